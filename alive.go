@@ -6,12 +6,12 @@ package uof
 // Reference: https://docs.betradar.com/display/BD/UOF+-+Alive
 type Alive struct {
 	//	The producer that sent this alive message.
-	Producer Producer `xml:"product,attr" json:"producer" bson:"producer"`
+	Producer Producer `xml:"product,attr" json:"producer" bson:"producer" uof:"1"`
 	// Timestamp in milliseconds since epoch when this message was generated
 	// according to generating system's clock.
-	Timestamp int `xml:"timestamp,attr" json:"timestamp" bson:"timestamp,omitempty"`
+	Timestamp int `xml:"timestamp,attr" json:"timestamp" bson:"timestamp,omitempty" uof:"2"`
 	// If set to 0 this means the product is up again after downtime, and the
 	// receiving client will have to issue recovery messages against the API to
 	// start receiving any additional messages and get the current state.
-	Subscribed int `xml:"subscribed,attr" json:"subscribed" bson:"subscribed,omitempty"`
+	Subscribed int `xml:"subscribed,attr" json:"subscribed" bson:"subscribed,omitempty" uof:"3"`
 }
@@ -15,16 +15,18 @@ import (
 )
 
 const (
-	stagingServer    = "stgapi.betradar.com"
-	productionServer = "api.betradar.com"
+	stagingServer    = "https://stgapi.betradar.com"
+	productionServer = "https://api.betradar.com"
 )
 
 var RequestTimeout = 32 * time.Second
 
 type API struct {
-	server  string
-	token   string
-	exitSig context.Context
+	server   string
+	token    string
+	exitSig  context.Context
+	capture  func(Exchange)
+	playback *playback
 }
 
 // Dial connect to the staging or production api environment
@@ -51,6 +53,17 @@ func Staging(exitSig context.Context, token string) (*API, error) {
 	return a, a.Ping()
 }
 
+// Custom connects to the api on the baseURL (scheme and host, like
+// http://127.0.0.1:8080). Used for testing with the fake api server.
+func Custom(exitSig context.Context, baseURL, token string) (*API, error) {
+	a := &API{
+		server:  baseURL,
+		token:   token,
+		exitSig: exitSig,
+	}
+	return a, a.Ping()
+}
+
 // Production connects to the production system
 func Production(exitSig context.Context, token string) (*API, error) {
 	a := &API{
@@ -134,11 +147,28 @@ func (a *API) post(tpl string, p *params) error {
 
 func (a *API) httpRequest(tpl string, p *params, method string) ([]byte, error) {
 	path := runTemplate(tpl, p)
-	url := fmt.Sprintf("https://%s%s", a.server, path)
+	if a.playback != nil {
+		return a.playback.response(a.server, method, path)
+	}
+	buf, statusCode, err := a.do(method, a.server+path)
+	if a.capture != nil {
+		x := Exchange{Method: method, Path: path, StatusCode: statusCode, Response: string(buf)}
+		if statusCode == 0 && err != nil {
+			x.Error = err.Error()
+		}
+		a.capture(x)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf, statusError(a.server+path, statusCode, buf)
+}
 
+// do makes http request, returns response body and status code
+func (a *API) do(method, url string) ([]byte, int, error) {
 	req, err := retryablehttp.NewRequest(method, url, nil)
 	if err != nil {
-		return nil, uof.E("http.NewRequest", uof.APIError{URL: url, Inner: err})
+		return nil, 0, uof.E("http.NewRequest", uof.APIError{URL: url, Inner: err})
 	}
 	if a.exitSig != nil {
 		ctx, cancel := context.WithTimeout(a.exitSig, RequestTimeout)
@@ -155,20 +185,22 @@ func (a *API) httpRequest(tpl string, p *params, method string) ([]byte, error)
 	req.Header.Set("x-access-token", a.token)
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, uof.E("client.Do", uof.APIError{URL: url, Inner: err})
+		return nil, 0, uof.E("client.Do", uof.APIError{URL: url, Inner: err})
 	}
 
 	defer resp.Body.Close()
 	buf, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, uof.E("http.Body", uof.APIError{URL: url, Inner: err})
+		return nil, 0, uof.E("http.Body", uof.APIError{URL: url, Inner: err})
 	}
+	return buf, resp.StatusCode, nil
+}
 
-	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
-		return nil, uof.E("http.StatusCode", uof.APIError{URL: url, StatusCode: resp.StatusCode, Response: string(buf)})
+func statusError(url string, statusCode int, buf []byte) error {
+	if !(statusCode >= 200 && statusCode < 300) {
+		return uof.E("http.StatusCode", uof.APIError{URL: url, StatusCode: statusCode, Response: string(buf)})
 	}
-
-	return buf, nil
+	return nil
 }
 
 type params struct {
@@ -180,6 +212,7 @@ type params struct {
 	MarketID           int
 	SportID            int
 	Variant            string
+	Specifiers         string
 	Timestamp          int
 	RequestID          int
 	Start              int
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+const (
+	pathCashoutProbabilities          = "/v1/probabilities/{{.EventURN}}"
+	pathMarketCashoutProbabilities    = "/v1/probabilities/{{.EventURN}}/{{.MarketID}}"
+	pathSpecifierCashoutProbabilities = "/v1/probabilities/{{.EventURN}}/{{.MarketID}}/{{.Specifiers}}"
+)
+
+// CashoutProbabilities gets probabilities of all event markets available for
+// cashout.
+func (a *API) CashoutProbabilities(eventURN uof.URN) (*uof.CashoutProbabilities, []byte, error) {
+	var cp uof.CashoutProbabilities
+	raw, err := a.getAs(&cp, pathCashoutProbabilities, &params{EventURN: eventURN})
+	return &cp, raw, err
+}
+
+// MarketCashoutProbabilities gets probabilities of all lines of the event
+// market.
+func (a *API) MarketCashoutProbabilities(eventURN uof.URN, marketID int) (*uof.CashoutProbabilities, []byte, error) {
+	var cp uof.CashoutProbabilities
+	raw, err := a.getAs(&cp, pathMarketCashoutProbabilities, &params{EventURN: eventURN, MarketID: marketID})
+	return &cp, raw, err
+}
+
+// SpecifierCashoutProbabilities gets probabilities of one market line.
+// Specifiers are as written in the feed, for example "total=2.5".
+func (a *API) SpecifierCashoutProbabilities(eventURN uof.URN, marketID int, specifiers string) (*uof.CashoutProbabilities, []byte, error) {
+	var cp uof.CashoutProbabilities
+	raw, err := a.getAs(&cp, pathSpecifierCashoutProbabilities, &params{EventURN: eventURN, MarketID: marketID, Specifiers: url.PathEscape(specifiers)})
+	return &cp, raw, err
+}
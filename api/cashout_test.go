@@ -0,0 +1,35 @@
+package api
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashoutProbabilities(t *testing.T) {
+	buf, err := ioutil.ReadFile("../testdata/cashout_probabilities.xml")
+	require.NoError(t, err)
+	a := Playback([]Exchange{
+		{Method: "GET", Path: "/v1/probabilities/sr:match:16470657", StatusCode: 200, Response: string(buf)},
+		{Method: "GET", Path: "/v1/probabilities/sr:match:16470657/18", StatusCode: 200, Response: string(buf)},
+		{Method: "GET", Path: "/v1/probabilities/sr:match:16470657/18/total=2.5%7Chcp=1", StatusCode: 200, Response: string(buf)},
+	})
+
+	cp, raw, err := a.CashoutProbabilities("sr:match:16470657")
+	assert.NoError(t, err)
+	assert.Equal(t, buf, raw)
+	assert.Equal(t, uof.URN("sr:match:16470657"), cp.EventURN)
+	assert.Equal(t, 16470657, cp.EventID)
+	assert.Equal(t, uof.ProducerLiveOdds, cp.Producer)
+	assert.Len(t, cp.Markets, 4)
+
+	_, _, err = a.MarketCashoutProbabilities("sr:match:16470657", 18)
+	assert.NoError(t, err)
+	_, _, err = a.SpecifierCashoutProbabilities("sr:match:16470657", 18, "total=2.5|hcp=1")
+	assert.NoError(t, err)
+	_, _, err = a.MarketCashoutProbabilities("sr:match:16470657", 1)
+	assert.True(t, uof.IsApiNotFoundErr(err))
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+// Exchange is api request and its response.
+type Exchange struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"` // with query
+	StatusCode int    `json:"statusCode,omitempty"`
+	Response   string `json:"response,omitempty"`
+	Error      string `json:"error,omitempty"` // request failed without response
+}
+
+func (x Exchange) key() string {
+	return x.Method + " " + x.Path
+}
+
+// path without query, recovery requests have random request id and timestamp
+// in the query
+func (x Exchange) baseKey() string {
+	if i := strings.Index(x.Path, "?"); i >= 0 {
+		return x.Method + " " + x.Path[:i]
+	}
+	return x.key()
+}
+
+// Capture calls cb with each request made by the api and its response. Cb is
+// called from many goroutines.
+func (a *API) Capture(cb func(Exchange)) {
+	a.capture = cb
+}
+
+// Playback creates api which doesn't make http requests but serves responses
+// from the recorded exchanges. Requests are matched with the recorded ones in
+// order, first by path with the query, then by path without the query. When
+// all matching exchanges are used the last one is repeated. Requests not found
+// in exchanges get 404 response.
+func Playback(exchanges []Exchange) *API {
+	p := &playback{
+		exchanges: exchanges,
+		used:      make([]bool, len(exchanges)),
+		byKey:     make(map[string][]int),
+		byBase:    make(map[string][]int),
+		last:      make(map[string]int),
+	}
+	for i, x := range exchanges {
+		p.byKey[x.key()] = append(p.byKey[x.key()], i)
+		p.byBase[x.baseKey()] = append(p.byBase[x.baseKey()], i)
+	}
+	return &API{playback: p}
+}
+
+type playback struct {
+	exchanges []Exchange
+	used      []bool
+	byKey     map[string][]int
+	byBase    map[string][]int
+	last      map[string]int // last used exchange by key
+	sync.Mutex
+}
+
+func (p *playback) response(server, method, path string) ([]byte, error) {
+	url := server + path
+	x, ok := p.find(Exchange{Method: method, Path: path})
+	if !ok {
+		return nil, statusError(url, http.StatusNotFound, nil)
+	}
+	if x.Error != "" {
+		return nil, uof.E("playback", uof.APIError{URL: url, Inner: errors.New(x.Error)})
+	}
+	buf := []byte(x.Response)
+	return buf, statusError(url, x.StatusCode, buf)
+}
+
+func (p *playback) find(r Exchange) (Exchange, bool) {
+	p.Lock()
+	defer p.Unlock()
+	next := func(idx map[string][]int, key string) (int, bool) {
+		is := idx[key]
+		for len(is) > 0 && p.used[is[0]] {
+			is = is[1:]
+		}
+		idx[key] = is
+		if len(is) == 0 {
+			return 0, false
+		}
+		return is[0], true
+	}
+	i, ok := next(p.byKey, r.key())
+	if !ok {
+		i, ok = next(p.byBase, r.baseKey())
+	}
+	if !ok {
+		i, ok = p.last[r.key()]
+		if !ok {
+			return Exchange{}, false
+		}
+	}
+	p.used[i] = true
+	p.last[r.key()] = i
+	return p.exchanges[i], true
+}
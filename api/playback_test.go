@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlayback(t *testing.T) {
+	a := Playback([]Exchange{
+		{Method: "GET", Path: "/a", StatusCode: 200, Response: "a1"},
+		{Method: "GET", Path: "/a", StatusCode: 200, Response: "a2"},
+		{Method: "POST", Path: "/r?request_id=1", StatusCode: 202},
+		{Method: "GET", Path: "/b", StatusCode: 500, Response: "b"},
+		{Method: "GET", Path: "/c", Error: "timeout"},
+	})
+	get := func(path string) (string, error) {
+		buf, err := a.get(path, nil)
+		return string(buf), err
+	}
+
+	// in order, last one repeated
+	for _, exp := range []string{"a1", "a2", "a2"} {
+		buf, err := get("/a")
+		assert.NoError(t, err)
+		assert.Equal(t, exp, buf)
+	}
+	// matched without query
+	assert.NoError(t, a.post("/r?request_id=2", nil))
+
+	var ae uof.APIError
+	_, err := get("/b")
+	assert.True(t, errors.As(err, &ae))
+	assert.Equal(t, 500, ae.StatusCode)
+	_, err = get("/c")
+	assert.EqualError(t, err, "uof error op: playback, inner: uof api error url: /c, inner: timeout")
+	_, err = get("/d")
+	assert.True(t, uof.IsApiNotFoundErr(err))
+}
+
+func TestCapture(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	a, err := Custom(context.Background(), srv.URL, "token")
+	assert.NoError(t, err)
+
+	var xs []Exchange
+	a.Capture(func(x Exchange) { xs = append(xs, x) })
+	_, _ = a.get("/a?b=c", nil)
+	_, _ = a.get("/missing", nil)
+	assert.Equal(t, []Exchange{
+		{Method: "GET", Path: "/a?b=c", StatusCode: 200, Response: "ok"},
+		{Method: "GET", Path: "/missing", StatusCode: 404, Response: "404 page not found\n"},
+	}, xs)
+
+	// captured exchanges played back
+	p := Playback(xs)
+	buf, err := p.get("/a?b=c", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(buf))
+	_, err = p.get("/missing", nil)
+	assert.True(t, uof.IsApiNotFoundErr(err))
+}
@@ -17,9 +17,14 @@ const (
 
 // Replay service for unified feed methods
 func Replay(exitSig context.Context, token string) (*ReplayAPI, error) {
+	return ReplayCustom(exitSig, productionServer, token)
+}
+
+// ReplayCustom replay service on the baseURL (scheme and host).
+func ReplayCustom(exitSig context.Context, baseURL, token string) (*ReplayAPI, error) {
 	r := &ReplayAPI{
 		api: &API{
-			server:  productionServer,
+			server:  baseURL,
 			token:   token,
 			exitSig: exitSig,
 		},
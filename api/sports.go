@@ -108,7 +108,7 @@ func (a *API) Fixtures(lang uof.Lang, to time.Time) (<-chan uof.Fixture, <-chan
 	go func() {
 		defer close(out)
 		defer close(errc)
-		done := false
+		done, empty := false, false
 
 		parse := func(buf []byte) error {
 			var sr scheduleRsp
@@ -121,6 +121,7 @@ func (a *API) Fixtures(lang uof.Lang, to time.Time) (<-chan uof.Fixture, <-chan
 					done = true
 				}
 			}
+			empty = len(sr.Fixtures) == 0
 			return nil
 		}
 
@@ -147,7 +148,9 @@ func (a *API) Fixtures(lang uof.Lang, to time.Time) (<-chan uof.Fixture, <-chan
 				errc <- err
 				return
 			}
-			if done {
+			// empty page is past the end of the schedule, when all scheduled
+			// events are before to
+			if done || empty {
 				return
 			}
 		}
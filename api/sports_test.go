@@ -0,0 +1,55 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixturesPaging(t *testing.T) {
+	schedule := func(scheduled ...string) string {
+		s := `<schedule generated_at="2020-01-01T00:00:00+00:00">`
+		for i, sc := range scheduled {
+			s += `<sport_event id="sr:match:` + string(rune('1'+i)) + `" scheduled="` + sc + `"/>`
+		}
+		return s + `</schedule>`
+	}
+	fixtures := func(a *API, to time.Time) ([]uof.Fixture, []error) {
+		var fs []uof.Fixture
+		var errs []error
+		out, errc := a.Fixtures(uof.LangEN, to)
+		for f := range out {
+			fs = append(fs, f)
+		}
+		for err := range errc {
+			errs = append(errs, err)
+		}
+		return fs, errs
+	}
+	live := Exchange{Method: "GET", Path: "/v1/sports/en/schedules/live/schedule.xml", StatusCode: 200, Response: schedule("2020-01-01T10:00:00+00:00")}
+	page := func(start, response string) Exchange {
+		return Exchange{Method: "GET", Path: "/v1/sports/en/schedules/pre/schedule.xml?start=" + start + "&limit=1000", StatusCode: 200, Response: response}
+	}
+	to := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	// schedule ends before to, empty page is the end of schedule
+	a := Playback([]Exchange{
+		live,
+		page("0", schedule("2020-01-02T10:00:00+00:00", "2020-01-03T10:00:00+00:00")),
+		page("1000", schedule()),
+	})
+	fs, errs := fixtures(a, to)
+	assert.Len(t, errs, 0)
+	assert.Len(t, fs, 3)
+
+	// fixture scheduled after to ends paging
+	a = Playback([]Exchange{
+		live,
+		page("0", schedule("2020-01-02T10:00:00+00:00", "2020-01-11T10:00:00+00:00")),
+	})
+	fs, errs = fixtures(a, to)
+	assert.Len(t, errs, 0)
+	assert.Len(t, fs, 3)
+}
@@ -7,28 +7,28 @@ import "encoding/xml"
 // bet-settlement/refund).
 // Reference: https://docs.betradar.com/display/BD/UOF+-+Bet+cancel
 type BetCancel struct {
-	EventID   int      `json:"eventId" bson:"eventId,omitempty"`
-	EventURN  URN      `xml:"event_id,attr" json:"eventURN" bson:"eventURN,omitempty"`
-	Producer  Producer `xml:"product,attr" json:"producer" bson:"producer,omitempty"`
-	Timestamp int      `xml:"timestamp,attr" json:"timestamp" bson:"timestamp,omitempty"`
-	RequestID *int     `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty"`
+	EventID   int      `json:"eventId" bson:"eventId,omitempty" uof:"1"`
+	EventURN  URN      `xml:"event_id,attr" json:"eventURN" bson:"eventURN,omitempty" uof:"2"`
+	Producer  Producer `xml:"product,attr" json:"producer" bson:"producer,omitempty" uof:"3"`
+	Timestamp int      `xml:"timestamp,attr" json:"timestamp" bson:"timestamp,omitempty" uof:"4"`
+	RequestID *int     `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty" uof:"5"`
 	// If start and end time are specified, they designate a range in time for
 	// which bets made should be cancelled. If there is an end_time but no
 	// start_time, this means cancel all bets placed before the specified time. If
 	// there is a start_time but no end_time this means, cancel all bets placed
 	// after the specified start_time.
-	StartTime    *int              `xml:"start_time,attr,omitempty" json:"startTime,omitempty" bson:"startTime,omitempty"`
-	EndTime      *int              `xml:"end_time,attr,omitempty" json:"endTime,omitempty" bson:"endTime,omitempty"`
-	SupercededBy *string           `xml:"superceded_by,attr,omitempty" json:"supercededBy,omitempty" bson:"supercededBy,omitempty"`
-	Markets      []BetCancelMarket `xml:"market" json:"markets" bson:"markets,omitempty"`
+	StartTime    *int              `xml:"start_time,attr,omitempty" json:"startTime,omitempty" bson:"startTime,omitempty" uof:"6"`
+	EndTime      *int              `xml:"end_time,attr,omitempty" json:"endTime,omitempty" bson:"endTime,omitempty" uof:"7"`
+	SupercededBy *string           `xml:"superceded_by,attr,omitempty" json:"supercededBy,omitempty" bson:"supercededBy,omitempty" uof:"8"`
+	Markets      []BetCancelMarket `xml:"market" json:"markets" bson:"markets,omitempty" uof:"9"`
 }
 
 type BetCancelMarket struct {
-	ID         int               `xml:"id,attr" json:"id" bson:"id,omitempty"`
-	LineID     int               `json:"lineId" bson:"lineId,omitempty"`
-	VariantID  int               `json:"variantId,omitempty" bson:"variantId,omitempty"`
-	Specifiers map[string]string `json:"specifiers,omitempty" bson:"specifiers,omitempty"`
-	VoidReason *int              `xml:"void_reason,attr,omitempty" json:"voidReason,omitempty" bson:"voidReason,omitempty"`
+	ID         int               `xml:"id,attr" json:"id" bson:"id,omitempty" uof:"1"`
+	LineID     int               `json:"lineId" bson:"lineId,omitempty" uof:"2"`
+	VariantID  int               `json:"variantId,omitempty" bson:"variantId,omitempty" uof:"3"`
+	Specifiers map[string]string `json:"specifiers,omitempty" bson:"specifiers,omitempty" uof:"4"`
+	VoidReason *int              `xml:"void_reason,attr,omitempty" json:"voidReason,omitempty" bson:"voidReason,omitempty" uof:"5"`
 }
 
 // A Rollback_bet_cancel message is sent when a previous bet cancel should be
@@ -36,14 +36,14 @@ type BetCancelMarket struct {
 // mistakenly cancels the wrong market (resulting in a bet_cancel being sent)
 // during the game; before realizing the mistake.
 type RollbackBetCancel struct {
-	EventID   int               `json:"eventId" bson:"eventId,omitempty"`
-	EventURN  URN               `xml:"event_id,attr" json:"eventURN" bson:"eventURN,omitempty"`
-	Producer  Producer          `xml:"product,attr" json:"producer" bson:"producer,omitempty"`
-	Timestamp int               `xml:"timestamp,attr" json:"timestamp" bson:"timestamp,omitempty"`
-	RequestID *int              `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty"`
-	StartTime *int              `xml:"start_time,attr,omitempty" json:"startTime,omitempty" bson:"startTime,omitempty"`
-	EndTime   *int              `xml:"end_time,attr,omitempty" json:"endTime,omitempty" bson:"endTime,omitempty"`
-	Markets   []BetCancelMarket `xml:"market" json:"markets" bson:"markets,omitempty"`
+	EventID   int               `json:"eventId" bson:"eventId,omitempty" uof:"1"`
+	EventURN  URN               `xml:"event_id,attr" json:"eventURN" bson:"eventURN,omitempty" uof:"2"`
+	Producer  Producer          `xml:"product,attr" json:"producer" bson:"producer,omitempty" uof:"3"`
+	Timestamp int               `xml:"timestamp,attr" json:"timestamp" bson:"timestamp,omitempty" uof:"4"`
+	RequestID *int              `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty" uof:"5"`
+	StartTime *int              `xml:"start_time,attr,omitempty" json:"startTime,omitempty" bson:"startTime,omitempty" uof:"6"`
+	EndTime   *int              `xml:"end_time,attr,omitempty" json:"endTime,omitempty" bson:"endTime,omitempty" uof:"7"`
+	Markets   []BetCancelMarket `xml:"market" json:"markets" bson:"markets,omitempty" uof:"8"`
 }
 
 // UnmarshalXML
@@ -72,7 +72,10 @@ func (t *BetCancelMarket) UnmarshalXML(d *xml.Decoder, start xml.StartElement) e
 	if err := d.DecodeElement(&overlay, &start); err != nil {
 		return err
 	}
-	t.Specifiers, t.LineID = toSpecifiersLineID(overlay.Specifiers, overlay.ExtendedSpecifiers)
+	var err error
+	if t.Specifiers, t.LineID, err = toSpecifiersLineID(overlay.Specifiers, overlay.ExtendedSpecifiers); err != nil {
+		return err
+	}
 	t.VariantID = toVariantID(variantSpecifier(t.Specifiers))
 	return nil
 }
@@ -90,3 +93,38 @@ func (t *RollbackBetCancel) UnmarshalXML(d *xml.Decoder, start xml.StartElement)
 	t.EventID = t.EventURN.EventID()
 	return nil
 }
+
+// inWindow reports whether the time is in the cancel time window. Missing
+// start or end time leaves window open on that side. Start time is inclusive,
+// end time exclusive.
+func inWindow(startTime, endTime *int, t int) bool {
+	if startTime != nil && t < *startTime {
+		return false
+	}
+	if endTime != nil && t >= *endTime {
+		return false
+	}
+	return true
+}
+
+// hasMarket reports whether the market is in the list
+func hasMarket(markets []BetCancelMarket, marketID, lineID int) bool {
+	for _, m := range markets {
+		if m.ID == marketID && m.LineID == lineID {
+			return true
+		}
+	}
+	return false
+}
+
+// Affects reports whether the bet placed at placedAt (timestamp in
+// milliseconds) on the market is cancelled by this message.
+func (t *BetCancel) Affects(marketID, lineID, placedAt int) bool {
+	return hasMarket(t.Markets, marketID, lineID) && inWindow(t.StartTime, t.EndTime, placedAt)
+}
+
+// Affects reports whether the rollback restores the bet placed at placedAt on
+// the market.
+func (t *RollbackBetCancel) Affects(marketID, lineID, placedAt int) bool {
+	return hasMarket(t.Markets, marketID, lineID) && inWindow(t.StartTime, t.EndTime, placedAt)
+}
@@ -0,0 +1,34 @@
+package uof
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBetCancelAffects(t *testing.T) {
+	start, end := 1000, 2000
+	markets := []BetCancelMarket{{ID: 18, LineID: 1}}
+	cases := []struct {
+		start, end *int
+		placedAt   int
+		affects    bool
+	}{
+		{nil, nil, 1, true},
+		{&start, nil, 999, false},
+		{&start, nil, 1000, true},
+		{nil, &end, 1999, true},
+		{nil, &end, 2000, false},
+		{&start, &end, 1500, true},
+		{&start, &end, 2500, false},
+	}
+	for i, c := range cases {
+		bc := BetCancel{StartTime: c.start, EndTime: c.end, Markets: markets}
+		assert.Equal(t, c.affects, bc.Affects(18, 1, c.placedAt), i)
+		rb := RollbackBetCancel{StartTime: c.start, EndTime: c.end, Markets: markets}
+		assert.Equal(t, c.affects, rb.Affects(18, 1, c.placedAt), i)
+	}
+	bc := BetCancel{Markets: markets}
+	assert.False(t, bc.Affects(18, 2, 1))
+	assert.False(t, bc.Affects(19, 1, 1))
+}
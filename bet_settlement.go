@@ -3,46 +3,75 @@ package uof
 import "encoding/xml"
 
 type BetSettlement struct {
-	EventID   int      `json:"eventId,omitempty" bson:"eventId,omitempty"`
-	EventURN  URN      `xml:"event_id,attr" json:"eventURN,omitempty" bson:"eventURN,omitempty"`
-	Producer  Producer `xml:"product,attr" json:"producer,omitempty" bson:"producer,omitempty"`
-	Timestamp int      `xml:"timestamp,attr" json:"timestamp,omitempty" bson:"timestamp,omitempty"`
-	RequestID *int     `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty"`
+	EventID   int      `json:"eventId,omitempty" bson:"eventId,omitempty" uof:"1"`
+	EventURN  URN      `xml:"event_id,attr" json:"eventURN,omitempty" bson:"eventURN,omitempty" uof:"2"`
+	Producer  Producer `xml:"product,attr" json:"producer,omitempty" bson:"producer,omitempty" uof:"3"`
+	Timestamp int      `xml:"timestamp,attr" json:"timestamp,omitempty" bson:"timestamp,omitempty" uof:"4"`
+	RequestID *int     `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty" uof:"5"`
 	// Is this bet-settlement sent as a consequence of scouts reporting the
 	// results live (1) or is this bet-settlement sent post-match when the
 	// official results have been confirmed (2)
-	Certainty *int8                 `xml:"certainty,attr" json:"certainty,omitempty" bson:"certainty,omitempty"` // May be one of 1, 2
-	Markets   []BetSettlementMarket `xml:"outcomes>market" json:"markets" bson:"markets,omitempty"`
+	Certainty *int8                 `xml:"certainty,attr" json:"certainty,omitempty" bson:"certainty,omitempty" uof:"6"` // May be one of 1, 2
+	Markets   []BetSettlementMarket `xml:"outcomes>market" json:"markets" bson:"markets,omitempty" uof:"7"`
 }
 
 type BetSettlementMarket struct {
-	ID         int               `xml:"id,attr" json:"id" bson:"id,omitempty"`
-	LineID     int               `json:"lineId" bson:"lineId,omitempty"`
-	VariantID  int               `json:"variantId,omitempty" bson:"variantId,omitempty"`
-	Specifiers map[string]string `json:"specifiers,omitempty" bson:"specifiers,omitempty"`
+	ID         int               `xml:"id,attr" json:"id" bson:"id,omitempty" uof:"1"`
+	LineID     int               `json:"lineId" bson:"lineId,omitempty" uof:"2"`
+	VariantID  int               `json:"variantId,omitempty" bson:"variantId,omitempty" uof:"3"`
+	Specifiers map[string]string `json:"specifiers,omitempty" bson:"specifiers,omitempty" uof:"4"`
 	// Describes the reason for voiding certain outcomes for a particular market.
 	// Only set if at least one of the outcomes have a void_factor. A list of void
 	// reasons can be found above this table or by using the API at
 	// https://iodocs.betradar.com/unifiedfeed#Betting-descriptions-GET-Void-reasons.
-	VoidReason *int                   `xml:"void_reason,attr,omitempty" json:"voidReason,omitempty" bson:"voidReason,omitempty"`
-	Result     *string                `xml:"result,attr,omitempty" json:"result,omitempty" bson:"result,omitempty"`
-	Outcomes   []BetSettlementOutcome `xml:"outcome" json:"outcomes" bson:"outcomes,omitempty"`
+	VoidReason *int                   `xml:"void_reason,attr,omitempty" json:"voidReason,omitempty" bson:"voidReason,omitempty" uof:"5"`
+	Result     *string                `xml:"result,attr,omitempty" json:"result,omitempty" bson:"result,omitempty" uof:"6"`
+	Outcomes   []BetSettlementOutcome `xml:"outcome" json:"outcomes" bson:"outcomes,omitempty" uof:"7"`
 }
 
 type BetSettlementOutcome struct {
-	ID             int           `json:"id" bson:"id"`
-	PlayerID       int           `json:"playerId,omitempty" bson:"playerId,omitempty"`
-	Result         OutcomeResult `json:"result" bson:"result"`
-	DeadHeatFactor float64       `json:"deadHeatFactor,omitempty" bson:"deadHeatFactor,omitempty"`
+	ID             int           `json:"id" bson:"id" uof:"1"`
+	PlayerID       int           `json:"playerId,omitempty" bson:"playerId,omitempty" uof:"2"`
+	Result         OutcomeResult `json:"result" bson:"result" uof:"3"`
+	DeadHeatFactor float64       `json:"deadHeatFactor,omitempty" bson:"deadHeatFactor,omitempty" uof:"4"`
+}
+
+// Factors returns the part of the stake paid at odds and the part of the stake
+// refunded for the outcome result:
+//
+//	lose:           0, 0
+//	win:            1, 0
+//	void:           0, 1   (void_factor=1)
+//	half lose:      0, 0.5 (void_factor=0.5)
+//	half win:       0.5, 0.5
+//	dead heat win:  dead_heat_factor, 0
+//
+// ok is false for the unknown result.
+func (o BetSettlementOutcome) Factors() (win, refund float64, ok bool) {
+	switch o.Result {
+	case OutcomeResultLose:
+		return 0, 0, true
+	case OutcomeResultWin:
+		return 1, 0, true
+	case OutcomeResultVoid:
+		return 0, 1, true
+	case OutcomeResultHalfLose:
+		return 0, 0.5, true
+	case OutcomeResultHalfWin:
+		return 0.5, 0.5, true
+	case OutcomeResultWinWithDeadHead:
+		return o.DeadHeatFactor, 0, true
+	}
+	return 0, 0, false
 }
 
 type RollbackBetSettlement struct {
-	EventID   int               `json:"eventId,omitempty" bson:"eventId,omitempty"`
-	EventURN  URN               `xml:"event_id,attr" json:"eventURN,omitempty" bson:"eventURN,omitempty"`
-	Producer  Producer          `xml:"product,attr" json:"producer,omitempty" bson:"producer,omitempty"`
-	Timestamp int               `xml:"timestamp,attr" json:"timestamp,omitempty" bson:"timestamp,omitempty"`
-	RequestID *int              `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty"`
-	Markets   []BetCancelMarket `xml:"market" json:"markets" bson:"markets,omitempty"`
+	EventID   int               `json:"eventId,omitempty" bson:"eventId,omitempty" uof:"1"`
+	EventURN  URN               `xml:"event_id,attr" json:"eventURN,omitempty" bson:"eventURN,omitempty" uof:"2"`
+	Producer  Producer          `xml:"product,attr" json:"producer,omitempty" bson:"producer,omitempty" uof:"3"`
+	Timestamp int               `xml:"timestamp,attr" json:"timestamp,omitempty" bson:"timestamp,omitempty" uof:"4"`
+	RequestID *int              `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty" uof:"5"`
+	Markets   []BetCancelMarket `xml:"market" json:"markets" bson:"markets,omitempty" uof:"6"`
 }
 
 func (t *BetSettlement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
@@ -82,7 +111,10 @@ func (t *BetSettlementMarket) UnmarshalXML(d *xml.Decoder, start xml.StartElemen
 	if err := d.DecodeElement(&overlay, &start); err != nil {
 		return err
 	}
-	t.Specifiers, t.LineID = toSpecifiersLineID(overlay.Specifiers, overlay.ExtendedSpecifiers)
+	var err error
+	if t.Specifiers, t.LineID, err = toSpecifiersLineID(overlay.Specifiers, overlay.ExtendedSpecifiers); err != nil {
+		return err
+	}
 	t.VariantID = toVariantID(variantSpecifier(t.Specifiers))
 	return nil
 }
@@ -104,8 +136,10 @@ func (t *BetSettlementOutcome) UnmarshalXML(d *xml.Decoder, start xml.StartEleme
 	if err := d.DecodeElement(&overlay, &start); err != nil {
 		return err
 	}
-	t.ID = toOutcomeID(overlay.ID)
-	t.PlayerID = toPlayerID(overlay.ID)
+	var err error
+	if t.ID, t.PlayerID, _, err = toOutcomeIDs(overlay.ID); err != nil {
+		return err
+	}
 	t.Result = toResult(overlay.Result, overlay.VoidFactor, overlay.DeadHeatFactor)
 	if t.Result == OutcomeResultWinWithDeadHead && overlay.DeadHeatFactor != nil {
 		t.DeadHeatFactor = *overlay.DeadHeatFactor
@@ -16,14 +16,17 @@ import (
 // moved to suspended. However, if the market is already deactivated, settled or
 // cancelled this is not a good practice. Only move ACTIVE markets to suspended.
 type BetStop struct {
-	EventID   int          `json:"eventId,omitempty" bson:"eventId,omitempty"`
-	EventURN  URN          `xml:"event_id,attr" json:"eventURN,omitempty" bson:"eventURN,omitempty"`
-	Timestamp int          `xml:"timestamp,attr" json:"timestamp,omitempty" bson:"timestamp,omitempty"`
-	RequestID *int         `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty"`
-	Groups    []string     `json:"groups,omitempty" bson:"groups,omitempty"`
-	MarketIDs []int        `json:"marketsIds,omitempty" bson:"marketIds,omitempty"`
-	Producer  Producer     `xml:"product,attr" json:"producer,omitempty" bson:"producer,omitempty"`
-	Status    MarketStatus `json:"status" bson:"status"`
+	EventID   int          `json:"eventId,omitempty" bson:"eventId,omitempty" uof:"1"`
+	EventURN  URN          `xml:"event_id,attr" json:"eventURN,omitempty" bson:"eventURN,omitempty" uof:"2"`
+	Timestamp int          `xml:"timestamp,attr" json:"timestamp,omitempty" bson:"timestamp,omitempty" uof:"3"`
+	RequestID *int         `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty" uof:"4"`
+	Groups    []string     `json:"groups,omitempty" bson:"groups,omitempty" uof:"5"`
+	MarketIDs []int        `json:"marketsIds,omitempty" bson:"marketIds,omitempty" uof:"6"`
+	Producer  Producer     `xml:"product,attr" json:"producer,omitempty" bson:"producer,omitempty" uof:"7"`
+	Status    MarketStatus `json:"status" bson:"status" uof:"8"`
+	// Origin of the SDK generated bet stop, BetStopOriginFeed for the feed
+	// messages.
+	Origin BetStopOrigin `json:"origin,omitempty" bson:"origin,omitempty" uof:"9"`
 }
 
 func (t *BetStop) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
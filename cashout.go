@@ -0,0 +1,69 @@
+package uof
+
+import "encoding/xml"
+
+// CashoutProbabilities is the probabilities api response. It has the same
+// markets as the odds change, outcomes have win probabilities and no odds.
+// Used for calculating cashout value of the open bets.
+// Reference: https://docs.betradar.com/display/BD/UOF+-+Cashout+Probabilities+API
+type CashoutProbabilities struct {
+	EventID       int               `json:"eventId,omitempty" bson:"eventId,omitempty" uof:"1"`
+	EventURN      URN               `xml:"event_id,attr" json:"eventURN,omitempty" bson:"eventURN,omitempty" uof:"2"`
+	Producer      Producer          `xml:"product,attr" json:"producer,omitempty" bson:"producer,omitempty" uof:"3"`
+	Timestamp     int               `xml:"timestamp,attr" json:"timestamp,omitempty" bson:"timestamp,omitempty" uof:"4"`
+	Markets       []Market          `json:"markets,omitempty" bson:"markets,omitempty" uof:"5"`
+	BettingStatus *int              `json:"bettingStatus,omitempty" bson:"bettingStatus,omitempty" uof:"6"`
+	BetstopReason *int              `json:"betstopReason,omitempty" bson:"betstopReason,omitempty" uof:"7"`
+	EventStatus   *SportEventStatus `xml:"sport_event_status,omitempty" json:"sportEventStatus,omitempty" bson:"eventStatus,omitempty" uof:"8"`
+}
+
+func (c *CashoutProbabilities) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type T CashoutProbabilities
+	var overlay struct {
+		*T
+		Odds *struct {
+			Markets       []Market `xml:"market,omitempty"`
+			BettingStatus *int     `xml:"betting_status,attr,omitempty"`
+			BetstopReason *int     `xml:"betstop_reason,attr,omitempty"`
+		} `xml:"odds,omitempty"`
+	}
+	overlay.T = (*T)(c)
+	if err := d.DecodeElement(&overlay, &start); err != nil {
+		return err
+	}
+	if overlay.Odds != nil {
+		c.BettingStatus = overlay.Odds.BettingStatus
+		c.BetstopReason = overlay.Odds.BetstopReason
+		c.Markets = overlay.Odds.Markets
+	}
+	c.EventID = c.EventURN.EventID()
+	return nil
+}
+
+// Probability returns win probability of the outcome. ok is false if the
+// market is not active, not available for cashout or the outcome has no
+// probability.
+func (c *CashoutProbabilities) Probability(marketID, lineID, outcomeID int) (float64, bool) {
+	if c == nil {
+		return 0, false
+	}
+	for _, m := range c.Markets {
+		if m.ID != marketID || m.LineID != lineID {
+			continue
+		}
+		if m.Status != MarketStatusActive ||
+			(m.CashoutStatus != nil && *m.CashoutStatus != CashoutStatusAvailable) {
+			return 0, false
+		}
+		for _, o := range m.Outcomes {
+			if o.ID != outcomeID {
+				continue
+			}
+			if o.Probabilities == nil || (o.Active != nil && !*o.Active) {
+				return 0, false
+			}
+			return *o.Probabilities, true
+		}
+	}
+	return 0, false
+}
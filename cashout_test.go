@@ -0,0 +1,45 @@
+package uof
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashoutProbabilities(t *testing.T) {
+	buf, err := ioutil.ReadFile("./testdata/cashout_probabilities.xml")
+	require.NoError(t, err)
+	m, err := NewAPIMessage(LangEN, MessageTypeCashoutProbabilities, buf)
+	require.NoError(t, err)
+	cp := m.CashoutProbabilities
+	assert.Equal(t, ProducerLiveOdds, m.Producer)
+	assert.Equal(t, 1558000000000, m.Timestamp)
+	assert.Equal(t, URN("sr:match:16470657"), cp.EventURN)
+	assert.Equal(t, 0, *cp.BettingStatus)
+	assert.Equal(t, 1, *cp.EventStatus.HomeScore)
+	assert.Len(t, cp.Markets, 4)
+	assert.Equal(t, CashoutStatusAvailable, *cp.Markets[0].CashoutStatus)
+
+	p, ok := cp.Probability(1, 0, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 0.62, p)
+	p, ok = cp.Probability(18, LineID("total=2.5"), 13)
+	assert.True(t, ok)
+	assert.Equal(t, 0.45, p)
+	// unknown outcome, cashout unavailable, market deactivated
+	for _, c := range [][3]int{{1, 0, 4}, {18, LineID("total=3.5"), 12}, {10, 0, 9}, {2, 0, 1}} {
+		_, ok = cp.Probability(c[0], c[1], c[2])
+		assert.False(t, ok, c)
+	}
+	var nilCP *CashoutProbabilities
+	_, ok = nilCP.Probability(1, 0, 1)
+	assert.False(t, ok)
+
+	n := NewCashoutProbabilitiesMessage(cp, 1234, buf)
+	assert.Equal(t, MessageTypeCashoutProbabilities, n.Type)
+	assert.Equal(t, MessageKindEvent, n.Type.Kind())
+	assert.Equal(t, 16470657, n.EventID)
+	assert.Equal(t, 1234, n.RequestedAt)
+}
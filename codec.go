@@ -0,0 +1,566 @@
+package uof
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Binary message encoding. Message is encoded as:
+//
+//	magic, version, header, body
+//
+// Struct is encoded as the list of its fields, each field as key and value,
+// and ends with zero key. Key is field number from the uof struct tag and
+// wire type of the value. Zero and nil fields are omitted. Integers are
+// encoded as varints, floats as 8 bytes, all other field values (strings,
+// slices, maps, structs) are prefixed with length. Decoder skips fields with
+// unknown numbers, so new fields can be added to the encoded structs (Header,
+// Body and all body types) without changing binaryVersion: add them with the
+// next free number. Field number must never be changed or reused and field
+// type must not change, TestMessageBinaryFieldNumbers checks the numbering.
+//
+// Version 6 encoded struct fields in the order of declaration, without keys.
+// It is still decoded, binaryV6Fields has the number of fields each struct had
+// in that version.
+const (
+	binaryMagic   byte = 'u'
+	binaryVersion byte = 7
+)
+
+// wire types of the encoded field values
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+var (
+	errBinaryTruncated = errors.New("binary message truncated")
+	timeType           = reflect.TypeOf(time.Time{})
+)
+
+// MarshalBinary encodes message to the compact binary format. Message body is
+// encoded instead of the raw xml, Raw is not included. Lazy message is
+// unpacked before encoding.
+func (m *Message) MarshalBinary() ([]byte, error) {
+	if err := m.Unpack(); err != nil {
+		return nil, err
+	}
+	e := binaryEncoder{buf: make([]byte, 0, len(m.Raw)/2+64)}
+	e.buf = append(e.buf, binaryMagic, binaryVersion)
+	if err := e.value(reflect.ValueOf(&m.Header).Elem()); err != nil {
+		return nil, Notice("message.MarshalBinary", err)
+	}
+	if err := e.value(reflect.ValueOf(&m.Body).Elem()); err != nil {
+		return nil, Notice("message.MarshalBinary", err)
+	}
+	return e.buf, nil
+}
+
+// UnmarshalBinary decodes message encoded by MarshalBinary in the current or
+// any of the previous supported versions.
+func (m *Message) UnmarshalBinary(buf []byte) error {
+	if len(buf) < 2 || buf[0] != binaryMagic {
+		return Notice("message.UnmarshalBinary", errors.New("not a binary message"))
+	}
+	d := binaryDecoder{buf: buf[2:]}
+	switch buf[1] {
+	case binaryVersion:
+	case 6:
+		d.v6 = true
+	default:
+		return Notice("message.UnmarshalBinary", fmt.Errorf("unsupported version %d, current is %d", buf[1], binaryVersion))
+	}
+	var h Header
+	var b Body
+	if err := d.value(reflect.ValueOf(&h).Elem()); err != nil {
+		return Notice("message.UnmarshalBinary", err)
+	}
+	if err := d.value(reflect.ValueOf(&b).Elem()); err != nil {
+		return Notice("message.UnmarshalBinary", err)
+	}
+	m.Header, m.Body, m.Raw = h, b, nil
+	return nil
+}
+
+// binaryField is encoded struct field
+type binaryField struct {
+	index  int // in the struct
+	number int // from the uof tag
+	wire   int
+}
+
+var binaryFieldsCache sync.Map // reflect.Type -> []binaryField
+
+// binaryFields returns encoded fields of the struct ordered by number
+func binaryFields(t reflect.Type) ([]binaryField, error) {
+	if fs, ok := binaryFieldsCache.Load(t); ok {
+		return fs.([]binaryField), nil
+	}
+	var fs []binaryField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		n, err := strconv.Atoi(f.Tag.Get("uof"))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("field %s.%s without uof field number", t.Name(), f.Name)
+		}
+		fs = append(fs, binaryField{index: i, number: n, wire: wireType(f.Type)})
+	}
+	sort.Slice(fs, func(i, j int) bool { return fs[i].number < fs[j].number })
+	for i := 1; i < len(fs); i++ {
+		if fs[i].number == fs[i-1].number {
+			return nil, fmt.Errorf("duplicate uof field number %d in %s", fs[i].number, t.Name())
+		}
+	}
+	binaryFieldsCache.Store(t, fs)
+	return fs, nil
+}
+
+func wireType(t reflect.Type) int {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return wireVarint
+	case reflect.Float32, reflect.Float64:
+		return wireFixed64
+	}
+	return wireBytes
+}
+
+// omitted is true for the field value which is not encoded
+func omitted(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return math.Float64bits(v.Float()) == 0
+	case reflect.String:
+		return v.Len() == 0
+	}
+	return false
+}
+
+type binaryEncoder struct {
+	buf []byte
+}
+
+func (e *binaryEncoder) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+func (e *binaryEncoder) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, b[:binary.PutVarint(b[:], v)]...)
+}
+
+func (e *binaryEncoder) bytes(b []byte) {
+	e.uvarint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *binaryEncoder) value(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 1)
+		} else {
+			e.buf = append(e.buf, 0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.varint(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.uvarint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v.Float()))
+		e.buf = append(e.buf, b[:]...)
+	case reflect.String:
+		e.uvarint(uint64(v.Len()))
+		e.buf = append(e.buf, v.String()...)
+	case reflect.Ptr:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0)
+			return nil
+		}
+		e.buf = append(e.buf, 1)
+		return e.value(v.Elem())
+	case reflect.Slice:
+		// length is shifted by one, zero is reserved for nil
+		if v.IsNil() {
+			e.uvarint(0)
+			return nil
+		}
+		e.uvarint(uint64(v.Len()) + 1)
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.buf = append(e.buf, v.Bytes()...)
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := e.value(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			e.uvarint(0)
+			return nil
+		}
+		e.uvarint(uint64(v.Len()) + 1)
+		keys := v.MapKeys()
+		if v.Type().Key().Kind() == reflect.String {
+			// deterministic output
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		}
+		for _, k := range keys {
+			if err := e.value(k); err != nil {
+				return err
+			}
+			if err := e.value(v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if v.Type() == timeType {
+			buf, err := v.Interface().(time.Time).MarshalBinary()
+			if err != nil {
+				return err
+			}
+			e.bytes(buf)
+			return nil
+		}
+		fs, err := binaryFields(v.Type())
+		if err != nil {
+			return err
+		}
+		for _, f := range fs {
+			if err := e.field(f, v.Field(f.index)); err != nil {
+				return err
+			}
+		}
+		e.uvarint(0)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (e *binaryEncoder) field(f binaryField, v reflect.Value) error {
+	if omitted(v) {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	e.uvarint(uint64(f.number)<<3 | uint64(f.wire))
+	if f.wire != wireBytes || lengthPrefixed(v.Type()) {
+		return e.value(v)
+	}
+	sub := binaryEncoder{}
+	if err := sub.value(v); err != nil {
+		return err
+	}
+	e.bytes(sub.buf)
+	return nil
+}
+
+// lengthPrefixed is true for the types which value encoding starts with its
+// length
+func lengthPrefixed(t reflect.Type) bool {
+	return t.Kind() == reflect.String || t == timeType
+}
+
+type binaryDecoder struct {
+	buf []byte
+	v6  bool // decoding version 6
+}
+
+func (d *binaryDecoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		return 0, errBinaryTruncated
+	}
+	d.buf = d.buf[n:]
+	return v, nil
+}
+
+func (d *binaryDecoder) varint() (int64, error) {
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		return 0, errBinaryTruncated
+	}
+	d.buf = d.buf[n:]
+	return v, nil
+}
+
+func (d *binaryDecoder) byte() (byte, error) {
+	if len(d.buf) < 1 {
+		return 0, errBinaryTruncated
+	}
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b, nil
+}
+
+// len reads length and checks that the rest of the buffer can hold that
+// many elements
+func (d *binaryDecoder) len() (int, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.buf)) {
+		return 0, errBinaryTruncated
+	}
+	return int(n), nil
+}
+
+// optLen reads length of the nil-able slice or map
+func (d *binaryDecoder) optLen() (int, bool, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return 0, false, err
+	}
+	if n == 0 {
+		return 0, false, nil
+	}
+	if n-1 > uint64(len(d.buf)) {
+		return 0, false, errBinaryTruncated
+	}
+	return int(n - 1), true, nil
+}
+
+func (d *binaryDecoder) bytes(n int) []byte {
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *binaryDecoder) value(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := d.byte()
+		if err != nil {
+			return err
+		}
+		v.SetBool(b != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := d.varint()
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(i) {
+			return fmt.Errorf("value %d overflows %s", i, v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := d.uvarint()
+		if err != nil {
+			return err
+		}
+		if v.OverflowUint(u) {
+			return fmt.Errorf("value %d overflows %s", u, v.Type())
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		if len(d.buf) < 8 {
+			return errBinaryTruncated
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(d.bytes(8))))
+	case reflect.String:
+		n, err := d.len()
+		if err != nil {
+			return err
+		}
+		v.SetString(string(d.bytes(n)))
+	case reflect.Ptr:
+		b, err := d.byte()
+		if err != nil || b == 0 {
+			return err
+		}
+		p := reflect.New(v.Type().Elem())
+		if err := d.value(p.Elem()); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Slice:
+		n, ok, err := d.optLen()
+		if err != nil || !ok {
+			return err
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(append([]byte{}, d.bytes(n)...))
+			return nil
+		}
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			if err := d.value(s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		n, ok, err := d.optLen()
+		if err != nil || !ok {
+			return err
+		}
+		t := v.Type()
+		m := reflect.MakeMapWithSize(t, n)
+		for i := 0; i < n; i++ {
+			k := reflect.New(t.Key()).Elem()
+			if err := d.value(k); err != nil {
+				return err
+			}
+			e := reflect.New(t.Elem()).Elem()
+			if err := d.value(e); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			n, err := d.len()
+			if err != nil {
+				return err
+			}
+			var t time.Time
+			if err := t.UnmarshalBinary(d.bytes(n)); err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(t))
+			return nil
+		}
+		fs, err := binaryFields(v.Type())
+		if err != nil {
+			return err
+		}
+		if d.v6 {
+			return d.structV6(v, fs)
+		}
+		return d.fields(v, fs)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// fields decodes struct fields until the zero key
+func (d *binaryDecoder) fields(v reflect.Value, fs []binaryField) error {
+	for {
+		key, err := d.uvarint()
+		if err != nil {
+			return err
+		}
+		if key == 0 {
+			return nil
+		}
+		number, wire := int(key>>3), int(key&7)
+		i := sort.Search(len(fs), func(i int) bool { return fs[i].number >= number })
+		if i == len(fs) || fs[i].number != number {
+			if err := d.skip(wire); err != nil {
+				return err
+			}
+			continue
+		}
+		f := fs[i]
+		if f.wire != wire {
+			return fmt.Errorf("field %d of %s has wire type %d, expected %d", number, v.Type().Name(), wire, f.wire)
+		}
+		if err := d.field(f, v.Field(f.index)); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *binaryDecoder) field(f binaryField, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		p := reflect.New(v.Type().Elem())
+		v.Set(p)
+		v = p.Elem()
+	}
+	if f.wire != wireBytes || lengthPrefixed(v.Type()) {
+		return d.value(v)
+	}
+	n, err := d.len()
+	if err != nil {
+		return err
+	}
+	sub := binaryDecoder{buf: d.bytes(n)}
+	return sub.value(v)
+}
+
+// skip skips value of the unknown field
+func (d *binaryDecoder) skip(wire int) error {
+	switch wire {
+	case wireVarint:
+		_, err := d.uvarint()
+		return err
+	case wireFixed64:
+		if len(d.buf) < 8 {
+			return errBinaryTruncated
+		}
+		d.bytes(8)
+	case wireBytes:
+		n, err := d.len()
+		if err != nil {
+			return err
+		}
+		d.bytes(n)
+	default:
+		return fmt.Errorf("unknown wire type %d", wire)
+	}
+	return nil
+}
+
+// structV6 decodes struct fields in the order of numbers, as they were
+// declared in version 6
+func (d *binaryDecoder) structV6(v reflect.Value, fs []binaryField) error {
+	n := binaryV6Fields[v.Type().Name()]
+	for _, f := range fs {
+		if f.number > n {
+			break
+		}
+		if err := d.value(v.Field(f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// binaryV6Fields is the number of encoded fields of each struct in version 6.
+// Fields added later have larger numbers and are not in version 6 messages.
+var binaryV6Fields = map[string]int{
+	"Header": 12, "Body": 18,
+	"Alive": 3, "SnapshotComplete": 3, "Connection": 2, "ProducerChange": 4,
+	"BetCancel": 9, "BetCancelMarket": 5, "RollbackBetSettlement": 6, "RollbackBetCancel": 8,
+	"BetSettlement": 7, "BetSettlementMarket": 7, "BetSettlementOutcome": 4, "BetStop": 9,
+	"OddsChange": 12, "Market": 9, "Outcome": 7, "OddsGenerationProperties": 2,
+	"OddsDelta": 4, "MarketDelta": 5, "OutcomeDelta": 7, "FixtureChange": 8,
+	"SportEventStatus": 44, "Clock": 6, "PeriodScore": 4, "Result": 3, "Statistics": 5, "StatisticsScore": 2,
+	"Fixture": 26, "Sport": 2, "Category": 3, "Tournament": 2, "Round": 10, "Season": 7, "Venue": 7,
+	"ProductInfo": 6, "StreamingChannel": 2, "ProductInfoLink": 2, "Competitor": 8, "CompetitorPlayer": 4,
+	"TvChannel": 1, "ExtraInfo": 2, "SportEvent": 5, "FixtureTournament": 8, "Group": 2,
+	"MarketDescription": 11, "MarketOutcome": 3, "MarketSpecifier": 3, "MarketAttribute": 2, "MarketVariant": 2,
+	"Player": 12, "CashoutProbabilities": 8,
+}
@@ -0,0 +1,306 @@
+package uof
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func binaryTestMessages(t testing.TB) []*Message {
+	queue := []struct {
+		file string
+		key  string
+	}{
+		{"odds_change-0.xml", "hi.-.live.odds_change.4.sr:match.11784628.-"},
+		{"odds_change-vhc.xml", "hi.-.live.odds_change.1.vf:match.11784628.-"},
+		{"bet_settlement.xml", "lo.pre.-.bet_settlement.1.sr:match.16807109.-"},
+		{"bet_cancel.xml", "hi.pre.-.bet_cancel.1.sr:match.16807109.-"},
+		{"rollback_bet_cancel.xml", "hi.pre.-.rollback_bet_cancel.1.sr:match.16807109.-"},
+	}
+	api := []struct {
+		file string
+		typ  MessageType
+	}{
+		{"fixture-0.xml", MessageTypeFixture},
+		{"fixture-1.xml", MessageTypeFixture},
+		{"fixture-2.xml", MessageTypeFixture},
+		{"fixture-3.xml", MessageTypeFixture},
+		{"markets-0.xml", MessageTypeMarkets},
+		{"markets-1.xml", MessageTypeMarkets},
+		{"player_profile_f.xml", MessageTypePlayer},
+		{"player_profile_m.xml", MessageTypePlayer},
+		{"player_profile_u.xml", MessageTypePlayer},
+		{"cashout_probabilities.xml", MessageTypeCashoutProbabilities},
+	}
+
+	var msgs []*Message
+	for _, q := range queue {
+		buf, err := ioutil.ReadFile("./testdata/" + q.file)
+		assert.NoError(t, err)
+		m, err := NewQueueMessage(q.key, buf)
+		assert.NoError(t, err, q.file)
+		msgs = append(msgs, m)
+	}
+	for _, a := range api {
+		buf, err := ioutil.ReadFile("./testdata/" + a.file)
+		assert.NoError(t, err)
+		m, err := NewAPIMessage(LangEN, a.typ, buf)
+		assert.NoError(t, err, a.file)
+		msgs = append(msgs, m)
+	}
+	for _, s := range []string{
+		`<alive timestamp="1234" product="3" subscribed="1"/>`,
+		`<snapshot_complete request_id="123" timestamp="1234" product="3"/>`,
+		`<bet_stop timestamp="12345" product="3" event_id="sr:match:471123" groups="10_min|180s"/>`,
+		`<fixture_change start_time="1511107200000" product="3" event_id="sr:match:1234" timestamp="1234"/>`,
+		`<rollback_bet_settlement product="1" event_id="sr:match:1234" timestamp="1234"><market id="1"/></rollback_bet_settlement>`,
+	} {
+		m, err := NewQueueMessage("-.-.-."+xmlRootName(s)+".1.sr:match.1234.-", []byte(s))
+		assert.NoError(t, err, s)
+		msgs = append(msgs, m)
+	}
+	pc := ProducersChange{}
+	pc.Add(ProducerPrematch, 1234)
+	msgs = append(msgs,
+		NewConnnectionMessage(ConnectionStatusUp),
+		NewProducersChangeMessage(pc),
+		NewCompetitorMessage(LangDE, &CompetitorPlayer{ID: 1, Name: "pero"}, 1234),
+		NewTournamentMessage(LangEN, FixtureTournament{ID: 1, URN: "sr:tournament:1"}, 1234, nil),
+	)
+	return msgs
+}
+
+func xmlRootName(s string) string {
+	for i := 1; i < len(s); i++ {
+		if s[i] == ' ' || s[i] == '>' || s[i] == '/' {
+			return s[1:i]
+		}
+	}
+	return s
+}
+
+func TestMessageBinaryRoundTrip(t *testing.T) {
+	for _, m := range binaryTestMessages(t) {
+		buf, err := m.MarshalBinary()
+		assert.NoError(t, err)
+		assert.Less(t, len(buf), len(m.MarshalPretty()), m.Type.String())
+
+		var c Message
+		assert.NoError(t, c.UnmarshalBinary(buf))
+		assert.Equal(t, m.Header, c.Header, m.Type.String())
+		assert.Equal(t, m.Body, c.Body, m.Type.String())
+		assert.Nil(t, c.Raw)
+
+		// deterministic output
+		buf2, err := c.MarshalBinary()
+		assert.NoError(t, err)
+		assert.Equal(t, buf, buf2)
+	}
+}
+
+func TestMessageBinaryLazy(t *testing.T) {
+	buf, err := ioutil.ReadFile("./testdata/odds_change-0.xml")
+	assert.NoError(t, err)
+	m, err := NewLazyQueueMessage("hi.-.live.odds_change.4.sr:match.11784628.-", buf)
+	assert.NoError(t, err)
+	bin, err := m.MarshalBinary()
+	assert.NoError(t, err)
+	assert.NotNil(t, m.OddsChange)
+
+	var c Message
+	assert.NoError(t, c.UnmarshalBinary(bin))
+	assert.Equal(t, m.Body, c.Body)
+}
+
+func TestMessageBinaryErrors(t *testing.T) {
+	m := NewConnnectionMessage(ConnectionStatusUp)
+	buf, err := m.MarshalBinary()
+	assert.NoError(t, err)
+
+	var c Message
+	assert.Error(t, c.UnmarshalBinary(nil))
+	assert.Error(t, c.UnmarshalBinary([]byte("{}")))
+	assert.Error(t, c.UnmarshalBinary([]byte{binaryMagic, binaryVersion + 1}))
+	// every truncation is an error
+	for i := 0; i < len(buf); i++ {
+		assert.Error(t, c.UnmarshalBinary(buf[:i]))
+	}
+	assert.NoError(t, c.UnmarshalBinary(buf))
+}
+
+// binaryGoldenMessages are the test messages without timestamps set at
+// creation, they are stored in the golden files of each binary version.
+func binaryGoldenMessages(t testing.TB) []*Message {
+	var msgs []*Message
+	for _, m := range binaryTestMessages(t) {
+		if m.Type.Kind() == MessageKindSystem {
+			continue
+		}
+		m.ReceivedAt = 0
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+// Golden files are written by the binary version in the file name, never
+// change them. Messages are length prefixed.
+func TestMessageBinaryGolden(t *testing.T) {
+	msgs := binaryGoldenMessages(t)
+	for _, version := range []byte{6, 7} {
+		buf, err := ioutil.ReadFile(fmt.Sprintf("./testdata/binary-v%d.bin", version))
+		require.NoError(t, err)
+		d := binaryDecoder{buf: buf}
+		for _, m := range msgs {
+			n, err := d.len()
+			require.NoError(t, err)
+			b := d.bytes(n)
+			assert.Equal(t, version, b[1])
+			var c Message
+			require.NoError(t, c.UnmarshalBinary(b), "version %d %s", version, m.Type)
+			assert.Equal(t, m.Header, c.Header, "version %d %s", version, m.Type)
+			assert.Equal(t, m.Body, c.Body, "version %d %s", version, m.Type)
+		}
+		assert.Len(t, d.buf, 0)
+	}
+}
+
+func TestMessageBinaryFieldNumbers(t *testing.T) {
+	seen := make(map[reflect.Type]bool)
+	binaryFieldNumbers(t, reflect.TypeOf(Header{}), seen)
+	binaryFieldNumbers(t, reflect.TypeOf(Body{}), seen)
+	for name := range binaryV6Fields {
+		found := false
+		for typ := range seen {
+			found = found || typ.Name() == name
+		}
+		assert.True(t, found, name)
+	}
+}
+
+// binaryFieldNumbers checks that all encoded structs have valid field numbers
+func binaryFieldNumbers(t *testing.T, typ reflect.Type, seen map[reflect.Type]bool) {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		binaryFieldNumbers(t, typ.Elem(), seen)
+	case reflect.Map:
+		binaryFieldNumbers(t, typ.Key(), seen)
+		binaryFieldNumbers(t, typ.Elem(), seen)
+	case reflect.Struct:
+		if typ == timeType || seen[typ] {
+			return
+		}
+		seen[typ] = true
+		fs, err := binaryFields(typ)
+		assert.NoError(t, err)
+		for _, f := range fs {
+			binaryFieldNumbers(t, typ.Field(f.index).Type, seen)
+		}
+	}
+}
+
+func TestMessageBinaryUnknownFields(t *testing.T) {
+	type inner struct {
+		A int `uof:"1"`
+	}
+	type v1 struct {
+		ID   int    `uof:"1"`
+		Name string `uof:"3"`
+	}
+	type v2 struct {
+		ID     int               `uof:"1"`
+		Odds   float64           `uof:"2"`
+		Name   string            `uof:"3"`
+		Inner  *inner            `uof:"4"`
+		Values []int             `uof:"5"`
+		Map    map[string]string `uof:"6"`
+		Flag   bool              `uof:"7"`
+	}
+	e := binaryEncoder{}
+	require.NoError(t, e.value(reflect.ValueOf(v2{ID: 1, Odds: 1.5, Name: "pero", Inner: &inner{A: 2},
+		Values: []int{1, 2}, Map: map[string]string{"a": "b"}, Flag: true})))
+	var c v1
+	d := binaryDecoder{buf: e.buf}
+	assert.NoError(t, d.value(reflect.ValueOf(&c).Elem()))
+	assert.Equal(t, v1{ID: 1, Name: "pero"}, c)
+	assert.Len(t, d.buf, 0)
+
+	// changed field type is an error
+	type v3 struct {
+		Name int `uof:"3"`
+	}
+	d = binaryDecoder{buf: e.buf}
+	var c3 v3
+	assert.Error(t, d.value(reflect.ValueOf(&c3).Elem()))
+
+	// struct without field numbers can't be encoded
+	type untagged struct {
+		ID int
+	}
+	assert.Error(t, (&binaryEncoder{}).value(reflect.ValueOf(untagged{ID: 1})))
+}
+
+func BenchmarkMessageMarshal(b *testing.B) {
+	msgs := binaryTestMessages(b)
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, m := range msgs {
+				m.MarshalPretty()
+			}
+		}
+	})
+	b.Run("binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, m := range msgs {
+				if _, err := m.MarshalBinary(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkMessageUnmarshal(b *testing.B) {
+	msgs := binaryTestMessages(b)
+	var raws, jsons, bins [][]byte
+	for _, m := range msgs {
+		raws = append(raws, m.Marshal())
+		jsons = append(jsons, m.MarshalPretty())
+		buf, _ := m.MarshalBinary()
+		bins = append(bins, buf)
+	}
+	b.Run("raw", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, buf := range raws {
+				var m Message
+				_ = m.Unmarshal(buf)
+			}
+		}
+	})
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, buf := range jsons {
+				var m Message
+				_ = m.Unmarshal(buf)
+			}
+		}
+	})
+	b.Run("binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, buf := range bins {
+				var m Message
+				if err := m.UnmarshalBinary(buf); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,764 @@
+package uof
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Hand written decoders for the high volume messages: odds_change, bet_stop
+// and bet_settlement. They fill the same structs as the UnmarshalXML methods
+// with the same result, but scan the raw message only once and without
+// reflection. Rarely used parts of the message (sport_event_status) are still
+// decoded by encoding/xml.
+
+var errUnexpectedEOF = errors.New("xml: unexpected EOF")
+
+type xmlToken int8
+
+const (
+	xmlEOF xmlToken = iota
+	xmlStart
+	xmlEnd
+)
+
+type xmlAttr struct {
+	name  []byte // local name
+	value []byte // unescaped value
+}
+
+// xmlScanner is minimal xml tokenizer. It returns only start and end elements;
+// character data, comments, processing instructions and directives are
+// skipped. Names are returned without namespace prefix.
+type xmlScanner struct {
+	buf        []byte
+	pos        int
+	start      int    // offset of the current element start
+	name       []byte // local name of the current element
+	attrs      []xmlAttr
+	stack      [][]byte // raw names of the open elements
+	selfClosed bool     // current start element is self closing, end is pending
+}
+
+// next advances to the next start or end element
+func (s *xmlScanner) next() (xmlToken, error) {
+	if s.selfClosed {
+		s.selfClosed = false
+		s.pop()
+		return xmlEnd, nil
+	}
+	for {
+		i := bytes.IndexByte(s.buf[s.pos:], '<')
+		if i < 0 {
+			s.pos = len(s.buf)
+			if len(s.stack) > 0 {
+				return xmlEOF, errUnexpectedEOF
+			}
+			return xmlEOF, nil
+		}
+		s.pos += i
+		s.start = s.pos
+		rest := s.buf[s.pos+1:]
+		var err error
+		switch {
+		case bytes.HasPrefix(rest, []byte("!--")):
+			err = s.skipTo("-->")
+		case bytes.HasPrefix(rest, []byte("![CDATA[")):
+			err = s.skipTo("]]>")
+		case bytes.HasPrefix(rest, []byte("?")):
+			err = s.skipTo("?>")
+		case bytes.HasPrefix(rest, []byte("!")):
+			err = s.skipTo(">")
+		case bytes.HasPrefix(rest, []byte("/")):
+			return s.endElement()
+		default:
+			return s.startElement()
+		}
+		if err != nil {
+			return xmlEOF, err
+		}
+	}
+}
+
+func (s *xmlScanner) skipTo(end string) error {
+	i := bytes.Index(s.buf[s.pos:], []byte(end))
+	if i < 0 {
+		return errUnexpectedEOF
+	}
+	s.pos += i + len(end)
+	return nil
+}
+
+func (s *xmlScanner) startElement() (xmlToken, error) {
+	s.pos++ // <
+	raw := s.readName()
+	if len(raw) == 0 {
+		return xmlEOF, s.syntaxError("expected element name after <")
+	}
+	s.name = localName(raw)
+	s.attrs = s.attrs[:0]
+	for {
+		s.skipSpace()
+		if s.pos >= len(s.buf) {
+			return xmlEOF, errUnexpectedEOF
+		}
+		switch s.buf[s.pos] {
+		case '/':
+			if s.pos+1 >= len(s.buf) || s.buf[s.pos+1] != '>' {
+				return xmlEOF, s.syntaxError("expected /> in element")
+			}
+			s.pos += 2
+			s.stack = append(s.stack, raw)
+			s.selfClosed = true
+			return xmlStart, nil
+		case '>':
+			s.pos++
+			s.stack = append(s.stack, raw)
+			return xmlStart, nil
+		}
+		if err := s.attr(); err != nil {
+			return xmlEOF, err
+		}
+	}
+}
+
+func (s *xmlScanner) attr() error {
+	name := s.readName()
+	if len(name) == 0 {
+		return s.syntaxError("expected attribute name in element")
+	}
+	s.skipSpace()
+	if s.pos >= len(s.buf) {
+		return errUnexpectedEOF
+	}
+	if s.buf[s.pos] != '=' {
+		return s.syntaxError("attribute name without = in element")
+	}
+	s.pos++
+	s.skipSpace()
+	if s.pos >= len(s.buf) {
+		return errUnexpectedEOF
+	}
+	quote := s.buf[s.pos]
+	if quote != '"' && quote != '\'' {
+		return s.syntaxError("unquoted or missing attribute value in element")
+	}
+	s.pos++
+	i := bytes.IndexByte(s.buf[s.pos:], quote)
+	if i < 0 {
+		return errUnexpectedEOF
+	}
+	value := s.buf[s.pos : s.pos+i]
+	s.pos += i + 1
+	if bytes.IndexByte(value, '<') >= 0 {
+		return s.syntaxError("unescaped < inside quoted string")
+	}
+	value, err := unescape(value)
+	if err != nil {
+		return err
+	}
+	s.attrs = append(s.attrs, xmlAttr{name: localName(name), value: value})
+	return nil
+}
+
+func (s *xmlScanner) endElement() (xmlToken, error) {
+	s.pos += 2 // </
+	raw := s.readName()
+	if len(raw) == 0 {
+		return xmlEOF, s.syntaxError("expected element name after </")
+	}
+	s.skipSpace()
+	if s.pos >= len(s.buf) {
+		return xmlEOF, errUnexpectedEOF
+	}
+	if s.buf[s.pos] != '>' {
+		return xmlEOF, s.syntaxError("invalid characters between </" + string(raw) + " and >")
+	}
+	s.pos++
+	if len(s.stack) == 0 {
+		return xmlEOF, s.syntaxError("unexpected end element </" + string(raw) + ">")
+	}
+	if top := s.stack[len(s.stack)-1]; !bytes.Equal(top, raw) {
+		return xmlEOF, s.syntaxError("element <" + string(top) + "> closed by </" + string(raw) + ">")
+	}
+	s.pop()
+	return xmlEnd, nil
+}
+
+func (s *xmlScanner) pop() {
+	s.name = localName(s.stack[len(s.stack)-1])
+	s.stack = s.stack[:len(s.stack)-1]
+}
+
+// skip skips the rest of the current element
+func (s *xmlScanner) skip() error {
+	depth := 1
+	for depth > 0 {
+		t, err := s.next()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case xmlStart:
+			depth++
+		case xmlEnd:
+			depth--
+		case xmlEOF:
+			return errUnexpectedEOF
+		}
+	}
+	return nil
+}
+
+func (s *xmlScanner) readName() []byte {
+	start := s.pos
+	for ; s.pos < len(s.buf); s.pos++ {
+		switch s.buf[s.pos] {
+		case ' ', '\t', '\r', '\n', '=', '/', '>', '<', '"', '\'':
+			return s.buf[start:s.pos]
+		}
+	}
+	return s.buf[start:s.pos]
+}
+
+func (s *xmlScanner) skipSpace() {
+	for ; s.pos < len(s.buf); s.pos++ {
+		switch s.buf[s.pos] {
+		case ' ', '\t', '\r', '\n':
+		default:
+			return
+		}
+	}
+}
+
+func (s *xmlScanner) syntaxError(msg string) error {
+	line := 1 + bytes.Count(s.buf[:s.pos], []byte("\n"))
+	return &xml.SyntaxError{Msg: msg, Line: line}
+}
+
+// localName strips namespace prefix from the name, same as encoding/xml
+func localName(name []byte) []byte {
+	if i := bytes.IndexByte(name, ':'); i > 0 && i < len(name)-1 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// unescape replaces entities and normalizes new lines in the attribute value.
+// Allocates only if there is something to replace.
+func unescape(v []byte) ([]byte, error) {
+	if bytes.IndexByte(v, '&') < 0 && bytes.IndexByte(v, '\r') < 0 {
+		return v, nil
+	}
+	out := make([]byte, 0, len(v))
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c == '\r' {
+			out = append(out, '\n')
+			if i+1 < len(v) && v[i+1] == '\n' {
+				i++
+			}
+			continue
+		}
+		if c != '&' {
+			out = append(out, c)
+			continue
+		}
+		j := bytes.IndexByte(v[i:], ';')
+		if j < 0 {
+			return nil, &xml.SyntaxError{Msg: "invalid character entity " + string(v[i:]) + " (no semicolon)", Line: 1}
+		}
+		ent := string(v[i+1 : i+j])
+		switch ent {
+		case "lt":
+			out = append(out, '<')
+		case "gt":
+			out = append(out, '>')
+		case "amp":
+			out = append(out, '&')
+		case "apos":
+			out = append(out, '\'')
+		case "quot":
+			out = append(out, '"')
+		default:
+			r, ok := charRef(ent)
+			if !ok {
+				return nil, &xml.SyntaxError{Msg: "invalid character entity &" + ent + ";", Line: 1}
+			}
+			var rb [utf8.UTFMax]byte
+			out = append(out, rb[:utf8.EncodeRune(rb[:], r)]...)
+		}
+		i += j
+	}
+	return out, nil
+}
+
+func charRef(ent string) (rune, bool) {
+	if len(ent) < 2 || ent[0] != '#' {
+		return 0, false
+	}
+	var n uint64
+	var err error
+	if ent[1] == 'x' {
+		n, err = strconv.ParseUint(ent[2:], 16, 32)
+	} else {
+		n, err = strconv.ParseUint(ent[1:], 10, 32)
+	}
+	if err != nil || !utf8.ValidRune(rune(n)) {
+		return 0, false
+	}
+	return rune(n), true
+}
+
+// xmlDecoder fills struct fields from the scanned elements. Keeps the first
+// error, so the decoding functions can check it only at the end.
+type xmlDecoder struct {
+	s   xmlScanner
+	err error
+}
+
+// root advances to the root element
+func (d *xmlDecoder) root() bool {
+	t, err := d.s.next()
+	if err != nil {
+		d.err = err
+		return false
+	}
+	if t != xmlStart {
+		d.err = errUnexpectedEOF
+		return false
+	}
+	return true
+}
+
+// child advances to the next child of the current element. Returns false
+// on the end of the current element or on error. Each child has to be
+// consumed by child loop or skip.
+func (d *xmlDecoder) child() bool {
+	if d.err != nil {
+		return false
+	}
+	t, err := d.s.next()
+	if err != nil {
+		d.err = err
+		return false
+	}
+	switch t {
+	case xmlStart:
+		return true
+	case xmlEOF:
+		d.err = errUnexpectedEOF
+	}
+	return false
+}
+
+func (d *xmlDecoder) skip() {
+	if d.err != nil {
+		return
+	}
+	d.err = d.s.skip()
+}
+
+// unmarshal decodes current element with encoding/xml
+func (d *xmlDecoder) unmarshal(v interface{}) {
+	start := d.s.start
+	d.skip()
+	if d.err != nil {
+		return
+	}
+	d.err = xml.Unmarshal(d.s.buf[start:d.s.pos], v)
+}
+
+func (d *xmlDecoder) setErr(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *xmlDecoder) parseInt(v []byte, bitSize int) int64 {
+	if len(v) == 0 {
+		return 0
+	}
+	if i, ok := atoi(v); ok && i >= -1<<(bitSize-1) && i < 1<<(bitSize-1) {
+		return i
+	}
+	i, err := strconv.ParseInt(string(bytes.TrimSpace(v)), 10, bitSize)
+	if err != nil {
+		d.setErr(err)
+	}
+	return i
+}
+
+func (d *xmlDecoder) int(v []byte) int {
+	return int(d.parseInt(v, 64))
+}
+
+func (d *xmlDecoder) intPtr(v []byte) *int {
+	i := d.int(v)
+	return &i
+}
+
+func (d *xmlDecoder) int8(v []byte) int8 {
+	return int8(d.parseInt(v, 8))
+}
+
+func (d *xmlDecoder) int8Ptr(v []byte) *int8 {
+	i := d.int8(v)
+	return &i
+}
+
+func (d *xmlDecoder) float(v []byte) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	f, err := strconv.ParseFloat(string(bytes.TrimSpace(v)), 64)
+	if err != nil {
+		d.setErr(err)
+	}
+	return f
+}
+
+func (d *xmlDecoder) floatPtr(v []byte) *float64 {
+	f := d.float(v)
+	return &f
+}
+
+func (d *xmlDecoder) boolPtr(v []byte) *bool {
+	var b bool
+	if len(v) > 0 {
+		var err error
+		if b, err = strconv.ParseBool(string(bytes.TrimSpace(v))); err != nil {
+			d.setErr(err)
+		}
+	}
+	return &b
+}
+
+// atoi fast path for small positive and negative integers, without
+// conversion to string.
+func atoi(v []byte) (int64, bool) {
+	neg := false
+	if len(v) > 0 && v[0] == '-' {
+		neg = true
+		v = v[1:]
+	}
+	if len(v) == 0 || len(v) > 18 {
+		return 0, false
+	}
+	var n int64
+	for _, c := range v {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int64(c-'0')
+	}
+	if neg {
+		n = -n
+	}
+	return n, true
+}
+
+func newXMLDecoder(buf []byte) *xmlDecoder {
+	return &xmlDecoder{s: xmlScanner{buf: buf}}
+}
+
+// decodeHeader reads producer and timestamp from the root element attributes,
+// without decoding the rest of the message.
+func decodeHeader(buf []byte, h *Header) error {
+	d := newXMLDecoder(buf)
+	if !d.root() {
+		return d.err
+	}
+	for _, a := range d.s.attrs {
+		switch string(a.name) {
+		case "product":
+			h.Producer = Producer(d.int8(a.value))
+		case "timestamp":
+			h.Timestamp = d.int(a.value)
+		}
+	}
+	return d.err
+}
+
+// decodeOddsChange decodes odds_change message, same as xml.Unmarshal
+func decodeOddsChange(buf []byte, o *OddsChange) error {
+	d := newXMLDecoder(buf)
+	if !d.root() {
+		return d.err
+	}
+	for _, a := range d.s.attrs {
+		switch string(a.name) {
+		case "event_id":
+			o.EventURN = URN(a.value)
+		case "product":
+			o.Producer = Producer(d.int8(a.value))
+		case "timestamp":
+			o.Timestamp = d.int(a.value)
+		case "odds_change_reason":
+			o.OddsChangeReason = d.intPtr(a.value)
+		case "request_id":
+			o.RequestID = d.intPtr(a.value)
+		}
+	}
+	for d.child() {
+		switch string(d.s.name) {
+		case "sport_event_status":
+			if o.EventStatus == nil {
+				o.EventStatus = &SportEventStatus{}
+			}
+			d.unmarshal(o.EventStatus)
+		case "odds_generation_properties":
+			if o.OddsGenerationProperties == nil {
+				o.OddsGenerationProperties = &OddsGenerationProperties{}
+			}
+			p := o.OddsGenerationProperties
+			for _, a := range d.s.attrs {
+				switch string(a.name) {
+				case "expected_totals":
+					p.ExpectedTotals = d.floatPtr(a.value)
+				case "expected_supremacy":
+					p.ExpectedSupremacy = d.floatPtr(a.value)
+				}
+			}
+			d.skip()
+		case "odds":
+			d.odds(o)
+		default:
+			d.skip()
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	o.EventID = o.EventURN.EventID()
+	return nil
+}
+
+func (d *xmlDecoder) odds(o *OddsChange) {
+	for _, a := range d.s.attrs {
+		switch string(a.name) {
+		case "betting_status":
+			o.BettingStatus = d.intPtr(a.value)
+		case "betstop_reason":
+			o.BetstopReason = d.intPtr(a.value)
+		}
+	}
+	for d.child() {
+		if string(d.s.name) != "market" {
+			d.skip()
+			continue
+		}
+		o.Markets = append(o.Markets, Market{})
+		d.market(&o.Markets[len(o.Markets)-1])
+	}
+}
+
+func (d *xmlDecoder) market(m *Market) {
+	var status *int8
+	var specifiers, extendedSpecifiers []byte
+	for _, a := range d.s.attrs {
+		switch string(a.name) {
+		case "id":
+			m.ID = d.int(a.value)
+		case "status":
+			status = d.int8Ptr(a.value)
+		case "cashout_status":
+			cs := CashoutStatus(d.int8(a.value))
+			m.CashoutStatus = &cs
+		case "favourite":
+			m.Favourite = d.boolPtr(a.value)
+		case "specifiers":
+			specifiers = a.value
+		case "extended_specifiers":
+			extendedSpecifiers = a.value
+		}
+	}
+	for d.child() {
+		switch string(d.s.name) {
+		case "outcome":
+			m.Outcomes = append(m.Outcomes, Outcome{})
+			d.outcome(&m.Outcomes[len(m.Outcomes)-1])
+		case "market_metadata":
+			for _, a := range d.s.attrs {
+				if string(a.name) == "next_betstop" {
+					m.NextBetstop = d.intPtr(a.value)
+				}
+			}
+			d.skip()
+		default:
+			d.skip()
+		}
+	}
+	m.Status = MarketStatusActive // default
+	if status != nil {
+		m.Status = MarketStatus(*status)
+	}
+	var err error
+	if m.Specifiers, m.LineID, err = toSpecifiersLineID(string(specifiers), string(extendedSpecifiers)); err != nil {
+		d.setErr(err)
+	}
+	m.VariantID = toVariantID(variantSpecifier(m.Specifiers))
+}
+
+func (d *xmlDecoder) outcome(o *Outcome) {
+	for _, a := range d.s.attrs {
+		switch string(a.name) {
+		case "id":
+			o.ID, o.PlayerID, o.Competitors = d.outcomeIDs(a.value)
+		case "odds":
+			o.Odds = d.floatPtr(a.value)
+		case "probabilities":
+			o.Probabilities = d.floatPtr(a.value)
+		case "active":
+			o.Active = d.boolPtr(a.value)
+		case "team":
+			t := Team(d.int8(a.value))
+			o.Team = &t
+		}
+	}
+	d.skip()
+}
+
+// outcomeIDs same as toOutcomeIDs but without string conversion for the plain
+// numeric ids.
+func (d *xmlDecoder) outcomeIDs(v []byte) (int, int, []int) {
+	if len(v) > 0 && v[0] != '-' {
+		if i, ok := atoi(v); ok {
+			return int(i), 0, nil
+		}
+	}
+	id, playerID, competitors, err := toOutcomeIDs(string(v))
+	d.setErr(err)
+	return id, playerID, competitors
+}
+
+// decodeBetStop decodes bet_stop message, same as xml.Unmarshal
+func decodeBetStop(buf []byte, b *BetStop) error {
+	d := newXMLDecoder(buf)
+	if !d.root() {
+		return d.err
+	}
+	var groups []byte
+	var marketStatus *int
+	for _, a := range d.s.attrs {
+		switch string(a.name) {
+		case "event_id":
+			b.EventURN = URN(a.value)
+		case "timestamp":
+			b.Timestamp = d.int(a.value)
+		case "request_id":
+			b.RequestID = d.intPtr(a.value)
+		case "product":
+			b.Producer = Producer(d.int8(a.value))
+		case "groups":
+			groups = a.value
+		case "market_status":
+			marketStatus = d.intPtr(a.value)
+		}
+	}
+	d.skip()
+	if d.err != nil {
+		return d.err
+	}
+	b.EventID = b.EventURN.EventID()
+	b.Status = toMarketStatus(marketStatus)
+	b.Groups = toGroups(string(groups))
+	return nil
+}
+
+// decodeBetSettlement decodes bet_settlement message, same as xml.Unmarshal
+func decodeBetSettlement(buf []byte, b *BetSettlement) error {
+	d := newXMLDecoder(buf)
+	if !d.root() {
+		return d.err
+	}
+	for _, a := range d.s.attrs {
+		switch string(a.name) {
+		case "event_id":
+			b.EventURN = URN(a.value)
+		case "product":
+			b.Producer = Producer(d.int8(a.value))
+		case "timestamp":
+			b.Timestamp = d.int(a.value)
+		case "request_id":
+			b.RequestID = d.intPtr(a.value)
+		case "certainty":
+			b.Certainty = d.int8Ptr(a.value)
+		}
+	}
+	for d.child() {
+		if string(d.s.name) != "outcomes" {
+			d.skip()
+			continue
+		}
+		for d.child() {
+			if string(d.s.name) != "market" {
+				d.skip()
+				continue
+			}
+			b.Markets = append(b.Markets, BetSettlementMarket{})
+			d.betSettlementMarket(&b.Markets[len(b.Markets)-1])
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	b.EventID = b.EventURN.EventID()
+	return nil
+}
+
+func (d *xmlDecoder) betSettlementMarket(m *BetSettlementMarket) {
+	var specifiers, extendedSpecifiers []byte
+	for _, a := range d.s.attrs {
+		switch string(a.name) {
+		case "id":
+			m.ID = d.int(a.value)
+		case "void_reason":
+			m.VoidReason = d.intPtr(a.value)
+		case "result":
+			r := string(a.value)
+			m.Result = &r
+		case "specifiers":
+			specifiers = a.value
+		case "extended_specifiers":
+			extendedSpecifiers = a.value
+		}
+	}
+	for d.child() {
+		if string(d.s.name) != "outcome" {
+			d.skip()
+			continue
+		}
+		m.Outcomes = append(m.Outcomes, BetSettlementOutcome{})
+		d.betSettlementOutcome(&m.Outcomes[len(m.Outcomes)-1])
+	}
+	var err error
+	if m.Specifiers, m.LineID, err = toSpecifiersLineID(string(specifiers), string(extendedSpecifiers)); err != nil {
+		d.setErr(err)
+	}
+	m.VariantID = toVariantID(variantSpecifier(m.Specifiers))
+}
+
+func (d *xmlDecoder) betSettlementOutcome(o *BetSettlementOutcome) {
+	var result *int
+	var voidFactor, deadHeatFactor *float64
+	for _, a := range d.s.attrs {
+		switch string(a.name) {
+		case "id":
+			o.ID, o.PlayerID, _ = d.outcomeIDs(a.value)
+		case "result":
+			result = d.intPtr(a.value)
+		case "void_factor":
+			voidFactor = d.floatPtr(a.value)
+		case "dead_heat_factor":
+			deadHeatFactor = d.floatPtr(a.value)
+		}
+	}
+	d.skip()
+	o.Result = toResult(result, voidFactor, deadHeatFactor)
+	if o.Result == OutcomeResultWinWithDeadHead && deadHeatFactor != nil {
+		o.DeadHeatFactor = *deadHeatFactor
+	}
+}
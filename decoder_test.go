@@ -0,0 +1,152 @@
+package uof
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeOddsChange(t *testing.T) {
+	for _, fn := range []string{"odds_change-0.xml", "odds_change-vhc.xml"} {
+		buf, err := ioutil.ReadFile("./testdata/" + fn)
+		assert.NoError(t, err)
+
+		var expected, actual OddsChange
+		assert.NoError(t, xml.Unmarshal(buf, &expected))
+		assert.NoError(t, decodeOddsChange(buf, &actual))
+		assert.Equal(t, expected, actual, fn)
+	}
+
+	buf := []byte(`<?xml version="1.0"?>
+	<!-- comment -->
+	<odds_change product="1" event_id="sr:match:1" timestamp="1" request_id="2" odds_change_reason="1">
+		<odds_generation_properties expected_totals="2.5" expected_supremacy="-0.5"/>
+		<odds>
+			<market id="1" cashout_status="-1" favourite="true" extended_specifiers="a=&lt;b&gt;">
+				<unknown><outcome id="9"/></unknown>
+				<outcome id="sr:player:123" team="2" probabilities="0.5"/>
+				<outcome id="sr:competitor:1,sr:competitor:2" active="0"/>
+				<outcome id="-1" odds=""/>
+			</market>
+		</odds>
+	</odds_change>`)
+	var expected, actual OddsChange
+	assert.NoError(t, xml.Unmarshal(buf, &expected))
+	assert.NoError(t, decodeOddsChange(buf, &actual))
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, "<b>", actual.Markets[0].Specifiers["a"])
+}
+
+func TestDecodeBetStop(t *testing.T) {
+	for _, s := range []string{
+		`<bet_stop timestamp="12345" product="3" event_id="sr:match:471123" groups="all"/>`,
+		`<bet_stop timestamp="12345" product="3" event_id="sr:match:471123" groups="10_min|180s"/>`,
+		`<bet_stop groups="all" market_status="0" product="1" event_id="sr:match:18001015" timestamp="1234578910111" request_id="1"></bet_stop>`,
+	} {
+		var expected, actual BetStop
+		assert.NoError(t, xml.Unmarshal([]byte(s), &expected))
+		assert.NoError(t, decodeBetStop([]byte(s), &actual))
+		assert.Equal(t, expected, actual, s)
+	}
+}
+
+func TestDecodeBetSettlement(t *testing.T) {
+	buf, err := ioutil.ReadFile("./testdata/bet_settlement.xml")
+	assert.NoError(t, err)
+
+	var expected, actual BetSettlement
+	assert.NoError(t, xml.Unmarshal(buf, &expected))
+	assert.NoError(t, decodeBetSettlement(buf, &actual))
+	assert.Equal(t, expected, actual)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`<odds_change`,
+		`<odds_change product="1">`,
+		`<odds_change product="1"></odds>`,
+		`<odds_change product=1/>`,
+		`<odds_change product="300"/>`,
+		`<odds_change timestamp="pero"/>`,
+		`<odds_change event_id="&pero;"/>`,
+		`<odds_change><odds><market id="1"><outcome active="maybe"/></market></odds></odds_change>`,
+		`<odds_change><odds><market id="1"><outcome odds="x"/></market></odds></odds_change>`,
+		`<odds_change><sport_event_status status="x"/></odds_change>`,
+		`<odds_change><!-- </odds_change>`,
+		`<odds_change><odds><market id="1" specifiers="total"/></odds></odds_change>`,
+		`<odds_change><odds><market id="1" extended_specifiers="=1"/></odds></odds_change>`,
+		`<odds_change><odds><market id="1"><outcome id="sr:player:x"/></market></odds></odds_change>`,
+		`<odds_change><odds><market id="1"><outcome id="sr:player:-1"/></market></odds></odds_change>`,
+		`<odds_change><odds><market id="1"><outcome id="sr:competitor:1,2"/></market></odds></odds_change>`,
+	} {
+		var expected, actual OddsChange
+		assert.Error(t, xml.Unmarshal([]byte(s), &expected), s)
+		assert.Error(t, decodeOddsChange([]byte(s), &actual), s)
+	}
+
+	var bs BetStop
+	assert.Error(t, decodeBetStop([]byte(`<bet_stop market_status="x"/>`), &bs))
+	var bst BetSettlement
+	assert.Error(t, decodeBetSettlement([]byte(`<bet_settlement><outcomes><market id="1"><outcome result="x"/></market></outcomes></bet_settlement>`), &bst))
+}
+
+func benchmarkDecode(b *testing.B, fn string, decode func([]byte) error) {
+	buf, err := ioutil.ReadFile("./testdata/" + fn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		if err := decode(buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOddsChangeUnmarshal(b *testing.B) {
+	benchmarkDecode(b, "odds_change-0.xml", func(buf []byte) error {
+		return xml.Unmarshal(buf, &OddsChange{})
+	})
+}
+
+func BenchmarkOddsChangeDecode(b *testing.B) {
+	benchmarkDecode(b, "odds_change-0.xml", func(buf []byte) error {
+		return decodeOddsChange(buf, &OddsChange{})
+	})
+}
+
+func BenchmarkBetSettlementUnmarshal(b *testing.B) {
+	benchmarkDecode(b, "bet_settlement.xml", func(buf []byte) error {
+		return xml.Unmarshal(buf, &BetSettlement{})
+	})
+}
+
+func BenchmarkBetSettlementDecode(b *testing.B) {
+	benchmarkDecode(b, "bet_settlement.xml", func(buf []byte) error {
+		return decodeBetSettlement(buf, &BetSettlement{})
+	})
+}
+
+func BenchmarkBetStopUnmarshal(b *testing.B) {
+	buf := []byte(`<bet_stop timestamp="12345" product="3" event_id="sr:match:471123" groups="10_min|180s"/>`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := xml.Unmarshal(buf, &BetStop{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBetStopDecode(b *testing.B) {
+	buf := []byte(`<bet_stop timestamp="12345" product="3" event_id="sr:match:471123" groups="10_min|180s"/>`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := decodeBetStop(buf, &BetStop{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
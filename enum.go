@@ -1,9 +1,7 @@
 package uof
 
 import (
-	"fmt"
 	"hash/fnv"
-	"strconv"
 	"strings"
 )
 
@@ -123,161 +121,6 @@ const (
 	srCompetitor = "sr:competitor:"
 )
 
-type URN string
-
-func (u URN) ID() int {
-	if u == "" {
-		return 0
-	}
-	p := strings.Split(string(u), ":")
-	if len(p) != 3 {
-		return 0
-	}
-	i, _ := strconv.ParseUint(p[2], 10, 64)
-	return int(i)
-}
-
-func (u URN) Empty() bool {
-	return string(u) == ""
-}
-
-func NewEventURN(eventID int) URN {
-	return URN(fmt.Sprintf("%s%d", srMatch, eventID))
-}
-
-func (u URN) String() string {
-	return string(u)
-}
-
-func (u *URN) Parse(s string) {
-	r := URN(s)
-	if id, err := strconv.Atoi(s); err == nil {
-		r = NewEventURN(id)
-	}
-	*u = r
-}
-
-const NoURN = URN("")
-
-// EventID tries to generate unique id for all types of events. Most comon are
-// those with prefix sr:match for them we reserve positive id-s. All others got
-// range in negative ids.
-// Reference: https://docs.betradar.com/display/BD/MG+-+Entities
-//            http://sdk.sportradar.com/content/unifiedfeedsdk/net/doc/html/e1f73019-73cd-c9f8-0d58-7fe25800abf2.htm
-// List of currently existing event types is taken from the combo box in the
-// integration control page. From method "Fixture for a specified sport event".
-// !!! Refactored to Producer + EventID in producer.
-//nolint:gocyclo //accepting complexity of 23
-func (u URN) EventID() int {
-	id, _ := u.split()
-	if id == 0 {
-		return 0
-	}
-	if u.Producer() != ProducerUnknown {
-		return id
-	}
-	return u.uniqueEventID()
-}
-
-func (u URN) uniqueEventID() int {
-	id, prefix := u.split()
-	if id == 0 {
-		return 0
-	}
-	suffixID := func(suffix int8) int {
-		return -(id<<8 | int(suffix))
-	}
-
-	switch prefix {
-	case "sr:match":
-		return id
-	case "sr:stage":
-		return suffixID(1)
-	case "sr:season":
-		return suffixID(2)
-	case "sr:tournament":
-		return suffixID(3)
-	case "sr:simple_tournament":
-		return suffixID(4)
-	case "test:match":
-		return id //suffixID(15)
-	case "vf:match":
-		return suffixID(16)
-	case "vf:season":
-		return suffixID(17)
-	case "vf:tournament":
-		return suffixID(18)
-	case "vbl:match":
-		return suffixID(19)
-	case "vbl:season":
-		return suffixID(20)
-	case "vbl:tournament":
-		return suffixID(21)
-	case "vto:match":
-		return suffixID(22)
-	case "vto:season":
-		return suffixID(23)
-	case "vto:tournament":
-		return suffixID(24)
-	case "vdr:stage":
-		return suffixID(25)
-	case "vhc:stage":
-		return suffixID(26)
-	case "vti:match":
-		return suffixID(27)
-	case "vti:tournament":
-		return suffixID(28)
-	case "wns:draw":
-		return suffixID(29)
-	default:
-		return 0
-	}
-}
-
-func (u URN) Producer() Producer {
-	p := strings.Split(string(u), ":")
-	if len(p) != 3 {
-		return ProducerUnknown
-	}
-	for _, d := range producers {
-		if d.code == p[0] {
-			return Producer(d.id)
-		}
-	}
-	return ProducerUnknown
-}
-
-// splits urn into id and prefix
-func (u URN) split() (int, string) {
-	if u == "" {
-		return 0, ""
-	}
-	p := strings.Split(string(u), ":")
-	if len(p) != 3 {
-		return 0, ""
-	}
-	i, err := strconv.ParseUint(p[2], 10, 64)
-	if err != nil {
-		return 0, ""
-	}
-	id := int(i)
-	prefix := p[0] + ":" + p[1]
-
-	return id, prefix
-}
-
-func (u URN) IsTournament() bool {
-	p := strings.Split(string(u), ":")
-	if len(p) != 3 {
-		return false
-	}
-	return p[1] == "season" || p[1] == "tournament"
-}
-
-func (u URN) IsTest() bool {
-	return strings.HasPrefix(string(u), "test:match:")
-}
-
 func toLineID(specifiers string) int {
 	if specifiers == "" {
 		return 0
@@ -364,6 +207,9 @@ const (
 	MessageTypeBetStop
 	MessageTypeRollbackBetSettlement
 	MessageTypeRollbackBetCancel
+	// Generated by the SDK, never received from the feed. Lifts the SDK
+	// generated bet stop of the same origin, body is BetStop.
+	MessageTypeBetStopLift
 )
 
 // api message types
@@ -373,6 +219,7 @@ const (
 	MessageTypePlayer
 	MessageTypeCompetitor
 	MessageTypeTournament
+	MessageTypeCashoutProbabilities
 )
 
 // system message types
@@ -393,12 +240,14 @@ var messageTypes = []MessageType{
 	MessageTypeBetStop,
 	MessageTypeRollbackBetSettlement,
 	MessageTypeRollbackBetCancel,
+	MessageTypeBetStopLift,
 
 	MessageTypeFixture,
 	MessageTypeMarkets,
 	MessageTypePlayer,
 	MessageTypeCompetitor,
 	MessageTypeTournament,
+	MessageTypeCashoutProbabilities,
 
 	MessageTypeAlive,
 	MessageTypeSnapshotComplete,
@@ -416,12 +265,14 @@ var messageTypeNames = []string{
 	"bet_stop",
 	"rollback_bet_settlement",
 	"rollback_bet_cancel",
+	"bet_stop_lift",
 
 	"fixture",
 	"market",
 	"player",
 	"competitor",
 	"tournament",
+	"cashout_probabilities",
 
 	"alive",
 	"snapshot_complete",
@@ -449,8 +300,10 @@ func (m MessageType) String() string {
 	return InvalidName
 }
 
+// Kind of the message type. Cashout probabilities are api messages but they
+// are for one event, so they are of the event kind.
 func (m MessageType) Kind() MessageKind {
-	if m < 32 {
+	if m < 32 || m == MessageTypeCashoutProbabilities {
 		return MessageKindEvent
 	}
 	if m < 64 {
@@ -26,27 +26,6 @@ func TestProducer(t *testing.T) {
 	assert.True(t, Producer(11).Virtuals())
 }
 
-func TestURN(t *testing.T) {
-	u := URN("sr:match:123")
-	assert.Equal(t, 123, u.ID())
-	//assert.Equal(t, URNTypeMatch, u.Type())
-	assert.Equal(t, URN("sr:match:123"), NewEventURN(123))
-	assert.Equal(t, "sr:match:123", URN("sr:match:123").String())
-
-	assert.Equal(t, 0, URN("").ID())
-	assert.True(t, URN("").Empty())
-	assert.Equal(t, 0, URN("").EventID())
-	assert.Equal(t, 0, URN("pero").ID())
-
-	//assert.Equal(t, URNTypeUnknown, URN("").Type())
-	//assert.Equal(t, URNTypeUnknown, URN("pero").Type())
-	assert.Equal(t, 0, URN("pero").EventID())
-
-	u.Parse("123")
-	assert.Equal(t, URN("sr:match:123"), u)
-	assert.Equal(t, 123, u.EventID())
-}
-
 func TestLanguage(t *testing.T) {
 	var l Lang
 	l.Parse("hr")
@@ -80,52 +59,8 @@ func TestMessageTypes(t *testing.T) {
 	assert.Equal(t, MessageKindEvent, MessageType(1).Kind())
 	assert.Equal(t, MessageKindLexicon, MessageType(32).Kind())
 	assert.Equal(t, MessageKindSystem, MessageType(64).Kind())
-}
-
-func TestURNEventID(t *testing.T) {
-	assert.Equal(t, -0xff01, URN("sr:stage:255").uniqueEventID())
-	assert.Equal(t, -0xff02, URN("sr:season:255").uniqueEventID())
-	assert.Equal(t, -0xff1C, URN("vti:tournament:255").uniqueEventID())
-	assert.Equal(t, 0, URN("pero:zdero:255").uniqueEventID())
-
-	data := []struct {
-		u  string
-		id int
-	}{
-		{"sr:match:127", 127},
-
-		{"sr:stage:127", -0x7f01},
-		{"sr:season:255", -0xff02},
-		{"sr:tournament:255", -0xff03},
-		{"sr:simple_tournament:255", -0xff04},
-
-		{"test:match:255", -0xff0F},
-
-		{"vf:match:255", -0xff10},
-		{"vf:season:255", -0xff11},
-		{"vf:tournament:255", -0xff12},
-
-		{"vbl:match:255", -0xff13},
-		{"vbl:season:255", -0xff14},
-		{"vbl:tournament:255", -0xff15},
-
-		{"vto:match:255", -0xff16},
-		{"vto:season:255", -0xff17},
-		{"vto:tournament:255", -0xff18},
-
-		{"vdr:stage:255", -0xff19},
-		{"vhc:stage:255", -0xff1A},
-
-		{"vti:match:255", -0xff1B},
-		{"vti:tournament:255", -0xff1C},
-
-		{"wns:draw:255", -0xff1D},
-		// invalid
-		{"pero:zdero:255", 0},
-	}
-	for _, d := range data {
-		assert.Equal(t, d.id, URN(d.u).uniqueEventID())
-	}
+	assert.Equal(t, MessageKindEvent, MessageTypeCashoutProbabilities.Kind())
+	assert.Equal(t, MessageKindLexicon, MessageTypeTournament.Kind())
 }
 
 func TestProducersChange(t *testing.T) {
@@ -75,6 +75,17 @@ func (s *CashoutStatus) PtrVal() *int8 {
 	return &v
 }
 
+// BetStopOrigin is the source of the bet stop. Bet stops generated by the SDK
+// (Header.Generated is set) have the origin of the stage which made them.
+type BetStopOrigin int8
+
+const (
+	BetStopOriginFeed         BetStopOrigin = iota // received from the feed
+	BetStopOriginProducerDown                      // Suspension stage, producer is down
+	BetStopOriginHandover                          // Handover stage, market handed over to live
+	BetStopOriginNextBetstop                       // NextBetstop stage, next betstop time passed
+)
+
 type Team int8
 
 const (
@@ -14,37 +14,37 @@ type FixtureRsp struct {
 // Fixtures describe static or semi-static information about matches and races.
 // Reference: https://docs.betradar.com/display/BD/UOF+-+Fixtures+in+the+API
 type Fixture struct {
-	ID                 int       `xml:"-" json:"id" bson:"id,omitempty"`
-	URN                URN       `xml:"id,attr,omitempty" json:"urn" bson:"urn,omitempty"`
-	StartTime          time.Time `xml:"start_time,attr,omitempty" json:"startTime,omitempty" bson:"startTime,omitempty"`
-	StartTimeConfirmed bool      `xml:"start_time_confirmed,attr,omitempty" json:"startTimeConfirmed,omitempty" bson:"startTimeConfirmed,omitempty"`
-	StartTimeTbd       bool      `xml:"start_time_tbd,attr,omitempty" json:"startTimeTbd,omitempty" bson:"startTimeTbd,omitempty"`
-	NextLiveTime       time.Time `xml:"next_live_time,attr,omitempty" json:"nextLiveTime,omitempty" bson:"nextLiveTime,omitempty"`
-	Liveodds           string    `xml:"liveodds,attr,omitempty" json:"liveodds,omitempty" bson:"liveodds,omitempty"`
-	Status             string    `xml:"status,attr,omitempty" json:"status,omitempty" bson:"status,omitempty"`
-	Name               string    `xml:"name,attr,omitempty" json:"name,omitempty" bson:"name,omitempty"`
-	Type               string    `xml:"type,attr,omitempty" json:"type,omitempty" bson:"type,omitempty"`
-	Scheduled          time.Time `xml:"scheduled,attr,omitempty" json:"scheduled,omitempty" bson:"scheduled,omitempty"`
-	ScheduledEnd       time.Time `xml:"scheduled_end,attr,omitempty" json:"scheduledEnd,omitempty" bson:"scheduledEnd,omitempty"`
-	ReplacedBy         string    `xml:"replaced_by,attr,omitempty" json:"replacedBy,omitempty" bson:"replacedBy,omitempty"`
-
-	Sport      Sport      `xml:"sport" json:"sport" bson:"sport,omitempty"`
-	Category   Category   `xml:"category" json:"category" bson:"category,omitempty"`
-	Tournament Tournament `xml:"tournament,omitempty" json:"tournament,omitempty" bson:"tournament,omitempty"`
-
-	Round  Round  `xml:"tournament_round,omitempty" json:"round,omitempty" bson:"round,omitempty"`
-	Season Season `xml:"season,omitempty" json:"season,omitempty" bson:"season,omitempty"`
-	Venue  Venue  `xml:"venue,omitempty" json:"venue,omitempty" bson:"venue,omitempty"`
-
-	ProductInfo ProductInfo  `xml:"product_info,omitempty" json:"productInfo,omitempty" bson:"productInfo,omitempty"`
-	Competitors []Competitor `xml:"competitors>competitor,omitempty" json:"competitors,omitempty" bson:"competitors,omitempty"`
-	TvChannels  []TvChannel  `xml:"tv_channels>tv_channel,omitempty" json:"tvChannels,omitempty" bson:"tvChannels,omitempty"`
-
-	Home Competitor `json:"home" bson:"home,omitempty"`
-	Away Competitor `json:"away" bson:"away,omitempty"`
-
-	ExtraInfo []ExtraInfo  `xml:"extra_info>info,omitempty" json:"extraInfo,omitempty" bson:"extraInfo,omitempty"`
-	Races     []SportEvent `xml:"races>sport_event,omitempty" json:"races,omitempty" bson:"races,omitempty"`
+	ID                 int       `xml:"-" json:"id" bson:"id,omitempty" uof:"1"`
+	URN                URN       `xml:"id,attr,omitempty" json:"urn" bson:"urn,omitempty" uof:"2"`
+	StartTime          time.Time `xml:"start_time,attr,omitempty" json:"startTime,omitempty" bson:"startTime,omitempty" uof:"3"`
+	StartTimeConfirmed bool      `xml:"start_time_confirmed,attr,omitempty" json:"startTimeConfirmed,omitempty" bson:"startTimeConfirmed,omitempty" uof:"4"`
+	StartTimeTbd       bool      `xml:"start_time_tbd,attr,omitempty" json:"startTimeTbd,omitempty" bson:"startTimeTbd,omitempty" uof:"5"`
+	NextLiveTime       time.Time `xml:"next_live_time,attr,omitempty" json:"nextLiveTime,omitempty" bson:"nextLiveTime,omitempty" uof:"6"`
+	Liveodds           string    `xml:"liveodds,attr,omitempty" json:"liveodds,omitempty" bson:"liveodds,omitempty" uof:"7"`
+	Status             string    `xml:"status,attr,omitempty" json:"status,omitempty" bson:"status,omitempty" uof:"8"`
+	Name               string    `xml:"name,attr,omitempty" json:"name,omitempty" bson:"name,omitempty" uof:"9"`
+	Type               string    `xml:"type,attr,omitempty" json:"type,omitempty" bson:"type,omitempty" uof:"10"`
+	Scheduled          time.Time `xml:"scheduled,attr,omitempty" json:"scheduled,omitempty" bson:"scheduled,omitempty" uof:"11"`
+	ScheduledEnd       time.Time `xml:"scheduled_end,attr,omitempty" json:"scheduledEnd,omitempty" bson:"scheduledEnd,omitempty" uof:"12"`
+	ReplacedBy         string    `xml:"replaced_by,attr,omitempty" json:"replacedBy,omitempty" bson:"replacedBy,omitempty" uof:"13"`
+
+	Sport      Sport      `xml:"sport" json:"sport" bson:"sport,omitempty" uof:"14"`
+	Category   Category   `xml:"category" json:"category" bson:"category,omitempty" uof:"15"`
+	Tournament Tournament `xml:"tournament,omitempty" json:"tournament,omitempty" bson:"tournament,omitempty" uof:"16"`
+
+	Round  Round  `xml:"tournament_round,omitempty" json:"round,omitempty" bson:"round,omitempty" uof:"17"`
+	Season Season `xml:"season,omitempty" json:"season,omitempty" bson:"season,omitempty" uof:"18"`
+	Venue  Venue  `xml:"venue,omitempty" json:"venue,omitempty" bson:"venue,omitempty" uof:"19"`
+
+	ProductInfo ProductInfo  `xml:"product_info,omitempty" json:"productInfo,omitempty" bson:"productInfo,omitempty" uof:"20"`
+	Competitors []Competitor `xml:"competitors>competitor,omitempty" json:"competitors,omitempty" bson:"competitors,omitempty" uof:"21"`
+	TvChannels  []TvChannel  `xml:"tv_channels>tv_channel,omitempty" json:"tvChannels,omitempty" bson:"tvChannels,omitempty" uof:"22"`
+
+	Home Competitor `json:"home" bson:"home,omitempty" uof:"23"`
+	Away Competitor `json:"away" bson:"away,omitempty" uof:"24"`
+
+	ExtraInfo []ExtraInfo  `xml:"extra_info>info,omitempty" json:"extraInfo,omitempty" bson:"extraInfo,omitempty" uof:"25"`
+	Races     []SportEvent `xml:"races>sport_event,omitempty" json:"races,omitempty" bson:"races,omitempty" uof:"26"`
 	// this also exists but we are skiping for the time being
 	//ReferenceIDs         ReferenceIDs         `xml:"reference_ids,omitempty" json:"referenceId`s,omitempty"`
 	//SportEventConditions SportEventConditions `xml:"sport_event_conditions,omitempty" json:"sportEventConditions,omitempty"`
@@ -55,102 +55,102 @@ type Fixture struct {
 }
 
 type FixtureTournament struct {
-	ID         int        `xml:"-" json:"id" bson:"id,omitempty"`
-	URN        URN        `xml:"id,attr,omitempty" json:"urn" bson:"urn,omitempty"`
-	Name       string     `xml:"name,attr,omitempty" json:"name,omitempty" bson:"name,omitempty"`
-	Sport      Sport      `xml:"sport" json:"sport" bson:"sport,omitempty"`
-	Category   Category   `xml:"category" json:"category" bson:"category,omitempty"`
-	Tournament Tournament `xml:"tournament,omitempty" json:"tournament,omitempty" bson:"tournament,omitempty"`
-	Season     Season     `xml:"season,omitempty" json:"season,omitempty" bson:"season,omitempty"`
-	Groups     []Group    `xml:"groups>group,omitempty" json:"groups,omitempty" bson:"groups,omitempty"`
+	ID         int        `xml:"-" json:"id" bson:"id,omitempty" uof:"1"`
+	URN        URN        `xml:"id,attr,omitempty" json:"urn" bson:"urn,omitempty" uof:"2"`
+	Name       string     `xml:"name,attr,omitempty" json:"name,omitempty" bson:"name,omitempty" uof:"3"`
+	Sport      Sport      `xml:"sport" json:"sport" bson:"sport,omitempty" uof:"4"`
+	Category   Category   `xml:"category" json:"category" bson:"category,omitempty" uof:"5"`
+	Tournament Tournament `xml:"tournament,omitempty" json:"tournament,omitempty" bson:"tournament,omitempty" uof:"6"`
+	Season     Season     `xml:"season,omitempty" json:"season,omitempty" bson:"season,omitempty" uof:"7"`
+	Groups     []Group    `xml:"groups>group,omitempty" json:"groups,omitempty" bson:"groups,omitempty" uof:"8"`
 }
 
 type Group struct {
-	Name        string       `xml:"name,attr,omitempty" json:"name" bson:"name,omitempty"`
-	Competitors []Competitor `xml:"competitor,omitempty" json:"competitors,omitempty" bson:"competitors,omitempty"`
+	Name        string       `xml:"name,attr,omitempty" json:"name" bson:"name,omitempty" uof:"1"`
+	Competitors []Competitor `xml:"competitor,omitempty" json:"competitors,omitempty" bson:"competitors,omitempty" uof:"2"`
 }
 
 type Tournament struct {
-	ID   int    `json:"id" bson:"id,omitempty"`
-	Name string `xml:"name,attr" json:"name" bson:"name,omitempty"`
+	ID   int    `json:"id" bson:"id,omitempty" uof:"1"`
+	Name string `xml:"name,attr" json:"name" bson:"name,omitempty" uof:"2"`
 }
 
 type Sport struct {
-	ID   int    `json:"id" bson:"id,omitempty"`
-	Name string `xml:"name,attr" json:"name" bson:"name,omitempty"`
+	ID   int    `json:"id" bson:"id,omitempty" uof:"1"`
+	Name string `xml:"name,attr" json:"name" bson:"name,omitempty" uof:"2"`
 }
 
 type Category struct {
-	ID          int    `json:"id" bson:"id,omitempty"`
-	Name        string `xml:"name,attr" json:"name" bson:"name,omitempty"`
-	CountryCode string `xml:"country_code,attr,omitempty" json:"countryCode,omitempty" bson:"countryCode,omitempty"`
+	ID          int    `json:"id" bson:"id,omitempty" uof:"1"`
+	Name        string `xml:"name,attr" json:"name" bson:"name,omitempty" uof:"2"`
+	CountryCode string `xml:"country_code,attr,omitempty" json:"countryCode,omitempty" bson:"countryCode,omitempty" uof:"3"`
 }
 
 type Competitor struct {
-	ID           int                `json:"id" bson:"id,omitempty"`
-	Qualifier    string             `xml:"qualifier,attr,omitempty" json:"qualifier,omitempty" bson:"qualifier,omitempty"`
-	Name         string             `xml:"name,attr" json:"name" bson:"name,omitempty"`
-	Abbreviation string             `xml:"abbreviation,attr" json:"abbreviation" bson:"abbreviation,omitempty"`
-	Country      string             `xml:"country,attr,omitempty" json:"country,omitempty" bson:"country,omitempty"`
-	CountryCode  string             `xml:"country_code,attr,omitempty" json:"countryCode,omitempty" bson:"countryCode,omitempty"`
-	Virtual      bool               `xml:"virtual,attr,omitempty" json:"virtual,omitempty" bson:"virtual,omitempty"`
-	Players      []CompetitorPlayer `xml:"players>player,omitempty" json:"players,omitempty" bson:"players,omitempty"`
+	ID           int                `json:"id" bson:"id,omitempty" uof:"1"`
+	Qualifier    string             `xml:"qualifier,attr,omitempty" json:"qualifier,omitempty" bson:"qualifier,omitempty" uof:"2"`
+	Name         string             `xml:"name,attr" json:"name" bson:"name,omitempty" uof:"3"`
+	Abbreviation string             `xml:"abbreviation,attr" json:"abbreviation" bson:"abbreviation,omitempty" uof:"4"`
+	Country      string             `xml:"country,attr,omitempty" json:"country,omitempty" bson:"country,omitempty" uof:"5"`
+	CountryCode  string             `xml:"country_code,attr,omitempty" json:"countryCode,omitempty" bson:"countryCode,omitempty" uof:"6"`
+	Virtual      bool               `xml:"virtual,attr,omitempty" json:"virtual,omitempty" bson:"virtual,omitempty" uof:"7"`
+	Players      []CompetitorPlayer `xml:"players>player,omitempty" json:"players,omitempty" bson:"players,omitempty" uof:"8"`
 	//ReferenceIDs CompetitorReferenceIDs `xml:"reference_ids,omitempty" json:"referenceIds,omitempty"`
 }
 
 type CompetitorPlayer struct {
-	ID           int    `json:"id" bson:"id,omitempty"`
-	Name         string `xml:"name,attr" json:"name" bson:"name,omitempty"`
-	Abbreviation string `xml:"abbreviation,attr" json:"abbreviation" bson:"abbreviation,omitempty"`
-	Nationality  string `xml:"nationality,attr,omitempty" json:"nationality,omitempty" bson:"nationality,omitempty"`
+	ID           int    `json:"id" bson:"id,omitempty" uof:"1"`
+	Name         string `xml:"name,attr" json:"name" bson:"name,omitempty" uof:"2"`
+	Abbreviation string `xml:"abbreviation,attr" json:"abbreviation" bson:"abbreviation,omitempty" uof:"3"`
+	Nationality  string `xml:"nationality,attr,omitempty" json:"nationality,omitempty" bson:"nationality,omitempty" uof:"4"`
 }
 
 type Venue struct {
-	ID             int    `json:"id" bson:"id,omitempty"`
-	Name           string `xml:"name,attr" json:"name" bson:"name,omitempty"`
-	Capacity       int    `xml:"capacity,attr,omitempty" json:"capacity,omitempty" bson:"capacity,omitempty"`
-	CityName       string `xml:"city_name,attr,omitempty" json:"cityName,omitempty" bson:"cityName,omitempty"`
-	CountryName    string `xml:"country_name,attr,omitempty" json:"countryName,omitempty" bson:"countryName,omitempty"`
-	CountryCode    string `xml:"country_code,attr,omitempty" json:"countryCode,omitempty" bson:"countryCode,omitempty"`
-	MapCoordinates string `xml:"map_coordinates,attr,omitempty" json:"mapCoordinates,omitempty" bson:"mapCoordinates,omitempty"`
+	ID             int    `json:"id" bson:"id,omitempty" uof:"1"`
+	Name           string `xml:"name,attr" json:"name" bson:"name,omitempty" uof:"2"`
+	Capacity       int    `xml:"capacity,attr,omitempty" json:"capacity,omitempty" bson:"capacity,omitempty" uof:"3"`
+	CityName       string `xml:"city_name,attr,omitempty" json:"cityName,omitempty" bson:"cityName,omitempty" uof:"4"`
+	CountryName    string `xml:"country_name,attr,omitempty" json:"countryName,omitempty" bson:"countryName,omitempty" uof:"5"`
+	CountryCode    string `xml:"country_code,attr,omitempty" json:"countryCode,omitempty" bson:"countryCode,omitempty" uof:"6"`
+	MapCoordinates string `xml:"map_coordinates,attr,omitempty" json:"mapCoordinates,omitempty" bson:"mapCoordinates,omitempty" uof:"7"`
 }
 
 type TvChannel struct {
-	Name string `xml:"name,attr" json:"name" bson:"name,omitempty"`
+	Name string `xml:"name,attr" json:"name" bson:"name,omitempty" uof:"1"`
 	// seams to be always zero
 	// StartTime time.Time `xml:"start_time,attr,omitempty" json:"startTime,omitempty"`
 }
 
 type StreamingChannel struct {
-	ID   int    `xml:"id,attr" json:"id" bson:"id,omitempty"`
-	Name string `xml:"name,attr" json:"name" bson:"name,omitempty"`
+	ID   int    `xml:"id,attr" json:"id" bson:"id,omitempty" uof:"1"`
+	Name string `xml:"name,attr" json:"name" bson:"name,omitempty" uof:"2"`
 }
 type ProductInfoLink struct {
-	Name string `xml:"name,attr" json:"name" bson:"name,omitempty"`
-	Ref  string `xml:"ref,attr" json:"ref" bson:"ref,omitempty"`
+	Name string `xml:"name,attr" json:"name" bson:"name,omitempty" uof:"1"`
+	Ref  string `xml:"ref,attr" json:"ref" bson:"ref,omitempty" uof:"2"`
 }
 
 type Round struct {
-	ID                  int    `xml:"betradar_id,attr,omitempty" json:"id,omitempty" bson:"id,omitempty"`
-	Type                string `xml:"type,attr,omitempty" json:"type,omitempty" bson:"type,omitempty"`
-	Number              int    `xml:"number,attr,omitempty" json:"number,omitempty" bson:"number,omitempty"`
-	Name                string `xml:"name,attr,omitempty" json:"name,omitempty" bson:"name,omitempty"`
-	GroupLongName       string `xml:"group_long_name,attr,omitempty" json:"groupLongName,omitempty" bson:"groupLongName,omitempty"`
-	Group               string `xml:"group,attr,omitempty" json:"group,omitempty" bson:"group,omitempty"`
-	GroupID             string `xml:"group_id,attr,omitempty" json:"groupId,omitempty" bson:"groupId,omitempty"`
-	CupRoundMatches     int    `xml:"cup_round_matches,attr,omitempty" json:"cupRoundMatches,omitempty" bson:"cupRoundMatches,omitempty"`
-	CupRoundMatchNumber int    `xml:"cup_round_match_number,attr,omitempty" json:"cupRoundMatchNumber,omitempty" bson:"cupRoundMatchNumber,omitempty"`
-	OtherMatchID        string `xml:"other_match_id,attr,omitempty" json:"otherMatchId,omitempty" bson:"otherMatchId,omitempty"`
+	ID                  int    `xml:"betradar_id,attr,omitempty" json:"id,omitempty" bson:"id,omitempty" uof:"1"`
+	Type                string `xml:"type,attr,omitempty" json:"type,omitempty" bson:"type,omitempty" uof:"2"`
+	Number              int    `xml:"number,attr,omitempty" json:"number,omitempty" bson:"number,omitempty" uof:"3"`
+	Name                string `xml:"name,attr,omitempty" json:"name,omitempty" bson:"name,omitempty" uof:"4"`
+	GroupLongName       string `xml:"group_long_name,attr,omitempty" json:"groupLongName,omitempty" bson:"groupLongName,omitempty" uof:"5"`
+	Group               string `xml:"group,attr,omitempty" json:"group,omitempty" bson:"group,omitempty" uof:"6"`
+	GroupID             string `xml:"group_id,attr,omitempty" json:"groupId,omitempty" bson:"groupId,omitempty" uof:"7"`
+	CupRoundMatches     int    `xml:"cup_round_matches,attr,omitempty" json:"cupRoundMatches,omitempty" bson:"cupRoundMatches,omitempty" uof:"8"`
+	CupRoundMatchNumber int    `xml:"cup_round_match_number,attr,omitempty" json:"cupRoundMatchNumber,omitempty" bson:"cupRoundMatchNumber,omitempty" uof:"9"`
+	OtherMatchID        string `xml:"other_match_id,attr,omitempty" json:"otherMatchId,omitempty" bson:"otherMatchId,omitempty" uof:"10"`
 }
 
 type Season struct {
-	ID        int    `json:"id" bson:"id,omitempty"`
-	StartDate string `xml:"start_date,attr" json:"startDate" bson:"startDate,omitempty"`
-	EndDate   string `xml:"end_date,attr" json:"endDate" bson:"endDate,omitempty"`
-	StartTime string `xml:"start_time,attr,omitempty" json:"startTime,omitempty" bson:"startTime,omitempty"`
-	EndTime   string `xml:"end_time,attr,omitempty" json:"endTime,omitempty" bson:"endTime,omitempty"`
-	Year      string `xml:"year,attr,omitempty" json:"year,omitempty" bson:"year,omitempty"`
-	Name      string `xml:"name,attr" json:"name" bson:"name,omitempty"`
+	ID        int    `json:"id" bson:"id,omitempty" uof:"1"`
+	StartDate string `xml:"start_date,attr" json:"startDate" bson:"startDate,omitempty" uof:"2"`
+	EndDate   string `xml:"end_date,attr" json:"endDate" bson:"endDate,omitempty" uof:"3"`
+	StartTime string `xml:"start_time,attr,omitempty" json:"startTime,omitempty" bson:"startTime,omitempty" uof:"4"`
+	EndTime   string `xml:"end_time,attr,omitempty" json:"endTime,omitempty" bson:"endTime,omitempty" uof:"5"`
+	Year      string `xml:"year,attr,omitempty" json:"year,omitempty" bson:"year,omitempty" uof:"6"`
+	Name      string `xml:"name,attr" json:"name" bson:"name,omitempty" uof:"7"`
 	//TournamentID string    `xml:"tournament_id,attr,omitempty" json:"tournamentId,omitempty"`
 }
 
@@ -171,29 +171,29 @@ type Season struct {
 // }
 
 type ProductInfo struct {
-	Streaming            []StreamingChannel `xml:"streaming>channel,omitempty" json:"streaming,omitempty" bson:"streaming,omitempty"`
-	IsInLiveScore        string             `xml:"is_in_live_score,omitempty" json:"isInLiveScore,omitempty" bson:"isInLiveScore,omitempty"`
-	IsInHostedStatistics string             `xml:"is_in_hosted_statistics,omitempty" json:"isInHostedStatistics,omitempty" bson:"isInHostedStatistics,omitempty"`
-	IsInLiveCenterSoccer string             `xml:"is_in_live_center_soccer,omitempty" json:"isInLiveCenterSoccer,omitempty" bson:"isInLiveCenterSoccer,omitempty"`
-	IsAutoTraded         string             `xml:"is_auto_traded,omitempty" json:"isAutoTraded,omitempty" bson:"isAutoTraded,omitempty"`
-	Links                []ProductInfoLink  `xml:"links>link,omitempty" json:"links,omitempty" bson:"links,omitempty"`
+	Streaming            []StreamingChannel `xml:"streaming>channel,omitempty" json:"streaming,omitempty" bson:"streaming,omitempty" uof:"1"`
+	IsInLiveScore        string             `xml:"is_in_live_score,omitempty" json:"isInLiveScore,omitempty" bson:"isInLiveScore,omitempty" uof:"2"`
+	IsInHostedStatistics string             `xml:"is_in_hosted_statistics,omitempty" json:"isInHostedStatistics,omitempty" bson:"isInHostedStatistics,omitempty" uof:"3"`
+	IsInLiveCenterSoccer string             `xml:"is_in_live_center_soccer,omitempty" json:"isInLiveCenterSoccer,omitempty" bson:"isInLiveCenterSoccer,omitempty" uof:"4"`
+	IsAutoTraded         string             `xml:"is_auto_traded,omitempty" json:"isAutoTraded,omitempty" bson:"isAutoTraded,omitempty" uof:"5"`
+	Links                []ProductInfoLink  `xml:"links>link,omitempty" json:"links,omitempty" bson:"links,omitempty" uof:"6"`
 }
 
 // ExtraInfo covers additional fixture information about the match,
 // such as coverage information, extended markets offer, additional rules etc.
 type ExtraInfo struct {
-	Key   string `xml:"key,attr,omitempty" json:"key,omitempty" bson:"key,omitempty"`
-	Value string `xml:"value,attr,omitempty" json:"value,omitempty" bson:"value,omitempty"`
+	Key   string `xml:"key,attr,omitempty" json:"key,omitempty" bson:"key,omitempty" uof:"1"`
+	Value string `xml:"value,attr,omitempty" json:"value,omitempty" bson:"value,omitempty" uof:"2"`
 }
 
 // SportEvent covers information about scheduled races in a stage
 // For VHC and VDR information is in vdr/vhc:stage:<int> fixture with type="parent"
 type SportEvent struct {
-	ID           string    `xml:"id,attr,omitempty" json:"id,omitempty" bson:"id,omitempty"`
-	Name         string    `xml:"name,attr,omitempty" json:"name,omitempty" bson:"name,omitempty"`
-	Type         string    `xml:"type,attr,omitempty" json:"type,omitempty" bson:"type,omitempty"`
-	Scheduled    time.Time `xml:"scheduled,attr,omitempty" json:"scheduled,omitempty" bson:"scheduled,omitempty"`
-	ScheduledEnd time.Time `xml:"scheduled_end,attr,omitempty" json:"scheduled_end,omitempty" bson:"scheduledEnd,omitempty"`
+	ID           string    `xml:"id,attr,omitempty" json:"id,omitempty" bson:"id,omitempty" uof:"1"`
+	Name         string    `xml:"name,attr,omitempty" json:"name,omitempty" bson:"name,omitempty" uof:"2"`
+	Type         string    `xml:"type,attr,omitempty" json:"type,omitempty" bson:"type,omitempty" uof:"3"`
+	Scheduled    time.Time `xml:"scheduled,attr,omitempty" json:"scheduled,omitempty" bson:"scheduled,omitempty" uof:"4"`
+	ScheduledEnd time.Time `xml:"scheduled_end,attr,omitempty" json:"scheduled_end,omitempty" bson:"scheduledEnd,omitempty" uof:"5"`
 }
 
 func (f *Fixture) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
@@ -212,10 +212,12 @@ func (f *Fixture) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 		return err
 	}
 	f.ID = overlay.URN.EventID()
-	f.Sport = overlay.Tournament.Sport
-	f.Category = overlay.Tournament.Category
-	f.Tournament.ID = overlay.Tournament.URN.ID()
-	f.Tournament.Name = overlay.Tournament.Name
+	if overlay.Tournament != nil {
+		f.Sport = overlay.Tournament.Sport
+		f.Category = overlay.Tournament.Category
+		f.Tournament.ID = overlay.Tournament.URN.ID()
+		f.Tournament.Name = overlay.Tournament.Name
+	}
 
 	for _, c := range f.Competitors {
 		if c.Qualifier == "home" {
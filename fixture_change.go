@@ -16,14 +16,14 @@ import (
 // call to lookup the updated fixture information.
 // Reference: https://docs.betradar.com/display/BD/UOF+-+Fixture+change
 type FixtureChange struct {
-	EventID      int                `json:"eventId" bson:"eventId,omitempty"`
-	EventURN     URN                `xml:"event_id,attr" json:"eventURN" bson:"eventURN,omitempty"`
-	Producer     Producer           `xml:"product,attr" json:"producer" bson:"producer,omitempty"`
-	Timestamp    int                `xml:"timestamp,attr" json:"timestamp" bson:"timestamp,omitempty"`
-	RequestID    *int               `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty"`
-	ChangeType   *FixtureChangeType `xml:"change_type,attr,omitempty" json:"changeType,omitempty" bson:"changeType,omitempty"`
-	StartTime    *int               `xml:"start_time,attr" json:"startTime" bson:"startTime,omitempty"`
-	NextLiveTime *int               `xml:"next_live_time,attr,omitempty" json:"nextLiveTime,omitempty" bson:"nextLiveTime,omitempty"`
+	EventID      int                `json:"eventId" bson:"eventId,omitempty" uof:"1"`
+	EventURN     URN                `xml:"event_id,attr" json:"eventURN" bson:"eventURN,omitempty" uof:"2"`
+	Producer     Producer           `xml:"product,attr" json:"producer" bson:"producer,omitempty" uof:"3"`
+	Timestamp    int                `xml:"timestamp,attr" json:"timestamp" bson:"timestamp,omitempty" uof:"4"`
+	RequestID    *int               `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty" uof:"5"`
+	ChangeType   *FixtureChangeType `xml:"change_type,attr,omitempty" json:"changeType,omitempty" bson:"changeType,omitempty" uof:"6"`
+	StartTime    *int               `xml:"start_time,attr" json:"startTime" bson:"startTime,omitempty" uof:"7"`
+	NextLiveTime *int               `xml:"next_live_time,attr,omitempty" json:"nextLiveTime,omitempty" bson:"nextLiveTime,omitempty" uof:"8"`
 }
 
 func (fc *FixtureChange) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
@@ -0,0 +1,208 @@
+//go:build go1.18
+// +build go1.18
+
+package uof
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Fuzz targets for the message parsers. Seed corpus is taken from the
+// testdata files. Run with:
+//   go test -run x -fuzz FuzzOddsChange
+
+// addCorpus adds testdata files matching the pattern to the fuzz seed corpus
+func addCorpus(f *testing.F, pattern string) {
+	files, err := filepath.Glob(filepath.Join("testdata", pattern))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, fn := range files {
+		buf, err := ioutil.ReadFile(fn)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(buf)
+	}
+}
+
+// NaN values are never equal, skip comparison for them
+func noNaN(buf []byte) bool {
+	return !bytes.Contains(bytes.ToLower(buf), []byte("nan"))
+}
+
+func FuzzOddsChange(f *testing.F) {
+	addCorpus(f, "odds_change-*.xml")
+	f.Add([]byte(`<odds_change product="1" event_id="sr:match:1" timestamp="1"><odds><market id="1" specifiers="total=2.5|x"><outcome id="sr:competitor:1,sr:player:2"/></market></odds></odds_change>`))
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		var expected, actual OddsChange
+		err1 := xml.Unmarshal(buf, &expected)
+		err2 := decodeOddsChange(buf, &actual)
+		if err1 != nil || err2 != nil {
+			return
+		}
+		if noNaN(buf) {
+			assert.Equal(t, expected, actual)
+		}
+		for _, m := range actual.Markets {
+			for _, o := range m.Outcomes {
+				assert.True(t, o.PlayerID >= 0)
+				if o.PlayerID != 0 {
+					assert.Equal(t, o.PlayerID, o.ID)
+				}
+				for _, c := range o.Competitors {
+					assert.True(t, c > 0)
+				}
+			}
+		}
+	})
+}
+
+func FuzzBetStop(f *testing.F) {
+	f.Add([]byte(`<bet_stop timestamp="12345" product="3" event_id="sr:match:471123" groups="10_min|180s"/>`))
+	f.Add([]byte(`<bet_stop groups="all" market_status="0" product="1" event_id="sr:match:18001015" timestamp="1234578910111" request_id="1"></bet_stop>`))
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		var expected, actual BetStop
+		err1 := xml.Unmarshal(buf, &expected)
+		err2 := decodeBetStop(buf, &actual)
+		if err1 != nil || err2 != nil {
+			return
+		}
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func FuzzBetSettlement(f *testing.F) {
+	addCorpus(f, "bet_settlement*.xml")
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		var expected, actual BetSettlement
+		err1 := xml.Unmarshal(buf, &expected)
+		err2 := decodeBetSettlement(buf, &actual)
+		if err1 != nil || err2 != nil {
+			return
+		}
+		if noNaN(buf) {
+			assert.Equal(t, expected, actual)
+		}
+	})
+}
+
+// routing keys of all queue message types
+var fuzzRoutingKeys = []string{
+	"hi.-.live.odds_change.1.sr:match.1.-",
+	"hi.pre.-.fixture_change.1.sr:match.1.-",
+	"hi.pre.-.bet_cancel.1.sr:match.1.-",
+	"hi.pre.-.bet_settlement.1.sr:match.1.-",
+	"hi.-.live.bet_stop.1.sr:match.1.-",
+	"hi.pre.-.rollback_bet_settlement.1.sr:match.1.-",
+	"hi.pre.-.rollback_bet_cancel.1.sr:match.1.-",
+	"-.-.-.alive.-.-.-.-",
+	"-.-.-.snapshot_complete.-.-.-.-",
+}
+
+func FuzzQueueMessage(f *testing.F) {
+	files, err := filepath.Glob("testdata/*.xml")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, fn := range files {
+		buf, err := ioutil.ReadFile(fn)
+		if err != nil {
+			f.Fatal(err)
+		}
+		typ := strings.Split(strings.TrimSuffix(filepath.Base(fn), ".xml"), "-")[0]
+		for i, rk := range fuzzRoutingKeys {
+			if strings.Contains(rk, "."+typ+".") {
+				f.Add(uint8(i), buf)
+			}
+		}
+	}
+	f.Add(uint8(7), []byte(`<alive product="1" timestamp="1" subscribed="1"/>`))
+	f.Add(uint8(8), []byte(`<snapshot_complete product="1" request_id="1" timestamp="1"/>`))
+	f.Fuzz(func(t *testing.T, typ uint8, buf []byte) {
+		rk := fuzzRoutingKeys[int(typ)%len(fuzzRoutingKeys)]
+		m, err := NewQueueMessage(rk, buf)
+		if err != nil {
+			return
+		}
+		assert.NotNil(t, m)
+	})
+}
+
+func FuzzAPIMessage(f *testing.F) {
+	types := []MessageType{MessageTypeFixture, MessageTypeMarkets, MessageTypePlayer}
+	for i, pattern := range []string{"fixture-*.xml", "markets-*.xml", "player_profile_*.xml"} {
+		files, err := filepath.Glob(filepath.Join("testdata", pattern))
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, fn := range files {
+			buf, err := ioutil.ReadFile(fn)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(uint8(i), buf)
+		}
+	}
+	f.Add(uint8(0), []byte(`<fixtures_fixture><fixture id="sr:match:1"/></fixtures_fixture>`))
+	f.Fuzz(func(t *testing.T, typ uint8, buf []byte) {
+		_, _ = NewAPIMessage(LangEN, types[int(typ)%len(types)], buf)
+	})
+}
+
+func FuzzURN(f *testing.F) {
+	for _, s := range []string{"sr:match:1", "sr:season:255", "vf:match:-1", "sr:match:18446744073709551615",
+		"wns:draw:36028797018963968", "test:match:1", "sr:match", "123", ""} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		u := URN(s)
+		assert.True(t, u.ID() >= 0)
+		id := u.EventID()
+		if strings.HasPrefix(s, "sr:match:") {
+			assert.Equal(t, u.ID(), id)
+		}
+		if id < 0 {
+			// negative ids are unique for the type, id is in upper bits
+			assert.Equal(t, u.ID(), -id>>8)
+		}
+		_ = u.Producer()
+		_ = u.IsTournament()
+		var p URN
+		p.Parse(s)
+		assert.True(t, p.ID() >= 0)
+	})
+}
+
+func FuzzSpecifiers(f *testing.F) {
+	f.Add("total=1.5|from=1|variant=sr:exact_goals:4+", "to=15")
+	f.Add("player=sr:player:1", "")
+	f.Add("from=1", "||")
+	f.Add("x", "")
+	f.Add("=1", "a==b")
+	f.Fuzz(func(t *testing.T, specifiers, extendedSpecifiers string) {
+		sm, lineID, err := toSpecifiersLineID(specifiers, extendedSpecifiers)
+		if err != nil {
+			assert.Nil(t, sm)
+			assert.Equal(t, 0, lineID)
+			return
+		}
+		for _, s := range strings.Split(specifiers+"|"+extendedSpecifiers, "|") {
+			if s == "" {
+				continue
+			}
+			k := s[:strings.Index(s, "=")]
+			assert.Contains(t, sm, k)
+		}
+		if extendedSpecifiers == "" {
+			assert.Equal(t, lineID, LineID(specifiers))
+		}
+	})
+}
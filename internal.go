@@ -1,8 +1,8 @@
 package uof
 
 type Connection struct {
-	Status    ConnectionStatus `json:"status" bson:"status"`
-	Timestamp int              `json:"timestamp,omitempty" bson:"timestamp,omitempty"`
+	Status    ConnectionStatus `json:"status" bson:"status" uof:"1"`
+	Timestamp int              `json:"timestamp,omitempty" bson:"timestamp,omitempty" uof:"2"`
 }
 
 type ConnectionStatus int8
@@ -15,6 +15,14 @@ type MarketsRsp struct {
 
 type MarketDescriptions []MarketDescription
 
+// MarketVariant identifies variant market descriptions api request. It is set
+// on the markets message which is response to that request, and nil on the
+// all markets response.
+type MarketVariant struct {
+	MarketID int    `json:"marketId,omitempty" bson:"marketId,omitempty" uof:"1"`
+	Variant  string `json:"variant,omitempty" bson:"variant,omitempty" uof:"2"`
+}
+
 func (md MarketDescriptions) Find(id int) *MarketDescription {
 	for _, m := range md {
 		if m.ID == id {
@@ -37,35 +45,35 @@ func (md MarketDescriptions) Groups() map[string][]int {
 }
 
 type MarketDescription struct {
-	ID                     int               `xml:"id,attr" json:"id" bson:"id,omitempty"`
-	VariantID              int               `json:"variantId,omitempty" bson:"variantId,omitempty"`
-	Name                   string            `xml:"name,attr" json:"name,omitempty" bson:"name,omitempty"`
-	Description            string            `xml:"description,attr,omitempty" json:"description,omitempty" bson:"description,omitempty"`
-	IncludesOutcomesOfType string            `xml:"includes_outcomes_of_type,attr,omitempty" json:"includesOutcomesOfType,omitempty" bson:"includesOutcomesOfType,omitempty"`
-	Variant                string            `xml:"variant,attr,omitempty" json:"variant,omitempty" bson:"variant,omitempty"`
-	OutcomeType            OutcomeType       `json:"outcomeType,omitempty" bson:"outcomeType,omitempty"`
-	Groups                 []string          `json:"groups,omitempty" bson:"groups,omitempty"`
-	Outcomes               []MarketOutcome   `xml:"outcomes>outcome,omitempty" json:"outcomes,omitempty" bson:"outcomes,omitempty"`
-	Specifiers             []MarketSpecifier `xml:"specifiers>specifier,omitempty" json:"specifiers,omitempty" bson:"specifiers,omitempty"`
-	Attributes             []MarketAttribute `xml:"attributes>attribute,omitempty" json:"attributes,omitempty" bson:"attributes,omitempty"`
+	ID                     int               `xml:"id,attr" json:"id" bson:"id,omitempty" uof:"1"`
+	VariantID              int               `json:"variantId,omitempty" bson:"variantId,omitempty" uof:"2"`
+	Name                   string            `xml:"name,attr" json:"name,omitempty" bson:"name,omitempty" uof:"3"`
+	Description            string            `xml:"description,attr,omitempty" json:"description,omitempty" bson:"description,omitempty" uof:"4"`
+	IncludesOutcomesOfType string            `xml:"includes_outcomes_of_type,attr,omitempty" json:"includesOutcomesOfType,omitempty" bson:"includesOutcomesOfType,omitempty" uof:"5"`
+	Variant                string            `xml:"variant,attr,omitempty" json:"variant,omitempty" bson:"variant,omitempty" uof:"6"`
+	OutcomeType            OutcomeType       `json:"outcomeType,omitempty" bson:"outcomeType,omitempty" uof:"7"`
+	Groups                 []string          `json:"groups,omitempty" bson:"groups,omitempty" uof:"8"`
+	Outcomes               []MarketOutcome   `xml:"outcomes>outcome,omitempty" json:"outcomes,omitempty" bson:"outcomes,omitempty" uof:"9"`
+	Specifiers             []MarketSpecifier `xml:"specifiers>specifier,omitempty" json:"specifiers,omitempty" bson:"specifiers,omitempty" uof:"10"`
+	Attributes             []MarketAttribute `xml:"attributes>attribute,omitempty" json:"attributes,omitempty" bson:"attributes,omitempty" uof:"11"`
 	//Mappings               []Mapping         `xml:"mappings>mapping,omitempty" json:"mappings,omitempty"`
 }
 
 type MarketOutcome struct {
-	ID          int    `json:"id" bson:"id,omitempty"`
-	Name        string `xml:"name,attr" json:"name,omitempty" bson:"name,omitempty"`
-	Description string `xml:"description,attr,omitempty" json:"description,omitempty" bson:"description,omitempty"`
+	ID          int    `json:"id" bson:"id,omitempty" uof:"1"`
+	Name        string `xml:"name,attr" json:"name,omitempty" bson:"name,omitempty" uof:"2"`
+	Description string `xml:"description,attr,omitempty" json:"description,omitempty" bson:"description,omitempty" uof:"3"`
 }
 
 type MarketSpecifier struct {
-	Type        SpecifierType `json:"type" bson:"type,omitempty"`
-	Name        string        `xml:"name,attr" json:"name,omitempty" bson:"name,omitempty"`
-	Description string        `xml:"description,attr,omitempty" json:"description,omitempty" bson:"description,omitempty"`
+	Type        SpecifierType `json:"type" bson:"type,omitempty" uof:"1"`
+	Name        string        `xml:"name,attr" json:"name,omitempty" bson:"name,omitempty" uof:"2"`
+	Description string        `xml:"description,attr,omitempty" json:"description,omitempty" bson:"description,omitempty" uof:"3"`
 }
 
 type MarketAttribute struct {
-	Name        string `xml:"name,attr" json:"name,omitempty" bson:"name,omitempty"`
-	Description string `xml:"description,attr" json:"description,omitempty" bson:"description,omitempty"`
+	Name        string `xml:"name,attr" json:"name,omitempty" bson:"name,omitempty" uof:"1"`
+	Description string `xml:"description,attr" json:"description,omitempty" bson:"description,omitempty" uof:"2"`
 }
 
 // // currently unused but parsing is valid
@@ -112,8 +120,9 @@ func (t *MarketOutcome) UnmarshalXML(d *xml.Decoder, start xml.StartElement) err
 	if err := d.DecodeElement(&overlay, &start); err != nil {
 		return err
 	}
-	t.ID = toOutcomeID(overlay.ID)
-	return nil
+	var err error
+	t.ID, _, _, err = toOutcomeIDs(overlay.ID)
+	return err
 }
 
 func (t *MarketSpecifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
@@ -12,46 +12,59 @@ import (
 )
 
 type Header struct {
-	Type        MessageType     `json:"type,omitempty" bson:"type"`
-	Scope       MessageScope    `json:"scope,omitempty" bson:"scope,omitempty"`
-	Priority    MessagePriority `json:"priority,omitempty" bson:"priority,omitempty"`
-	Lang        Lang            `json:"lang,omitempty" bson:"lang,omitempty"`
-	SportID     int             `json:"sportId,omitempty" bson:"sportId,omitempty"`
-	EventID     int             `json:"eventId,omitempty" bson:"eventId,omitempty"`
-	EventURN    URN             `json:"eventURN,omitempty" bson:"eventURN,omitempty"`
-	ReceivedAt  int             `json:"receivedAt,omitempty" bson:"receivedAt,omitempty"`
-	RequestedAt int             `json:"requestedAt,omitempty" bson:"requestedAt,omitempty"`
-	Producer    Producer        `json:"producer,omitempty" bson:"producer,omitempty"`
-	Timestamp   int             `json:"timestamp,omitempty" bson:"timestamp,omitempty"`
+	Type        MessageType     `json:"type,omitempty" bson:"type" uof:"1"`
+	Scope       MessageScope    `json:"scope,omitempty" bson:"scope,omitempty" uof:"2"`
+	Priority    MessagePriority `json:"priority,omitempty" bson:"priority,omitempty" uof:"3"`
+	Lang        Lang            `json:"lang,omitempty" bson:"lang,omitempty" uof:"4"`
+	SportID     int             `json:"sportId,omitempty" bson:"sportId,omitempty" uof:"5"`
+	EventID     int             `json:"eventId,omitempty" bson:"eventId,omitempty" uof:"6"` // unique within producer and urn type, identify event by EventURN
+	EventURN    URN             `json:"eventURN,omitempty" bson:"eventURN,omitempty" uof:"7"`
+	ReceivedAt  int             `json:"receivedAt,omitempty" bson:"receivedAt,omitempty" uof:"8"`
+	RequestedAt int             `json:"requestedAt,omitempty" bson:"requestedAt,omitempty" uof:"9"`
+	Producer    Producer        `json:"producer,omitempty" bson:"producer,omitempty" uof:"10"`
+	Timestamp   int             `json:"timestamp,omitempty" bson:"timestamp,omitempty" uof:"11"`
+	// Message is generated by the SDK, not received from the feed.
+	Generated bool `json:"generated,omitempty" bson:"generated,omitempty" uof:"12"`
 }
 
 type Body struct {
 	// queue message types
 	// Ref: https://docs.betradar.com/display/BD/UOF+-+Messages
-	Alive                 *Alive                 `json:"alive,omitempty" bson:"alive,omitempty"`
-	BetCancel             *BetCancel             `json:"betCancel,omitempty" bson:"betCancel,omitempty"`
-	RollbackBetSettlement *RollbackBetSettlement `json:"rollbackBetSettlement,omitempty" bson:"rollbackBetSettlement,omitempty"`
-	RollbackBetCancel     *RollbackBetCancel     `json:"rollbackBetCancel,omitempty" bson:"rollbackBetCancel,omitempty"`
-	SnapshotComplete      *SnapshotComplete      `json:"snapshotComplete,omitempty" bson:"snapshotComplete,omitempty"`
-	OddsChange            *OddsChange            `json:"oddsChange,omitempty" bson:"oddsChange,omitempty"`
-	FixtureChange         *FixtureChange         `json:"fixtureChange,omitempty" bson:"fixtureChange,omitempty"`
-	BetSettlement         *BetSettlement         `json:"betSettlement,omitempty" bson:"betSettlement,omitempty"`
-	BetStop               *BetStop               `json:"betStop,omitempty" bson:"betStop,omitempty"`
+	Alive                 *Alive                 `json:"alive,omitempty" bson:"alive,omitempty" uof:"1"`
+	BetCancel             *BetCancel             `json:"betCancel,omitempty" bson:"betCancel,omitempty" uof:"2"`
+	RollbackBetSettlement *RollbackBetSettlement `json:"rollbackBetSettlement,omitempty" bson:"rollbackBetSettlement,omitempty" uof:"3"`
+	RollbackBetCancel     *RollbackBetCancel     `json:"rollbackBetCancel,omitempty" bson:"rollbackBetCancel,omitempty" uof:"4"`
+	SnapshotComplete      *SnapshotComplete      `json:"snapshotComplete,omitempty" bson:"snapshotComplete,omitempty" uof:"5"`
+	OddsChange            *OddsChange            `json:"oddsChange,omitempty" bson:"oddsChange,omitempty" uof:"6"`
+	FixtureChange         *FixtureChange         `json:"fixtureChange,omitempty" bson:"fixtureChange,omitempty" uof:"7"`
+	BetSettlement         *BetSettlement         `json:"betSettlement,omitempty" bson:"betSettlement,omitempty" uof:"8"`
+	BetStop               *BetStop               `json:"betStop,omitempty" bson:"betStop,omitempty" uof:"9"`
 	// api response message types
-	Fixture    *Fixture           `json:"fixture,omitempty" bson:"fixture,omitempty"`
-	Markets    MarketDescriptions `json:"markets,omitempty" bson:"markets,omitempty"`
-	Player     *Player            `json:"player,omitempty" bson:"player,omitempty"`
-	Competitor *CompetitorPlayer  `json:"competitor,omitempty" bson:"competitor,omitempty"`
-	Tournament *FixtureTournament `json:"tournament,omitempty" bson:"tournament,omitempty"`
+	Fixture    *Fixture           `json:"fixture,omitempty" bson:"fixture,omitempty" uof:"10"`
+	Markets    MarketDescriptions `json:"markets,omitempty" bson:"markets,omitempty" uof:"11"`
+	Player     *Player            `json:"player,omitempty" bson:"player,omitempty" uof:"12"`
+	Competitor *CompetitorPlayer  `json:"competitor,omitempty" bson:"competitor,omitempty" uof:"13"`
+	Tournament *FixtureTournament `json:"tournament,omitempty" bson:"tournament,omitempty" uof:"14"`
+	// request of the variant markets response, nil for all markets
+	MarketVariant *MarketVariant `json:"marketVariant,omitempty" bson:"marketVariant,omitempty" uof:"15"`
+	// cashout probabilities api response
+	CashoutProbabilities *CashoutProbabilities `json:"cashoutProbabilities,omitempty" bson:"cashoutProbabilities,omitempty" uof:"16"`
 	// sdk status message types
-	Connection *Connection     `json:"connection,omitempty" bson:"connection,omitempty"`
-	Producers  ProducersChange `json:"producers,omitempty" bson:"producers,omitempty"`
+	Connection *Connection     `json:"connection,omitempty" bson:"connection,omitempty" uof:"17"`
+	Producers  ProducersChange `json:"producers,omitempty" bson:"producers,omitempty" uof:"18"`
 }
 
 type Message struct {
 	Header `json:",inline" bson:",inline"`
 	Raw    []byte `json:"-" bson:"-"`
 	Body   `json:",inline" bson:",inline"`
+	lazy   *lazyBody
+}
+
+// lazyBody holds state of the lazy message unpacking
+type lazyBody struct {
+	once sync.Once
+	err  error
 }
 
 var uniqTimestamp func() int // ensures unique timestamp value
@@ -82,17 +95,81 @@ func timeToTimestamp(t time.Time) int {
 }
 
 func NewQueueMessage(routingKey string, body []byte) (*Message, error) {
+	h, err := ParseRoutingKey(routingKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueueMessageWithHeader(h, body)
+}
+
+// NewQueueMessageWithHeader creates message from the header, previously parsed
+// from the routing key, and the message body.
+func NewQueueMessageWithHeader(h Header, body []byte) (*Message, error) {
 	r := &Message{
-		Header: Header{ReceivedAt: uniqTimestamp()},
+		Header: h,
 		Raw:    body,
 	}
-	if err := r.parseRoutingKey(routingKey); err != nil {
+	return r, r.unpack()
+}
+
+// NewLazyQueueMessage creates message with header parsed from the routing key.
+// Message body is not unpacked until the first call to Unpack. Producer and
+// Timestamp header fields are read from the root element attributes, so the
+// header is complete without unpacking. System messages are always unpacked
+// immediately.
+//
+// Markets, Player and BetStop pipe stages, which sdk.Run always installs,
+// unpack every odds_change and bet_stop, and pipe consumers get unpacked
+// messages. Lazy unpacking pays off in the custom pipe, see sdk.LazyUnpack.
+func NewLazyQueueMessage(routingKey string, body []byte) (*Message, error) {
+	h, err := ParseRoutingKey(routingKey)
+	if err != nil {
 		return nil, err
 	}
-	return r, r.unpack()
+	return NewLazyQueueMessageWithHeader(h, body)
+}
+
+// NewLazyQueueMessageWithHeader same as NewLazyQueueMessage but with header
+// previously parsed from the routing key.
+func NewLazyQueueMessageWithHeader(h Header, body []byte) (*Message, error) {
+	if h.Type.Kind() == MessageKindSystem {
+		return NewQueueMessageWithHeader(h, body)
+	}
+	if err := decodeHeader(body, &h); err != nil {
+		return nil, Notice("message.unpack", fmt.Errorf("%w xml: %s", err, body))
+	}
+	return &Message{
+		Header: h,
+		Raw:    body,
+		lazy:   &lazyBody{},
+	}, nil
+}
+
+// Unpack unpacks body of the lazy message. Body is unpacked only once,
+// subsequent calls return result of the first one. For messages which are not
+// lazy this is no-op. On error message is left without body, error is
+// uof.Notice same as the one of the NewQueueMessage for malformed message.
+func (m *Message) Unpack() error {
+	if m.lazy == nil {
+		return nil
+	}
+	m.lazy.once.Do(func() {
+		m.lazy.err = m.unpack()
+	})
+	return m.lazy.err
+}
+
+// ParseRoutingKey creates message header from the queue routing key. Allows
+// decisions about the message without unpacking the message body.
+func ParseRoutingKey(routingKey string) (Header, error) {
+	h := Header{ReceivedAt: uniqTimestamp()}
+	if err := h.parseRoutingKey(routingKey); err != nil {
+		return h, err
+	}
+	return h, nil
 }
 
-func (m *Message) parseRoutingKey(routingKey string) error {
+func (h *Header) parseRoutingKey(routingKey string) error {
 	p := strings.Split(routingKey, ".")
 	if len(p) < 7 {
 		return fmt.Errorf("unknown routing key: %s", routingKey)
@@ -112,27 +189,27 @@ func (m *Message) parseRoutingKey(routingKey string) error {
 	eventID := part(6)
 	//nodeID := part(7)  // currently unused
 
-	m.Priority.Parse(priority)
-	m.Type.Parse(messageType)
-	m.Scope.Parse(prematchInterest, liveInterest)
+	h.Priority.Parse(priority)
+	h.Type.Parse(messageType)
+	h.Scope.Parse(prematchInterest, liveInterest)
 
-	if m.Type == MessageTypeUnknown {
+	if h.Type == MessageTypeUnknown {
 		return fmt.Errorf("unknown message type for routing key: %s", routingKey)
 	}
 
 	// if eventID != "" {
-	// 	m.EventID, _ = strconv.Atoi(eventID)
+	// 	h.EventID, _ = strconv.Atoi(eventID)
 	// }
 	if sportID != "" {
-		m.SportID, _ = strconv.Atoi(sportID)
+		h.SportID, _ = strconv.Atoi(sportID)
 	}
 	if eventURN != "" && eventID != "" {
-		m.EventURN = URN(eventURN + ":" + eventID)
-		id := m.EventURN.EventID()
+		h.EventURN = URN(eventURN + ":" + eventID)
+		id := h.EventURN.EventID()
 		if id == 0 {
-			return fmt.Errorf("unknown eventID for URN: %s", m.EventURN)
+			return fmt.Errorf("unknown eventID for URN: %s", h.EventURN)
 		}
-		m.EventID = id
+		h.EventID = id
 	}
 
 	return nil
@@ -162,12 +239,12 @@ func (m *Message) unpack() error {
 		m.Producer = m.BetCancel.Producer
 	case MessageTypeBetSettlement:
 		m.BetSettlement = &BetSettlement{}
-		unmarshal(m.BetSettlement)
+		err = decodeBetSettlement(m.Raw, m.BetSettlement)
 		m.Timestamp = m.BetSettlement.Timestamp
 		m.Producer = m.BetSettlement.Producer
 	case MessageTypeBetStop:
 		m.BetStop = &BetStop{}
-		unmarshal(m.BetStop)
+		err = decodeBetStop(m.Raw, m.BetStop)
 		m.Timestamp = m.BetStop.Timestamp
 		m.Producer = m.BetStop.Producer
 	case MessageTypeFixtureChange:
@@ -177,7 +254,7 @@ func (m *Message) unpack() error {
 		m.Producer = m.FixtureChange.Producer
 	case MessageTypeOddsChange:
 		m.OddsChange = &OddsChange{}
-		unmarshal(m.OddsChange)
+		err = decodeOddsChange(m.Raw, m.OddsChange)
 		m.Timestamp = m.OddsChange.Timestamp
 		m.Producer = m.OddsChange.Producer
 	case MessageTypeRollbackBetSettlement:
@@ -207,11 +284,17 @@ func (m *Message) unpack() error {
 		pp := PlayerProfile{}
 		unmarshal(&pp)
 		m.Player = &pp.Player
+	case MessageTypeCashoutProbabilities:
+		m.CashoutProbabilities = &CashoutProbabilities{}
+		unmarshal(m.CashoutProbabilities)
+		m.Timestamp = m.CashoutProbabilities.Timestamp
+		m.Producer = m.CashoutProbabilities.Producer
 	default:
 		err := fmt.Errorf("unknown message type %d", m.Type)
 		return Notice("message.unpack", err)
 	}
 	if err != nil {
+		m.Body = Body{}
 		return Notice("message.unpack", fmt.Errorf("%w xml: %s", err, m.Raw))
 	}
 	return nil
@@ -246,6 +329,14 @@ func NewMarketsMessage(lang Lang, ms MarketDescriptions, requestedAt int, raw []
 	return m
 }
 
+// NewMarketVariantMessage creates markets message from the variant market
+// descriptions response.
+func NewMarketVariantMessage(lang Lang, marketID int, variant string, ms MarketDescriptions, requestedAt int, raw []byte) *Message {
+	m := NewMarketsMessage(lang, ms, requestedAt, raw)
+	m.MarketVariant = &MarketVariant{MarketID: marketID, Variant: variant}
+	return m
+}
+
 func NewPlayerMessage(lang Lang, player *Player, requestedAt int, raw []byte) *Message {
 	return &Message{
 		Header: Header{
@@ -271,6 +362,44 @@ func NewCompetitorMessage(lang Lang, competitor *CompetitorPlayer, requestedAt i
 	}
 }
 
+// NewBetStopMessage creates bet stop message generated by the SDK. Empty
+// marketIDs means all markets of the event.
+func NewBetStopMessage(producer Producer, eventURN URN, marketIDs []int, status MarketStatus, origin BetStopOrigin) *Message {
+	return newBetStopMessage(MessageTypeBetStop, producer, eventURN, marketIDs, status, origin)
+}
+
+// NewBetStopLiftMessage creates message which lifts the SDK generated bet stop
+// of the origin. Empty marketIDs means all markets of the event.
+func NewBetStopLiftMessage(producer Producer, eventURN URN, marketIDs []int, origin BetStopOrigin) *Message {
+	return newBetStopMessage(MessageTypeBetStopLift, producer, eventURN, marketIDs, MarketStatusActive, origin)
+}
+
+func newBetStopMessage(typ MessageType, producer Producer, eventURN URN, marketIDs []int, status MarketStatus, origin BetStopOrigin) *Message {
+	ts := uniqTimestamp()
+	return &Message{
+		Header: Header{
+			Type:       typ,
+			EventURN:   eventURN,
+			EventID:    eventURN.EventID(),
+			Producer:   producer,
+			ReceivedAt: ts,
+			Timestamp:  ts,
+			Generated:  true,
+		},
+		Body: Body{
+			BetStop: &BetStop{
+				EventID:   eventURN.EventID(),
+				EventURN:  eventURN,
+				Timestamp: ts,
+				MarketIDs: marketIDs,
+				Producer:  producer,
+				Status:    status,
+				Origin:    origin,
+			},
+		},
+	}
+}
+
 func NewConnnectionMessage(status ConnectionStatus) *Message {
 	ts := uniqTimestamp()
 	return &Message{
@@ -331,6 +460,22 @@ func NewTournamentMessage(lang Lang, x FixtureTournament, requestedAt int, raw [
 	}
 }
 
+func NewCashoutProbabilitiesMessage(x *CashoutProbabilities, requestedAt int, raw []byte) *Message {
+	return &Message{
+		Header: Header{
+			Type:        MessageTypeCashoutProbabilities,
+			EventURN:    x.EventURN,
+			EventID:     x.EventID,
+			Producer:    x.Producer,
+			Timestamp:   x.Timestamp,
+			ReceivedAt:  uniqTimestamp(),
+			RequestedAt: requestedAt,
+		},
+		Raw:  raw,
+		Body: Body{CashoutProbabilities: x},
+	}
+}
+
 func (m *Message) NewFixtureMessage(lang Lang, f Fixture) *Message {
 	c := &Message{
 		Header: m.Header,
@@ -1,6 +1,7 @@
 package uof
 
 import (
+	"io/ioutil"
 	"strings"
 	"testing"
 
@@ -435,3 +436,86 @@ func getProducerFromMsg(msg *Message) Producer {
 	}
 	return ProducerUnknown
 }
+
+func TestLazyMessage(t *testing.T) {
+	buf, err := ioutil.ReadFile("./testdata/odds_change-0.xml")
+	assert.NoError(t, err)
+	key := "hi.-.live.odds_change.4.sr:match.11784628.-"
+
+	m, err := NewLazyQueueMessage(key, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, MessageTypeOddsChange, m.Type)
+	assert.Equal(t, 11784628, m.EventID)
+	assert.Nil(t, m.OddsChange)
+	// producer and timestamp are read from the root element
+	assert.Equal(t, Producer(2), m.Producer)
+	assert.Equal(t, 1234, m.Timestamp)
+
+	// header with raw body is marshaled without unpacking
+	assert.True(t, strings.HasSuffix(string(m.Marshal()), string(buf)))
+	assert.Nil(t, m.OddsChange)
+
+	assert.NoError(t, m.Unpack())
+	assert.NoError(t, m.Unpack())
+	em, err := NewQueueMessage(key, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, em.Body, m.Body)
+	assert.Equal(t, em.Producer, m.Producer)
+	assert.Equal(t, em.Timestamp, m.Timestamp)
+
+	// system messages are unpacked immediately
+	m, err = NewLazyQueueMessage("-.-.-.alive.-.-.-.-", []byte(`<alive timestamp="1234" product="3" subscribed="1"/>`))
+	assert.NoError(t, err)
+	assert.NotNil(t, m.Alive)
+
+	// unpack error is returned on each call
+	m, err = NewLazyQueueMessage("hi.pre.-.bet_cancel.1.sr:match.1234.-", []byte(`<bet_cancel product="3" timestamp="1234"><market`))
+	assert.NoError(t, err)
+	assert.Equal(t, ProducerPrematch, m.Producer)
+	assert.Error(t, m.Unpack())
+	assert.Error(t, m.Unpack())
+	assert.Nil(t, m.BetCancel)
+
+	// malformed root element is error on create, same as for not lazy message
+	_, err = NewLazyQueueMessage("hi.pre.-.bet_cancel.1.sr:match.1234.-", []byte("<bet_cancel"))
+	assert.Error(t, err)
+	_, err = NewQueueMessage("hi.pre.-.bet_cancel.1.sr:match.1234.-", []byte("<bet_cancel"))
+	assert.Error(t, err)
+
+	// not lazy message
+	assert.NoError(t, em.Unpack())
+}
+
+var benchmarkMessages = []struct {
+	file string
+	key  string
+}{
+	{"odds_change-0.xml", "hi.-.live.odds_change.4.sr:match.11784628.-"},
+	{"bet_settlement.xml", "lo.pre.-.bet_settlement.1.sr:match.16807109.-"},
+	{"bet_cancel.xml", "hi.pre.-.bet_cancel.1.sr:match.16807109.-"},
+}
+
+func benchmarkQueueMessage(b *testing.B, create func(string, []byte) (*Message, error)) {
+	for _, bm := range benchmarkMessages {
+		buf, err := ioutil.ReadFile("./testdata/" + bm.file)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(bm.file, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := create(bm.key, buf); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewQueueMessage(b *testing.B) {
+	benchmarkQueueMessage(b, NewQueueMessage)
+}
+
+func BenchmarkNewLazyQueueMessage(b *testing.B) {
+	benchmarkQueueMessage(b, NewLazyQueueMessage)
+}
@@ -0,0 +1,79 @@
+package odds
+
+import (
+	"github.com/minus5/go-uof-sdk"
+)
+
+// Overround is the sum of the implied probabilities (1/odds) of the market
+// outcomes. Only active outcomes with odds are counted, market needs at least
+// two of them.
+func Overround(m uof.Market) (float64, bool) {
+	var sum float64
+	var n int
+	for _, o := range m.Outcomes {
+		if !priced(o) {
+			continue
+		}
+		sum += 1 / *o.Odds
+		n++
+	}
+	if n < 2 {
+		return 0, false
+	}
+	return sum, true
+}
+
+// Margin is the bookmaker margin of the market, overround above 1. For
+// example 0.047 for the two way market at 1.91 both.
+func Margin(m uof.Market) (float64, bool) {
+	o, ok := Overround(m)
+	if !ok {
+		return 0, false
+	}
+	return o - 1, true
+}
+
+// FairOdds returns odds without margin, from the outcome probabilities.
+func FairOdds(o uof.Outcome) (float64, bool) {
+	if o.Probabilities == nil || *o.Probabilities <= 0 || *o.Probabilities > 1 {
+		return 0, false
+	}
+	return 1 / *o.Probabilities, true
+}
+
+func priced(o uof.Outcome) bool {
+	return o.Odds != nil && *o.Odds > 1 && (o.Active == nil || *o.Active)
+}
+
+// MarginBounds are limits of the acceptable market margin.
+type MarginBounds struct {
+	Min float64
+	Max float64
+}
+
+// MarketMargin is the margin of one market line.
+type MarketMargin struct {
+	MarketID int
+	LineID   int
+	Margin   float64
+}
+
+// Outside returns market lines of the odds change with margin outside the
+// bounds. Markets which are not active or without margin are skipped.
+func (b MarginBounds) Outside(oc *uof.OddsChange) []MarketMargin {
+	if oc == nil {
+		return nil
+	}
+	var mm []MarketMargin
+	for _, m := range oc.Markets {
+		if m.Status != uof.MarketStatusActive {
+			continue
+		}
+		v, ok := Margin(m)
+		if !ok || (v >= b.Min && v <= b.Max) {
+			continue
+		}
+		mm = append(mm, MarketMargin{MarketID: m.ID, LineID: m.LineID, Margin: v})
+	}
+	return mm
+}
@@ -0,0 +1,47 @@
+package odds
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMargin(t *testing.T) {
+	var oc uof.OddsChange
+	require.NoError(t, xml.Unmarshal([]byte(`<odds_change product="1" event_id="sr:match:1" timestamp="1"><odds>
+		<market id="1"><outcome id="1" odds="2" probabilities="0.5"/><outcome id="2" odds="2" probabilities="0.5"/></market>
+		<market id="18" specifiers="total=2.5"><outcome id="12" odds="1.91"/><outcome id="13" odds="1.91"/></market>
+		<market id="18" specifiers="total=3.5"><outcome id="12" odds="1.5"/><outcome id="13" odds="2.2"/></market>
+		<market id="18" specifiers="total=4.5" status="-1"><outcome id="12" odds="1.1"/><outcome id="13" odds="1.1"/></market>
+		<market id="16" specifiers="hcp=1"><outcome id="1714" odds="1.8"/><outcome id="1715" odds="1.2" active="0"/></market>
+	</odds></odds_change>`), &oc))
+
+	m, ok := Margin(oc.Markets[0])
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, m)
+	m, ok = Margin(oc.Markets[1])
+	assert.True(t, ok)
+	assert.InDelta(t, 0.047, m, 0.001)
+	o, ok := Overround(oc.Markets[2])
+	assert.True(t, ok)
+	assert.InDelta(t, 1.121, o, 0.001)
+	// one active outcome
+	_, ok = Margin(oc.Markets[4])
+	assert.False(t, ok)
+
+	f, ok := FairOdds(oc.Markets[0].Outcomes[0])
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, f)
+	_, ok = FairOdds(oc.Markets[1].Outcomes[0])
+	assert.False(t, ok)
+
+	out := MarginBounds{Min: 0.02, Max: 0.1}.Outside(&oc)
+	assert.Len(t, out, 2)
+	assert.Equal(t, 1, out[0].MarketID)
+	assert.Equal(t, 18, out[1].MarketID)
+	assert.Equal(t, uof.LineID("total=3.5"), out[1].LineID)
+	assert.Nil(t, MarginBounds{}.Outside(nil))
+}
@@ -0,0 +1,230 @@
+// Package odds converts decimal odds, as sent in the feed, to the other odds
+// formats and calculates market margins.
+package odds
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Format of the odds presentation.
+type Format int8
+
+const (
+	FormatDecimal    Format = iota // 2.50
+	FormatFractional               // 6/4
+	FormatAmerican                 // +150, -200
+	FormatHongKong                 // 1.50
+	FormatIndonesian               // 1.50, -2.00
+	FormatMalay                    // 0.67, -0.67
+)
+
+var formatNames = []string{"decimal", "fractional", "american", "hongkong", "indonesian", "malay"}
+
+func (f Format) String() string {
+	if f < 0 || int(f) >= len(formatNames) {
+		return "?"
+	}
+	return formatNames[f]
+}
+
+func (f *Format) Parse(name string) error {
+	for i, n := range formatNames {
+		if n == name {
+			*f = Format(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown odds format %q", name)
+}
+
+// Format returns decimal odds in the format, rounded as usually shown:
+// fractional to the ladder, american to integer and others to two decimals.
+func (f Format) Format(d float64) (string, error) {
+	if err := check(d); err != nil {
+		return "", err
+	}
+	switch f {
+	case FormatDecimal:
+		return strconv.FormatFloat(round2(d), 'f', 2, 64), nil
+	case FormatFractional:
+		n, m := Fractional(d)
+		return fmt.Sprintf("%d/%d", n, m), nil
+	case FormatAmerican:
+		a := American(d)
+		if a > 0 {
+			return fmt.Sprintf("+%d", a), nil
+		}
+		return strconv.Itoa(a), nil
+	case FormatHongKong:
+		return strconv.FormatFloat(HongKong(d), 'f', 2, 64), nil
+	case FormatIndonesian:
+		return strconv.FormatFloat(Indonesian(d), 'f', 2, 64), nil
+	case FormatMalay:
+		return strconv.FormatFloat(Malay(d), 'f', 2, 64), nil
+	}
+	return "", fmt.Errorf("unknown odds format %d", f)
+}
+
+// Decimal parses odds written in the format to decimal odds.
+func (f Format) Decimal(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	var d float64
+	switch f {
+	case FormatFractional:
+		p := strings.Split(s, "/")
+		if len(p) != 2 {
+			return 0, fmt.Errorf("malformed fractional odds %q", s)
+		}
+		n, err1 := strconv.Atoi(p[0])
+		m, err2 := strconv.Atoi(p[1])
+		if err1 != nil || err2 != nil || n <= 0 || m <= 0 {
+			return 0, fmt.Errorf("malformed fractional odds %q", s)
+		}
+		d = 1 + float64(n)/float64(m)
+	case FormatAmerican:
+		a, err := strconv.Atoi(strings.TrimPrefix(s, "+"))
+		if err != nil {
+			return 0, fmt.Errorf("malformed american odds %q", s)
+		}
+		d = FromAmerican(a)
+	case FormatDecimal, FormatHongKong, FormatIndonesian, FormatMalay:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("malformed %s odds %q", f, s)
+		}
+		switch f {
+		case FormatDecimal:
+			d = v
+		case FormatHongKong:
+			d = FromHongKong(v)
+		case FormatIndonesian:
+			d = FromIndonesian(v)
+		case FormatMalay:
+			d = FromMalay(v)
+		}
+	default:
+		return 0, fmt.Errorf("unknown odds format %d", f)
+	}
+	if err := check(d); err != nil {
+		return 0, err
+	}
+	return d, nil
+}
+
+func check(d float64) error {
+	if math.IsNaN(d) || math.IsInf(d, 0) || d <= 1 {
+		return fmt.Errorf("invalid decimal odds %v", d)
+	}
+	return nil
+}
+
+func round2(v float64) float64 {
+	r := math.Round(v*100) / 100
+	if r == 0 {
+		return 0 // no negative zero
+	}
+	return r
+}
+
+// fractional odds ladder, as used by the bookmakers
+var ladder = [][2]int{
+	{1, 50}, {1, 40}, {1, 33}, {1, 25}, {1, 20}, {1, 16}, {1, 14}, {1, 12}, {1, 10}, {1, 9}, {1, 8},
+	{2, 15}, {1, 7}, {1, 6}, {2, 11}, {1, 5}, {2, 9}, {1, 4}, {2, 7}, {3, 10}, {1, 3}, {4, 11},
+	{2, 5}, {4, 9}, {1, 2}, {8, 15}, {4, 7}, {8, 13}, {4, 6}, {8, 11}, {4, 5}, {5, 6}, {10, 11},
+	{1, 1}, {21, 20}, {11, 10}, {6, 5}, {5, 4}, {11, 8}, {7, 5}, {6, 4}, {8, 5}, {13, 8}, {7, 4},
+	{9, 5}, {15, 8}, {2, 1}, {85, 40}, {11, 5}, {9, 4}, {12, 5}, {5, 2}, {13, 5}, {11, 4}, {3, 1},
+	{100, 30}, {7, 2}, {4, 1}, {9, 2}, {5, 1}, {11, 2}, {6, 1}, {13, 2}, {7, 1}, {15, 2}, {8, 1},
+	{17, 2}, {9, 1}, {10, 1}, {11, 1}, {12, 1}, {14, 1}, {16, 1}, {18, 1}, {20, 1}, {22, 1},
+	{25, 1}, {28, 1}, {33, 1}, {40, 1}, {50, 1}, {66, 1}, {80, 1}, {100, 1}, {125, 1}, {150, 1},
+	{200, 1}, {250, 1}, {300, 1}, {500, 1}, {1000, 1},
+}
+
+// Fractional returns numerator and denominator of the ladder fraction nearest
+// to the decimal odds. Above the ladder it is rounded to n/1.
+func Fractional(d float64) (int, int) {
+	v := d - 1
+	last := ladder[len(ladder)-1]
+	if v > float64(last[0]) {
+		return int(math.Round(v)), 1
+	}
+	best := ladder[0]
+	diff := math.Inf(1)
+	for _, f := range ladder {
+		if x := math.Abs(v - float64(f[0])/float64(f[1])); x < diff {
+			best, diff = f, x
+		}
+	}
+	return best[0], best[1]
+}
+
+// American returns american (moneyline) odds. Positive is the profit on 100
+// stake, negative the stake needed for 100 profit.
+func American(d float64) int {
+	if d >= 2 {
+		return int(math.Round((d - 1) * 100))
+	}
+	return -int(math.Round(100 / (d - 1)))
+}
+
+// FromAmerican returns decimal odds from the american odds.
+func FromAmerican(a int) float64 {
+	if a >= 100 {
+		return 1 + float64(a)/100
+	}
+	if a <= -100 {
+		return 1 + 100/float64(-a)
+	}
+	return 0
+}
+
+// HongKong returns hong kong odds, profit on the unit stake.
+func HongKong(d float64) float64 {
+	return round2(d - 1)
+}
+
+// FromHongKong returns decimal odds from the hong kong odds.
+func FromHongKong(h float64) float64 {
+	return h + 1
+}
+
+// Indonesian returns indonesian odds, same as american divided by 100.
+func Indonesian(d float64) float64 {
+	if d >= 2 {
+		return round2(d - 1)
+	}
+	return round2(-1 / (d - 1))
+}
+
+// FromIndonesian returns decimal odds from the indonesian odds.
+func FromIndonesian(i float64) float64 {
+	if i >= 1 {
+		return i + 1
+	}
+	if i <= -1 {
+		return 1 - 1/i
+	}
+	return 0
+}
+
+// Malay returns malay odds. Positive for the decimal odds up to 2, profit on
+// the unit stake; negative above, stake needed for the unit profit.
+func Malay(d float64) float64 {
+	if d <= 2 {
+		return round2(d - 1)
+	}
+	return round2(-1 / (d - 1))
+}
+
+// FromMalay returns decimal odds from the malay odds.
+func FromMalay(m float64) float64 {
+	if m > 0 && m <= 1 {
+		return m + 1
+	}
+	if m < 0 && m >= -1 {
+		return 1 - 1/m
+	}
+	return 0
+}
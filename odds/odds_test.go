@@ -0,0 +1,122 @@
+package odds
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormats(t *testing.T) {
+	data := []struct {
+		d          float64
+		decimal    string
+		fractional string
+		american   string
+		hongKong   string
+		indonesian string
+		malay      string
+	}{
+		{1.5, "1.50", "1/2", "-200", "0.50", "-2.00", "0.50"},
+		{1.91, "1.91", "10/11", "-110", "0.91", "-1.10", "0.91"},
+		{2, "2.00", "1/1", "+100", "1.00", "1.00", "1.00"},
+		{2.5, "2.50", "6/4", "+150", "1.50", "1.50", "-0.67"},
+		{3.25, "3.25", "9/4", "+225", "2.25", "2.25", "-0.44"},
+		{4.33, "4.33", "100/30", "+333", "3.33", "3.33", "-0.30"},
+		{11, "11.00", "10/1", "+1000", "10.00", "10.00", "-0.10"},
+		{1.01, "1.01", "1/50", "-10000", "0.01", "-100.00", "0.01"},
+		{1501, "1501.00", "1500/1", "+150000", "1500.00", "1500.00", "0.00"},
+		{2.62, "2.62", "13/8", "+162", "1.62", "1.62", "-0.62"},
+	}
+	for _, d := range data {
+		for f, expected := range map[Format]string{
+			FormatDecimal:    d.decimal,
+			FormatFractional: d.fractional,
+			FormatAmerican:   d.american,
+			FormatHongKong:   d.hongKong,
+			FormatIndonesian: d.indonesian,
+			FormatMalay:      d.malay,
+		} {
+			s, err := f.Format(d.d)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, s, "%v %s", d.d, f)
+		}
+	}
+
+	for _, d := range []float64{1, 0.5, -2, math.NaN(), math.Inf(1)} {
+		_, err := FormatDecimal.Format(d)
+		assert.Error(t, err)
+	}
+	_, err := Format(42).Format(2)
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	data := []struct {
+		f Format
+		s string
+		d float64
+	}{
+		{FormatDecimal, "2.5", 2.5},
+		{FormatFractional, "6/4", 2.5},
+		{FormatFractional, "10/11", 1 + 10.0/11},
+		{FormatAmerican, "+150", 2.5},
+		{FormatAmerican, "150", 2.5},
+		{FormatAmerican, "-200", 1.5},
+		{FormatHongKong, "1.5", 2.5},
+		{FormatIndonesian, "1.5", 2.5},
+		{FormatIndonesian, "-2", 1.5},
+		{FormatMalay, "0.5", 1.5},
+		{FormatMalay, "-0.5", 3},
+	}
+	for _, d := range data {
+		v, err := d.f.Decimal(d.s)
+		assert.NoError(t, err, d.s)
+		assert.InDelta(t, d.d, v, 1e-9, "%s %s", d.f, d.s)
+	}
+
+	for _, d := range []struct {
+		f Format
+		s string
+	}{
+		{FormatDecimal, "x"},
+		{FormatDecimal, "1"},
+		{FormatFractional, "6"},
+		{FormatFractional, "6/0"},
+		{FormatFractional, "-1/2"},
+		{FormatAmerican, "+50"},
+		{FormatAmerican, "x"},
+		{FormatHongKong, "0"},
+		{FormatIndonesian, "0.5"},
+		{FormatMalay, "2"},
+		{Format(42), "2"},
+	} {
+		_, err := d.f.Decimal(d.s)
+		assert.Error(t, err, "%s %s", d.f, d.s)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, f := range []Format{FormatDecimal, FormatAmerican, FormatHongKong, FormatIndonesian, FormatMalay} {
+		for d := 1.05; d < 20; d += 0.05 {
+			s, err := f.Format(d)
+			assert.NoError(t, err)
+			v, err := f.Decimal(s)
+			assert.NoError(t, err, "%s %s", f, s)
+			// format is rounded to two decimals
+			assert.InDelta(t, d, v, 0.03*d*d, "%s %v %s", f, d, s)
+		}
+	}
+}
+
+func TestFormatNames(t *testing.T) {
+	for i, n := range formatNames {
+		var f Format
+		assert.NoError(t, f.Parse(n))
+		assert.Equal(t, Format(i), f)
+		assert.Equal(t, n, f.String())
+	}
+	var f Format
+	assert.Error(t, f.Parse("x"))
+	assert.Equal(t, "?", Format(42).String())
+}
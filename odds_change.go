@@ -2,6 +2,7 @@ package uof
 
 import (
 	"encoding/xml"
+	"fmt"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,30 +14,33 @@ import (
 // reported.
 // Reference: https://docs.betradar.com/display/BD/UOF+-+Odds+change
 type OddsChange struct {
-	EventID  int `json:"eventId,omitempty" bson:"eventId,omitempty"`
-	EventURN URN `xml:"event_id,attr" json:"eventURN,omitempty" bson:"eventURN,omitempty"`
+	EventID  int `json:"eventId,omitempty" bson:"eventId,omitempty" uof:"1"`
+	EventURN URN `xml:"event_id,attr" json:"eventURN,omitempty" bson:"eventURN,omitempty" uof:"2"`
 	// Specifies which producer generated these odds. At any given point in time
 	// there should only be one product generating odds for a particular event.
-	Producer  Producer `xml:"product,attr" json:"producer,omitempty" bson:"producer,omitempty"`
-	Timestamp int      `xml:"timestamp,attr" json:"timestamp,omitempty" bson:"timestamp,omitempty"`
-	Markets   []Market `json:"markets,omitempty" bson:"markets,omitempty"`
+	Producer  Producer `xml:"product,attr" json:"producer,omitempty" bson:"producer,omitempty" uof:"3"`
+	Timestamp int      `xml:"timestamp,attr" json:"timestamp,omitempty" bson:"timestamp,omitempty" uof:"4"`
+	Markets   []Market `json:"markets,omitempty" bson:"markets,omitempty" uof:"5"`
 	// values in range 0-6   /v1/descriptions/betting_status.xml
-	BettingStatus *int `json:"bettingStatus,omitempty" bson:"bettingStatus,omitempty"`
+	BettingStatus *int `json:"bettingStatus,omitempty" bson:"bettingStatus,omitempty" uof:"6"`
 	// values in range 0-87  /v1/descriptions/betstop_reasons.xml
-	BetstopReason    *int              `json:"betstopReason,omitempty" bson:"betstopReason,omitempty"`
-	OddsChangeReason *int              `xml:"odds_change_reason,attr,omitempty" json:"oddsChangeReason,omitempty" bson:"oddsChangeReason,omitempty"` // May be one of 1
-	EventStatus      *SportEventStatus `xml:"sport_event_status,omitempty" json:"sportEventStatus,omitempty" bson:"eventStatus,omitempty"`
+	BetstopReason    *int              `json:"betstopReason,omitempty" bson:"betstopReason,omitempty" uof:"7"`
+	OddsChangeReason *int              `xml:"odds_change_reason,attr,omitempty" json:"oddsChangeReason,omitempty" bson:"oddsChangeReason,omitempty" uof:"8"` // May be one of 1
+	EventStatus      *SportEventStatus `xml:"sport_event_status,omitempty" json:"sportEventStatus,omitempty" bson:"eventStatus,omitempty" uof:"9"`
 
-	OddsGenerationProperties *OddsGenerationProperties `xml:"odds_generation_properties,omitempty" json:"oddsGenerationProperties,omitempty" bson:"oddsGenerationProperties,omitempty"`
-	RequestID                *int                      `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty"`
+	OddsGenerationProperties *OddsGenerationProperties `xml:"odds_generation_properties,omitempty" json:"oddsGenerationProperties,omitempty" bson:"oddsGenerationProperties,omitempty" uof:"10"`
+	RequestID                *int                      `xml:"request_id,attr,omitempty" json:"requestId,omitempty" bson:"requestId,omitempty" uof:"11"`
+	// Difference to the previous odds change for the event. Set by OddsDelta
+	// pipe stage, not part of the message xml.
+	Delta *OddsDelta `xml:"-" json:"delta,omitempty" bson:"delta,omitempty" uof:"12"`
 }
 
 // Provided by the prematch odds producer only, and contains a few
 // key-parameters that can be used in a client’s own special odds model, or
 // even offer spread betting bets based on it.
 type OddsGenerationProperties struct {
-	ExpectedTotals    *float64 `xml:"expected_totals,attr,omitempty" json:"expectedTotals,omitempty" bson:"expectedTotals,omitempty"`
-	ExpectedSupremacy *float64 `xml:"expected_supremacy,attr,omitempty" json:"expectedSupremacy,omitempty" bson:"expectedSupremacy,omitempty"`
+	ExpectedTotals    *float64 `xml:"expected_totals,attr,omitempty" json:"expectedTotals,omitempty" bson:"expectedTotals,omitempty" uof:"1"`
+	ExpectedSupremacy *float64 `xml:"expected_supremacy,attr,omitempty" json:"expectedSupremacy,omitempty" bson:"expectedSupremacy,omitempty" uof:"2"`
 }
 
 // Market describes the odds updates for a particular market.
@@ -50,33 +54,33 @@ type OddsGenerationProperties struct {
 // LineID is hash of specifier field used to uniquely identify lines in one market.
 // One market line is uniquely identified by market id and line id.
 type Market struct {
-	ID            int               `xml:"id,attr" json:"id,omitempty" bson:"id,omitempty"`
-	LineID        int               `json:"lineId,omitempty" bson:"lineId,omitempty"`
-	VariantID     int               `json:"variantId,omitempty" bson:"variantId,omitempty"`
-	Specifiers    map[string]string `json:"specifiers,omitempty" bson:"specifiers,omitempty"`
-	Status        MarketStatus      `xml:"status,attr,omitempty" json:"status" bson:"status"`
-	CashoutStatus *CashoutStatus    `xml:"cashout_status,attr,omitempty" json:"cashoutStatus,omitempty" bson:"cashoutStatus,omitempty"`
+	ID            int               `xml:"id,attr" json:"id,omitempty" bson:"id,omitempty" uof:"1"`
+	LineID        int               `json:"lineId,omitempty" bson:"lineId,omitempty" uof:"2"`
+	VariantID     int               `json:"variantId,omitempty" bson:"variantId,omitempty" uof:"3"`
+	Specifiers    map[string]string `json:"specifiers,omitempty" bson:"specifiers,omitempty" uof:"4"`
+	Status        MarketStatus      `xml:"status,attr,omitempty" json:"status" bson:"status" uof:"5"`
+	CashoutStatus *CashoutStatus    `xml:"cashout_status,attr,omitempty" json:"cashoutStatus,omitempty" bson:"cashoutStatus,omitempty" uof:"6"`
 	// If present, this is set to 1, which states that this is the most balanced
 	// or recommended market line. This setting makes most sense for markets where
 	// multiple lines are provided (e.g. the Totals market).
-	Favourite *bool     `xml:"favourite,attr,omitempty" json:"favourite,omitempty" bson:"favourite,omitempty"`
-	Outcomes  []Outcome `xml:"outcome,omitempty" json:"outcomes,omitempty" bson:"outcomes,omitempty"`
+	Favourite *bool     `xml:"favourite,attr,omitempty" json:"favourite,omitempty" bson:"favourite,omitempty" uof:"7"`
+	Outcomes  []Outcome `xml:"outcome,omitempty" json:"outcomes,omitempty" bson:"outcomes,omitempty" uof:"8"`
 	// Timestamp in UTC when to betstop this market. Typically used for outrights
 	// and typically is the start-time of the event the market refers to.
-	NextBetstop *int `json:"nextBetstop,omitempty" bson:"nextBetstop,omitempty"`
+	NextBetstop *int `json:"nextBetstop,omitempty" bson:"nextBetstop,omitempty" uof:"9"`
 }
 type MarketMetadata struct {
 	NextBetstop *int `xml:"next_betstop,attr,omitempty" json:"nextBetstop,omitempty" bson:"nextBetstop,omitempty"`
 }
 
 type Outcome struct {
-	ID            int      `json:"id" bson:"id,omitempty"`
-	PlayerID      int      `json:"playerId,omitempty" bson:"playerId,omitempty"`
-	Competitors   []int    `json:"competitors,omitempty" bson:"competitors,omitempty"`
-	Odds          *float64 `xml:"odds,attr,omitempty" json:"odds,omitempty" bson:"odds,omitempty"`
-	Probabilities *float64 `xml:"probabilities,attr,omitempty" json:"probabilities,omitempty" bson:"probabilities,omitempty"`
-	Active        *bool    `xml:"active,attr,omitempty" json:"active,omitempty" bson:"active,omitempty"`
-	Team          *Team    `xml:"team,attr,omitempty" json:"team,omitempty" bson:"team,omitempty"`
+	ID            int      `json:"id" bson:"id,omitempty" uof:"1"`
+	PlayerID      int      `json:"playerId,omitempty" bson:"playerId,omitempty" uof:"2"`
+	Competitors   []int    `json:"competitors,omitempty" bson:"competitors,omitempty" uof:"3"`
+	Odds          *float64 `xml:"odds,attr,omitempty" json:"odds,omitempty" bson:"odds,omitempty" uof:"4"`
+	Probabilities *float64 `xml:"probabilities,attr,omitempty" json:"probabilities,omitempty" bson:"probabilities,omitempty" uof:"5"`
+	Active        *bool    `xml:"active,attr,omitempty" json:"active,omitempty" bson:"active,omitempty" uof:"6"`
+	Team          *Team    `xml:"team,attr,omitempty" json:"team,omitempty" bson:"team,omitempty" uof:"7"`
 }
 
 // UnmarshalXML
@@ -128,7 +132,10 @@ func (m *Market) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	if overlay.Status != nil {
 		m.Status = MarketStatus(*overlay.Status)
 	}
-	m.Specifiers, m.LineID = toSpecifiersLineID(overlay.Specifiers, overlay.ExtendedSpecifiers)
+	var err error
+	if m.Specifiers, m.LineID, err = toSpecifiersLineID(overlay.Specifiers, overlay.ExtendedSpecifiers); err != nil {
+		return err
+	}
 	m.VariantID = toVariantID(variantSpecifier(m.Specifiers))
 	if overlay.MarketMetadata != nil {
 		m.NextBetstop = overlay.MarketMetadata.NextBetstop
@@ -146,10 +153,9 @@ func (t *Outcome) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	if err := d.DecodeElement(&overlay, &start); err != nil {
 		return err
 	}
-	t.ID = toOutcomeID(overlay.ID)
-	t.PlayerID = toPlayerID(overlay.ID)
-	t.Competitors = toComptitors(overlay.ID)
-	return nil
+	var err error
+	t.ID, t.PlayerID, t.Competitors, err = toOutcomeIDs(overlay.ID)
+	return err
 }
 
 func (m Market) VariantSpecifier() string {
@@ -157,11 +163,9 @@ func (m Market) VariantSpecifier() string {
 	return strings.TrimPrefix(v, "replay:")
 }
 
-const variantSpecifireKey = "variant"
-
 func variantSpecifier(specifiers map[string]string) string {
 	for k, v := range specifiers {
-		if k == variantSpecifireKey {
+		if k == SpecifierVariant {
 			return v
 		}
 	}
@@ -182,7 +186,7 @@ func toSpecifiers(specifiers, extendedSpecifiers string) map[string]string {
 		if p := strings.Split(s, "="); len(p) == 2 {
 			k := p[0]
 			v := p[1]
-			if k == "player" {
+			if k == SpecifierPlayer {
 				v = strings.TrimPrefix(v, srPlayer)
 			}
 			sm[k] = v
@@ -191,21 +195,25 @@ func toSpecifiers(specifiers, extendedSpecifiers string) map[string]string {
 	return sm
 }
 
-func toSpecifiersLineID(specifiers, extendedSpecifiers string) (map[string]string, int) {
+// toSpecifiersLineID splits specifiers into map and calculates market line id.
+// Each specifier has to be in key=value form, empty ones are skipped.
+func toSpecifiersLineID(specifiers, extendedSpecifiers string) (map[string]string, int, error) {
 	if len(specifiers) == 0 && len(extendedSpecifiers) == 0 {
-		return nil, 0
+		return nil, 0, nil
 	}
 	sm := make(map[string]string)
 	hasVariant := false
 	var withoutVariant []string
-	for _, s := range strings.Split(specifiers, "|") {
-		if p := strings.Split(s, "="); len(p) == 2 {
-			k := p[0]
-			v := p[1]
-			if k == "player" {
-				v = strings.TrimPrefix(v, srPlayer)
+	if specifiers != "" {
+		for _, s := range strings.Split(specifiers, "|") {
+			if s == "" {
+				continue
+			}
+			k, v, err := splitSpecifier(s)
+			if err != nil {
+				return nil, 0, err
 			}
-			if k == variantSpecifireKey {
+			if k == SpecifierVariant {
 				hasVariant = true
 			} else {
 				withoutVariant = append(withoutVariant, s)
@@ -213,57 +221,82 @@ func toSpecifiersLineID(specifiers, extendedSpecifiers string) (map[string]strin
 			sm[k] = v
 		}
 	}
-	for _, s := range strings.Split(extendedSpecifiers, "|") {
-		if p := strings.Split(s, "="); len(p) == 2 {
-			k := p[0]
-			v := p[1]
-			if k == "player" {
-				v = strings.TrimPrefix(v, srPlayer)
+	if extendedSpecifiers != "" {
+		for _, s := range strings.Split(extendedSpecifiers, "|") {
+			if s == "" {
+				continue
+			}
+			k, v, err := splitSpecifier(s)
+			if err != nil {
+				return nil, 0, err
 			}
 			sm[k] = v
 		}
 	}
 	if len(specifiers) == 0 {
-		return sm, 0
+		return sm, 0, nil
 	}
 	if !hasVariant {
-		return sm, toLineID(specifiers)
+		return sm, toLineID(specifiers), nil
 	}
-	return sm, toLineID(strings.Join(withoutVariant, "|"))
+	return sm, toLineID(strings.Join(withoutVariant, "|")), nil
 }
 
-func toPlayerID(id string) int {
-	if strings.HasPrefix(id, srPlayer) {
-		return URN(id).ID()
+func splitSpecifier(s string) (string, string, error) {
+	p := strings.Split(s, "=")
+	if len(p) != 2 || p[0] == "" {
+		return "", "", fmt.Errorf("malformed specifier %q", s)
 	}
-	return 0
+	k, v := p[0], p[1]
+	if k == SpecifierPlayer {
+		v = strings.TrimPrefix(v, srPlayer)
+	}
+	return k, v, nil
 }
 
-func toComptitors(id string) []int {
-	if !strings.Contains(id, srCompetitor) {
-		return nil
-	}
-	var competitors []int
-	for _, p := range strings.Split(id, ",") {
-		v := strings.TrimPrefix(p, srCompetitor)
-		if k, err := strconv.Atoi(v); err == nil {
-			competitors = append(competitors, k)
+// LineID returns market line id for the market specifiers, as they are
+// written in the feed messages (for example "total=2.5|hcp=1:0"). Malformed
+// specifiers have line id 0.
+func LineID(specifiers string) int {
+	_, lineID, _ := toSpecifiersLineID(specifiers, "")
+	return lineID
+}
+
+// toOutcomeIDs returns outcome id, player id and competitors from the outcome
+// id attribute. Player and competitor outcomes have urns in the id, malformed
+// urn is an error. Other non numeric ids are hashed.
+func toOutcomeIDs(id string) (int, int, []int, error) {
+	if strings.HasPrefix(id, srPlayer) {
+		playerID, err := toURNID(id, URNTypePlayer)
+		return playerID, playerID, nil, err
+	}
+	if strings.Contains(id, srCompetitor) {
+		var competitors []int
+		for _, p := range strings.Split(id, ",") {
+			c, err := toURNID(p, URNTypeCompetitor)
+			if err != nil {
+				return 0, 0, nil, err
+			}
+			competitors = append(competitors, c)
 		}
+		return hash32(id), 0, competitors, nil
 	}
-	if len(competitors) == 0 {
-		return nil
+	if i, err := strconv.ParseInt(id, 10, 64); err == nil {
+		return int(i), 0, nil, nil
 	}
-	return competitors
+	return hash32(id), 0, nil, nil
 }
 
-func toOutcomeID(id string) int {
-	if strings.HasPrefix(id, srPlayer) {
-		return toPlayerID(id)
+// toURNID returns id from the urn of the type
+func toURNID(urn string, typ URNType) (int, error) {
+	p, err := ParseURN(urn)
+	if err != nil {
+		return 0, err
 	}
-	if i, err := strconv.ParseInt(id, 10, 64); err == nil {
-		return int(i)
+	if p.Type != typ {
+		return 0, fmt.Errorf("expected %s urn %q", typ, urn)
 	}
-	return hash32(id)
+	return p.ID, nil
 }
 
 func (o *OddsChange) EachPlayer(handler func(int)) {
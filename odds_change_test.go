@@ -182,7 +182,8 @@ func TestSpecifiersParsing(t *testing.T) {
 	}
 	for i, d := range data {
 		s := toSpecifiers(d.specifiers, d.extendedSpecifers)
-		s2, lineID := toSpecifiersLineID(d.specifiers, d.extendedSpecifers)
+		s2, lineID, err := toSpecifiersLineID(d.specifiers, d.extendedSpecifers)
+		assert.NoError(t, err)
 
 		assert.Equal(t, len(d.specifiersMap), len(s))
 		assert.Equal(t, d.variantSpecifier, variantSpecifier(s))
@@ -0,0 +1,61 @@
+package uof
+
+// OddsDelta is the difference of the odds change to the previous state of the
+// event. Previous state is built from the previous odds changes of the same
+// producer for the event.
+type OddsDelta struct {
+	// Markets which are new, removed or whose status has changed.
+	Markets []MarketDelta `json:"markets,omitempty" bson:"markets,omitempty" uof:"1"`
+	// Outcomes whose odds or active flag has changed, including all outcomes
+	// of the new markets.
+	Outcomes []OutcomeDelta `json:"outcomes,omitempty" bson:"outcomes,omitempty" uof:"2"`
+	// Betting status, bet stop reason or sport event status has changed.
+	BettingStatusChanged bool `json:"bettingStatusChanged,omitempty" bson:"bettingStatusChanged,omitempty" uof:"3"`
+	EventStatusChanged   bool `json:"eventStatusChanged,omitempty" bson:"eventStatusChanged,omitempty" uof:"4"`
+}
+
+type MarketChange int8
+
+const (
+	MarketChangeStatus MarketChange = iota
+	MarketChangeNew
+	MarketChangeRemoved // deactivated or cancelled
+)
+
+func (c MarketChange) String() string {
+	switch c {
+	case MarketChangeNew:
+		return "new"
+	case MarketChangeRemoved:
+		return "removed"
+	default:
+		return "status"
+	}
+}
+
+// MarketDelta describes change of one market line.
+type MarketDelta struct {
+	ID         int          `json:"id" bson:"id" uof:"1"`
+	LineID     int          `json:"lineId,omitempty" bson:"lineId,omitempty" uof:"2"`
+	Change     MarketChange `json:"change" bson:"change" uof:"3"`
+	Status     MarketStatus `json:"status" bson:"status" uof:"4"`
+	PrevStatus MarketStatus `json:"prevStatus" bson:"prevStatus" uof:"5"`
+}
+
+// OutcomeDelta describes change of one outcome in the market line.
+type OutcomeDelta struct {
+	MarketID   int      `json:"marketId" bson:"marketId" uof:"1"`
+	LineID     int      `json:"lineId,omitempty" bson:"lineId,omitempty" uof:"2"`
+	ID         int      `json:"id" bson:"id" uof:"3"`
+	Odds       *float64 `json:"odds,omitempty" bson:"odds,omitempty" uof:"4"`
+	PrevOdds   *float64 `json:"prevOdds,omitempty" bson:"prevOdds,omitempty" uof:"5"`
+	Active     *bool    `json:"active,omitempty" bson:"active,omitempty" uof:"6"`
+	PrevActive *bool    `json:"prevActive,omitempty" bson:"prevActive,omitempty" uof:"7"`
+}
+
+// Empty is true if there is no effective change.
+func (d *OddsDelta) Empty() bool {
+	return d == nil ||
+		len(d.Markets) == 0 && len(d.Outcomes) == 0 &&
+			!d.BettingStatusChanged && !d.EventStatusChanged
+}
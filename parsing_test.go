@@ -491,3 +491,18 @@ func TestConnectionStatus(t *testing.T) {
 	}
 
 }
+
+func TestFixtureWithoutTournament(t *testing.T) {
+	var f Fixture
+	err := xml.Unmarshal([]byte(`<fixture id="sr:match:1"/>`), &f)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, f.ID)
+	assert.Equal(t, 0, f.Tournament.ID)
+}
+
+func TestMalformedPlayerID(t *testing.T) {
+	var p Player
+	assert.Error(t, xml.Unmarshal([]byte(`<player id="sr:player:x"/>`), &p))
+	assert.NoError(t, xml.Unmarshal([]byte(`<player id="sr:player:1"/>`), &p))
+	assert.Equal(t, 1, p.ID)
+}
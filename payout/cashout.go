@@ -0,0 +1,31 @@
+package payout
+
+import "github.com/minus5/go-uof-sdk"
+
+// CashoutValue calculates fair cashout value of the open accumulator bet
+// (single is accumulator with one selection). Settled selections count with
+// their payout factor, unsettled with the odds multiplied by the current win
+// probability. Probabilities are by the event urn. ok is false if any of the
+// unsettled selections has no probability. Value is without margin, apply
+// bookmaker cashout margin to it.
+func CashoutValue(stake float64, probabilities map[uof.URN]*uof.CashoutProbabilities, selections ...Selection) (float64, bool) {
+	value := stake
+	for _, s := range selections {
+		if s.Result != nil {
+			if w, r, ok := s.Result.Factors(); ok {
+				f := s.Odds*w + r
+				if f == 0 {
+					return 0, true
+				}
+				value *= f
+				continue
+			}
+		}
+		p, ok := probabilities[s.EventURN].Probability(s.MarketID, s.LineID, s.OutcomeID)
+		if !ok {
+			return 0, false
+		}
+		value *= s.Odds * p
+	}
+	return value, true
+}
@@ -0,0 +1,45 @@
+package payout
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashoutValue(t *testing.T) {
+	buf, err := ioutil.ReadFile("../testdata/cashout_probabilities.xml")
+	require.NoError(t, err)
+	m, err := uof.NewAPIMessage(uof.LangEN, uof.MessageTypeCashoutProbabilities, buf)
+	require.NoError(t, err)
+	probs := map[uof.URN]*uof.CashoutProbabilities{m.EventURN: m.CashoutProbabilities}
+
+	home := Selection{EventURN: m.EventURN, MarketID: 1, OutcomeID: 1, Odds: 1.5}
+	over := Selection{EventURN: m.EventURN, MarketID: 18, LineID: uof.LineID("total=2.5"), OutcomeID: 12, Odds: 2}
+	v, ok := CashoutValue(10, probs, home)
+	assert.True(t, ok)
+	assert.InDelta(t, 10*1.5*0.62, v, 1e-9)
+	v, ok = CashoutValue(10, probs, home, over)
+	assert.True(t, ok)
+	assert.InDelta(t, 10*1.5*0.62*2*0.55, v, 1e-9)
+
+	// settled selections count with payout factors
+	won := Selection{EventURN: "sr:match:1", MarketID: 1, OutcomeID: 1, Odds: 3, Result: &uof.BetSettlementOutcome{Result: uof.OutcomeResultWin}}
+	void := Selection{EventURN: "sr:match:2", MarketID: 1, OutcomeID: 1, Odds: 3, Result: &uof.BetSettlementOutcome{Result: uof.OutcomeResultVoid}}
+	v, ok = CashoutValue(10, probs, home, won, void)
+	assert.True(t, ok)
+	assert.InDelta(t, 10*1.5*0.62*3, v, 1e-9)
+	lost := Selection{EventURN: "sr:match:3", MarketID: 1, OutcomeID: 1, Odds: 3, Result: &uof.BetSettlementOutcome{Result: uof.OutcomeResultLose}}
+	v, ok = CashoutValue(10, probs, home, lost)
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, v)
+
+	// no probability
+	unavailable := Selection{EventURN: m.EventURN, MarketID: 18, LineID: uof.LineID("total=3.5"), OutcomeID: 12, Odds: 3}
+	_, ok = CashoutValue(10, probs, home, unavailable)
+	assert.False(t, ok)
+	_, ok = CashoutValue(10, nil, home)
+	assert.False(t, ok)
+}
@@ -0,0 +1,152 @@
+// Package payout calculates payout and cashout value of the bets from the
+// settlement messages and cashout probabilities.
+package payout
+
+import (
+	"fmt"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+// Selection is one outcome on the bet with the odds at which it was accepted.
+// Result is the settlement of the outcome, nil until settled.
+type Selection struct {
+	EventURN  uof.URN
+	MarketID  int
+	LineID    int
+	OutcomeID int
+	Odds      float64
+	Result    *uof.BetSettlementOutcome
+}
+
+// Settle sets selection result from the bet settlement. Returns true if the
+// settlement contains selection outcome.
+func (s *Selection) Settle(bs *uof.BetSettlement) bool {
+	if bs == nil || bs.EventURN != s.EventURN {
+		return false
+	}
+	for _, m := range bs.Markets {
+		if m.ID != s.MarketID || m.LineID != s.LineID {
+			continue
+		}
+		for _, o := range m.Outcomes {
+			if o.ID == s.OutcomeID {
+				r := o
+				s.Result = &r
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Rollback clears selection result if the rollback is for the selection
+// market. Returns true if the result is cleared.
+func (s *Selection) Rollback(rb *uof.RollbackBetSettlement) bool {
+	if rb == nil || rb.EventURN != s.EventURN || s.Result == nil {
+		return false
+	}
+	for _, m := range rb.Markets {
+		if m.ID == s.MarketID && m.LineID == s.LineID {
+			s.Result = nil
+			return true
+		}
+	}
+	return false
+}
+
+// Payout of the bet. Win is the amount paid at odds (including the stake part
+// which won), Refund is the returned part of the stake. Amounts are not
+// rounded.
+type Payout struct {
+	Settled bool
+	Win     float64
+	Refund  float64
+}
+
+// Total amount returned to the player.
+func (p Payout) Total() float64 {
+	return p.Win + p.Refund
+}
+
+// Single calculates payout of the single bet.
+func Single(stake float64, s Selection) Payout {
+	return Accumulator(stake, s)
+}
+
+// Accumulator calculates payout of the bet combining all selections. Return of
+// each selection is rolled over to the next one; voided part of the selection
+// counts as odds 1. Accumulator is settled when all selections are settled or
+// when any of the settled selections is lost.
+func Accumulator(stake float64, selections ...Selection) Payout {
+	total, refund := float64(1), float64(1)
+	settled := true
+	for _, s := range selections {
+		if s.Result == nil {
+			settled = false
+			continue
+		}
+		w, r, ok := s.Result.Factors()
+		if !ok {
+			settled = false
+			continue
+		}
+		f := s.Odds*w + r
+		if f == 0 {
+			return Payout{Settled: true}
+		}
+		total *= f
+		refund *= r
+	}
+	if !settled {
+		return Payout{}
+	}
+	return Payout{
+		Settled: true,
+		Win:     stake * (total - refund),
+		Refund:  stake * refund,
+	}
+}
+
+// System calculates payout of the system bet: each combination of size
+// selections is accumulator with stake. Total stake of the bet is stake
+// multiplied by Combinations(len(selections), size). Returns error if size is
+// not in 1..len(selections).
+func System(stake float64, size int, selections ...Selection) (Payout, error) {
+	if size <= 0 || size > len(selections) {
+		return Payout{}, fmt.Errorf("invalid system size %d for %d selections", size, len(selections))
+	}
+	p := Payout{Settled: true}
+	combination := make([]Selection, size)
+	var each func(start, depth int)
+	each = func(start, depth int) {
+		if depth == size {
+			a := Accumulator(stake, combination...)
+			p.Settled = p.Settled && a.Settled
+			p.Win += a.Win
+			p.Refund += a.Refund
+			return
+		}
+		for i := start; i <= len(selections)-(size-depth); i++ {
+			combination[depth] = selections[i]
+			each(i+1, depth+1)
+		}
+	}
+	each(0, 0)
+	if !p.Settled {
+		return Payout{}, nil
+	}
+	return p, nil
+}
+
+// Combinations returns number of combinations of size k from n selections.
+func Combinations(n, k int) int {
+	if k < 0 || k > n {
+		return 0
+	}
+	c := 1
+	for i := 1; i <= k; i++ {
+		c = c * (n - k + i) / i
+	}
+	return c
+}
@@ -0,0 +1,102 @@
+package payout
+
+import (
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func testBetSettlement(t *testing.T) *uof.BetSettlement {
+	m, err := uof.NewQueueMessage("lo.pre.-.bet_settlement.1.sr:match.1.-", []byte(`<bet_settlement product="1" event_id="sr:match:1" timestamp="1">
+	<outcomes>
+		<market id="1">
+			<outcome id="1" result="0"/>
+			<outcome id="2" result="1"/>
+			<outcome id="3" result="0" void_factor="1"/>
+		</market>
+		<market id="16" specifiers="hcp=0.25">
+			<outcome id="1714" result="1" void_factor="0.5"/>
+			<outcome id="1715" result="0" void_factor="0.5"/>
+		</market>
+		<market id="40">
+			<outcome id="sr:player:1" result="1" dead_heat_factor="0.5"/>
+		</market>
+	</outcomes>
+	</bet_settlement>`))
+	assert.NoError(t, err)
+	return m.BetSettlement
+}
+
+func TestPayoutSingle(t *testing.T) {
+	bs := testBetSettlement(t)
+	hcp := bs.Markets[1].LineID
+	cases := []struct {
+		marketID  int
+		lineID    int
+		outcomeID int
+		win       float64
+		refund    float64
+	}{
+		{1, 0, 1, 0, 0},          // lose
+		{1, 0, 2, 25, 0},         // win
+		{1, 0, 3, 0, 10},         // void
+		{16, hcp, 1714, 12.5, 5}, // half win, half refund
+		{16, hcp, 1715, 0, 5},    // half lose, half refund
+		{40, 0, 1, 12.5, 0},      // dead heat
+	}
+	for _, c := range cases {
+		s := Selection{EventURN: "sr:match:1", MarketID: c.marketID, LineID: c.lineID, OutcomeID: c.outcomeID, Odds: 2.5}
+		assert.False(t, Single(10, s).Settled)
+		assert.True(t, s.Settle(bs))
+		p := Single(10, s)
+		assert.True(t, p.Settled)
+		assert.Equal(t, c.win, p.Win, c.outcomeID)
+		assert.Equal(t, c.refund, p.Refund, c.outcomeID)
+	}
+
+	// rollback
+	s := Selection{EventURN: "sr:match:1", MarketID: 1, OutcomeID: 2, Odds: 2}
+	assert.True(t, s.Settle(bs))
+	assert.False(t, s.Rollback(&uof.RollbackBetSettlement{EventURN: "sr:match:1", Markets: []uof.BetCancelMarket{{ID: 2}}}))
+	assert.True(t, s.Rollback(&uof.RollbackBetSettlement{EventURN: "sr:match:1", Markets: []uof.BetCancelMarket{{ID: 1}}}))
+	assert.Nil(t, s.Result)
+	assert.False(t, Single(10, s).Settled)
+}
+
+func TestPayoutAccumulator(t *testing.T) {
+	result := func(r uof.OutcomeResult) *uof.BetSettlementOutcome {
+		return &uof.BetSettlementOutcome{Result: r}
+	}
+	win := Selection{Odds: 2, Result: result(uof.OutcomeResultWin)}
+	void := Selection{Odds: 3, Result: result(uof.OutcomeResultVoid)}
+	halfWin := Selection{Odds: 3, Result: result(uof.OutcomeResultHalfWin)}
+	lose := Selection{Odds: 3, Result: result(uof.OutcomeResultLose)}
+	pending := Selection{Odds: 3}
+
+	p := Accumulator(10, win, void)
+	assert.Equal(t, Payout{Settled: true, Win: 20}, p)
+	p = Accumulator(10, win, halfWin)
+	assert.Equal(t, 40.0, p.Total())
+	p = Accumulator(10, void, void)
+	assert.Equal(t, Payout{Settled: true, Refund: 10}, p)
+	assert.False(t, Accumulator(10, win, pending).Settled)
+	// lost selection settles the accumulator
+	assert.Equal(t, Payout{Settled: true}, Accumulator(10, pending, lose))
+
+	// system 2/3: win*void, win*halfWin, void*halfWin
+	p, err := System(1, 2, win, void, halfWin)
+	assert.NoError(t, err)
+	assert.True(t, p.Settled)
+	assert.Equal(t, 2+4+2.0, p.Total())
+	p, err = System(1, 2, win, pending, void)
+	assert.NoError(t, err)
+	assert.False(t, p.Settled)
+	_, err = System(1, 0, win, void)
+	assert.Error(t, err)
+	_, err = System(1, 3, win, void)
+	assert.Error(t, err)
+	assert.Equal(t, 3, Combinations(3, 2))
+	assert.Equal(t, 10, Combinations(5, 3))
+	assert.Equal(t, 0, Combinations(2, 3))
+}
@@ -0,0 +1,302 @@
+package pipe
+
+import (
+	"sync"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+// RejectReason describes why selection is not bettable.
+type RejectReason int8
+
+const (
+	RejectNone RejectReason = iota
+	RejectConnectionDown
+	RejectProducerDown
+	RejectUnknownEvent
+	RejectBettingStatus
+	RejectUnknownMarket
+	RejectBetStop
+	RejectMarketNotActive
+	RejectUnknownOutcome
+	RejectOutcomeNotActive
+	RejectOddsStale
+	RejectProducersUnknown
+)
+
+func (r RejectReason) String() string {
+	switch r {
+	case RejectNone:
+		return "none"
+	case RejectConnectionDown:
+		return "connection down"
+	case RejectProducerDown:
+		return "producer down"
+	case RejectUnknownEvent:
+		return "unknown event"
+	case RejectBettingStatus:
+		return "betting status"
+	case RejectUnknownMarket:
+		return "unknown market"
+	case RejectBetStop:
+		return "bet stop"
+	case RejectMarketNotActive:
+		return "market not active"
+	case RejectUnknownOutcome:
+		return "unknown outcome"
+	case RejectOutcomeNotActive:
+		return "outcome not active"
+	case RejectOddsStale:
+		return "odds stale"
+	case RejectProducersUnknown:
+		return "producers unknown"
+	}
+	return "unknown"
+}
+
+// Decision of the bet acceptance check.
+type Decision struct {
+	Accept   bool
+	Reason   RejectReason
+	Producer uof.Producer
+	Odds     *float64
+}
+
+type acceptMarket struct {
+	producer   uof.Producer
+	status     uof.MarketStatus
+	betStopped bool
+	stopOrigin uof.BetStopOrigin // origin of the bet stop
+	outcomes   map[int]uof.Outcome
+	receivedAt int
+}
+
+type acceptEvent struct {
+	bettingStatus map[uof.Producer]*int
+	markets       map[lineKey]*acceptMarket
+	updatedAt     int
+}
+
+// BetAcceptance keeps the state of the producers and event markets needed to
+// decide whether a selection is bettable right now. State is built by the
+// Stage, Check can be called concurrently from any goroutine.
+//
+// Producers state comes from the producers change messages which are sent by
+// the Recovery stage (sdk.Recovery option). Without it every check is
+// rejected with RejectProducersUnknown.
+type BetAcceptance struct {
+	maxOddsAge time.Duration
+	now        func() int
+
+	sync.RWMutex
+	connectionDown bool
+	producers      map[uof.Producer]uof.ProducerStatus // nil until the first producers change
+	events         map[uof.URN]*acceptEvent
+	sweptAt        int
+}
+
+// NewBetAcceptance creates bet acceptance state. Odds of the non prematch
+// producers are considered stale if not refreshed in maxOddsAge, zero
+// disables that check.
+func NewBetAcceptance(maxOddsAge time.Duration) *BetAcceptance {
+	return &BetAcceptance{
+		maxOddsAge: maxOddsAge,
+		now:        uof.CurrentTimestamp,
+		events:     make(map[uof.URN]*acceptEvent),
+	}
+}
+
+// Stage returns pipe stage which builds bet acceptance state from the
+// messages. It should be placed after the BetStop stage, so bet stop
+// messages have market ids.
+func (a *BetAcceptance) Stage() InnerStage {
+	return Stage(func(in <-chan *uof.Message, out chan<- *uof.Message, errc chan<- error) {
+		for m := range in {
+			if !unpackBody(m, errc, uof.MessageTypeOddsChange, uof.MessageTypeBetStop, uof.MessageTypeBetSettlement) {
+				continue
+			}
+			a.apply(m)
+			out <- m
+		}
+	})
+}
+
+func (a *BetAcceptance) apply(m *uof.Message) {
+	a.Lock()
+	defer a.Unlock()
+
+	switch m.Type {
+	case uof.MessageTypeConnection:
+		if m.Connection != nil {
+			a.connectionDown = m.Connection.Status == uof.ConnectionStatusDown
+		}
+	case uof.MessageTypeProducersChange:
+		if a.producers == nil {
+			a.producers = make(map[uof.Producer]uof.ProducerStatus)
+		}
+		for _, pc := range m.Producers {
+			a.producers[pc.Producer] = pc.Status
+		}
+	case uof.MessageTypeOddsChange:
+		if m.OddsChange != nil {
+			a.oddsChange(m)
+		}
+	case uof.MessageTypeBetStop, uof.MessageTypeBetStopLift:
+		if m.BetStop != nil {
+			a.betStop(m)
+		}
+	case uof.MessageTypeBetSettlement:
+		if m.BetSettlement != nil {
+			a.betSettlement(m)
+		}
+	}
+	a.sweep(m.ReceivedAt)
+}
+
+func (a *BetAcceptance) event(eventURN uof.URN, receivedAt int) *acceptEvent {
+	e, ok := a.events[eventURN]
+	if !ok {
+		e = &acceptEvent{
+			bettingStatus: make(map[uof.Producer]*int),
+			markets:       make(map[lineKey]*acceptMarket),
+		}
+		a.events[eventURN] = e
+	}
+	e.updatedAt = receivedAt
+	return e
+}
+
+func (a *BetAcceptance) oddsChange(m *uof.Message) {
+	oc := m.OddsChange
+	e := a.event(m.EventURN, m.ReceivedAt)
+	e.bettingStatus[m.Producer] = oc.BettingStatus
+	for _, mkt := range oc.Markets {
+		am := &acceptMarket{
+			producer:   m.Producer,
+			status:     mkt.Status,
+			outcomes:   make(map[int]uof.Outcome, len(mkt.Outcomes)),
+			receivedAt: m.ReceivedAt,
+		}
+		for _, o := range mkt.Outcomes {
+			am.outcomes[o.ID] = o
+		}
+		e.markets[lineKey{id: mkt.ID, lineID: mkt.LineID}] = am
+	}
+}
+
+// betStop stops active markets of the event. Bet stop lift restores markets
+// stopped by the SDK generated stop of the same origin.
+func (a *BetAcceptance) betStop(m *uof.Message) {
+	bs := m.BetStop
+	e, ok := a.events[m.EventURN]
+	if !ok {
+		return
+	}
+	for k, am := range e.markets {
+		if am.producer != m.Producer || !betStopCovers(bs, k.id) {
+			continue
+		}
+		if m.Is(uof.MessageTypeBetStopLift) {
+			if am.betStopped && liftable(am.stopOrigin, bs.Origin) {
+				am.status = uof.MarketStatusActive
+				am.betStopped, am.stopOrigin = false, uof.BetStopOriginFeed
+			}
+			continue
+		}
+		if am.status == uof.MarketStatusActive {
+			am.status = bs.Status
+			am.betStopped, am.stopOrigin = true, bs.Origin
+		}
+	}
+}
+
+func (a *BetAcceptance) betSettlement(m *uof.Message) {
+	e, ok := a.events[m.EventURN]
+	if !ok {
+		return
+	}
+	for _, mkt := range m.BetSettlement.Markets {
+		if am, ok := e.markets[lineKey{id: mkt.ID, lineID: mkt.LineID}]; ok {
+			am.status = uof.MarketStatusSettled
+		}
+	}
+}
+
+// sweep removes state of the events without updates in eventStateTTL
+func (a *BetAcceptance) sweep(now int) {
+	if now-a.sweptAt < eventStateTTL/12 {
+		return
+	}
+	a.sweptAt = now
+	for id, e := range a.events {
+		if now-e.updatedAt > eventStateTTL {
+			delete(a.events, id)
+		}
+	}
+}
+
+// Check decides whether the selection is bettable right now. Selection is
+// identified by event, market id, market specifiers as written in the feed
+// (empty for markets without specifiers) and outcome id.
+//
+// Checks are made in order: producers state, connection, event, market,
+// betting status, bet stop, market status, producer, outcome, outcome active
+// and odds freshness. Betting status of the event is the one sent by the
+// market producer, it is ok if not set or zero.
+func (a *BetAcceptance) Check(eventURN uof.URN, marketID int, specifiers string, outcomeID int) Decision {
+	a.RLock()
+	defer a.RUnlock()
+
+	reject := func(r RejectReason) Decision {
+		return Decision{Reason: r}
+	}
+	if a.producers == nil {
+		return reject(RejectProducersUnknown)
+	}
+	if a.connectionDown {
+		return reject(RejectConnectionDown)
+	}
+	e, ok := a.events[eventURN]
+	if !ok {
+		return reject(RejectUnknownEvent)
+	}
+	am, ok := e.markets[lineKey{id: marketID, lineID: uof.LineID(specifiers)}]
+	if !ok {
+		return reject(RejectUnknownMarket)
+	}
+	d := Decision{Producer: am.producer}
+	if bs := e.bettingStatus[am.producer]; bs != nil && *bs != 0 {
+		d.Reason = RejectBettingStatus
+		return d
+	}
+	if am.betStopped {
+		d.Reason = RejectBetStop
+		return d
+	}
+	if am.status != uof.MarketStatusActive {
+		d.Reason = RejectMarketNotActive
+		return d
+	}
+	if a.producers[am.producer] != uof.ProducerStatusActive {
+		d.Reason = RejectProducerDown
+		return d
+	}
+	o, ok := am.outcomes[outcomeID]
+	if !ok {
+		d.Reason = RejectUnknownOutcome
+		return d
+	}
+	d.Odds = o.Odds
+	if o.Odds == nil || o.Active != nil && !*o.Active {
+		d.Reason = RejectOutcomeNotActive
+		return d
+	}
+	if a.maxOddsAge > 0 && !am.producer.Prematch() &&
+		a.now()-am.receivedAt > int(a.maxOddsAge/time.Millisecond) {
+		d.Reason = RejectOddsStale
+		return d
+	}
+	d.Accept = true
+	return d
+}
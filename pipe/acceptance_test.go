@@ -0,0 +1,128 @@
+package pipe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBetAcceptance(t *testing.T) {
+	a := NewBetAcceptance(time.Second)
+	now := 0
+	a.now = func() int { return now }
+	check := func() Decision {
+		return a.Check("sr:match:1", 18, "total=2.5", 12)
+	}
+	apply := func(m *uof.Message) {
+		m.ReceivedAt = now
+		a.apply(m)
+	}
+	odds := func(body string) {
+		apply(oddsDeltaMessage(t, "odds_change", "1", body))
+	}
+	producers := func(s uof.ProducerStatus) {
+		apply(uof.NewProducersChangeMessage(uof.ProducersChange{{Producer: uof.ProducerLiveOdds, Status: s}}))
+	}
+
+	// without producers change from the recovery
+	assert.Equal(t, RejectProducersUnknown, check().Reason)
+	producers(uof.ProducerStatusDown)
+	assert.Equal(t, RejectUnknownEvent, check().Reason)
+	odds(`<odds><market id="1"><outcome id="1" odds="1.5" active="1"/></market></odds>`)
+	assert.Equal(t, RejectUnknownMarket, check().Reason)
+	odds(`<odds><market id="18" specifiers="total=2.5"><outcome id="12" odds="1.9" active="1"/></market></odds>`)
+	assert.Equal(t, RejectProducerDown, check().Reason)
+
+	producers(uof.ProducerStatusActive)
+	d := check()
+	assert.True(t, d.Accept)
+	assert.Equal(t, RejectNone, d.Reason)
+	assert.Equal(t, 1.9, *d.Odds)
+	assert.Equal(t, uof.ProducerLiveOdds, d.Producer)
+	assert.Equal(t, RejectUnknownOutcome, a.Check("sr:match:1", 18, "total=2.5", 13).Reason)
+	assert.Equal(t, RejectUnknownMarket, a.Check("sr:match:1", 18, "total=3.5", 12).Reason)
+	// same id, other event type
+	assert.Equal(t, RejectUnknownEvent, a.Check("sr:season:1", 18, "total=2.5", 12).Reason)
+
+	now = 2000
+	assert.Equal(t, RejectOddsStale, check().Reason)
+	odds(`<odds><market id="18" specifiers="total=2.5"><outcome id="12" odds="1.9" active="0"/></market></odds>`)
+	assert.Equal(t, RejectOutcomeNotActive, check().Reason)
+	odds(`<odds><market id="18" specifiers="total=2.5" status="-1"><outcome id="12" odds="1.9" active="1"/></market></odds>`)
+	assert.Equal(t, RejectMarketNotActive, check().Reason)
+	odds(`<odds betting_status="1"><market id="18" specifiers="total=2.5"><outcome id="12" odds="1.9" active="1"/></market></odds>`)
+	assert.Equal(t, RejectBettingStatus, check().Reason)
+	odds(`<odds><market id="18" specifiers="total=2.5"><outcome id="12" odds="1.9" active="1"/></market></odds>`)
+	assert.True(t, check().Accept)
+	// betting status is by producer
+	apply(oddsDeltaMessage(t, "odds_change", "3", `<odds betting_status="1"><market id="1"><outcome id="1" odds="1.5" active="1"/></market></odds>`))
+	assert.True(t, check().Accept)
+
+	// bet stop covers market until next odds change
+	apply(oddsDeltaMessage(t, "bet_stop", "1", ""))
+	assert.Equal(t, RejectBetStop, check().Reason)
+	odds(`<odds><market id="18" specifiers="total=2.5"><outcome id="12" odds="1.9" active="1"/></market></odds>`)
+	assert.True(t, check().Accept)
+
+	producers(uof.ProducerStatusDown)
+	assert.Equal(t, RejectProducerDown, check().Reason)
+	producers(uof.ProducerStatusActive)
+
+	// generated bet stop is lifted only by the generated lift
+	apply(uof.NewBetStopMessage(uof.ProducerLiveOdds, "sr:match:1", nil, uof.MarketStatusSuspended, uof.BetStopOriginProducerDown))
+	assert.Equal(t, RejectBetStop, check().Reason)
+	apply(uof.NewBetStopLiftMessage(uof.ProducerLiveOdds, "sr:match:1", nil, uof.BetStopOriginProducerDown))
+	assert.True(t, check().Accept)
+	apply(oddsDeltaMessage(t, "bet_stop", "1", ""))
+	apply(uof.NewBetStopLiftMessage(uof.ProducerLiveOdds, "sr:match:1", nil, uof.BetStopOriginProducerDown))
+	assert.Equal(t, RejectBetStop, check().Reason)
+	odds(`<odds><market id="18" specifiers="total=2.5"><outcome id="12" odds="1.9" active="1"/></market></odds>`)
+
+	apply(uof.NewConnnectionMessage(uof.ConnectionStatusDown))
+	assert.Equal(t, RejectConnectionDown, check().Reason)
+	apply(uof.NewConnnectionMessage(uof.ConnectionStatusUp))
+	assert.True(t, check().Accept)
+
+	apply(oddsDeltaMessage(t, "bet_settlement", "1", `<outcomes><market id="18" specifiers="total=2.5"><outcome id="12" result="1"/></market></outcomes>`))
+	assert.Equal(t, RejectMarketNotActive, check().Reason)
+	assert.Equal(t, "market not active", check().Reason.String())
+}
+
+func TestBetAcceptanceLiftOrigin(t *testing.T) {
+	a := NewBetAcceptance(time.Hour)
+	a.now = func() int { return 0 }
+	s := newSuspension()
+	apply := func(m *uof.Message) {
+		a.apply(m)
+		if m.Is(uof.MessageTypeProducersChange) {
+			for _, g := range s.producersChange(m) {
+				a.apply(g)
+			}
+			return
+		}
+		s.oddsChange(m)
+	}
+	check := func(marketID int) Decision {
+		return a.Check("sr:match:1", marketID, "", 1)
+	}
+
+	apply(producersChangeMessage(uof.ProducerPrematch, uof.ProducerStatusActive))
+	apply(oddsDeltaMessage(t, "odds_change", "3", `<odds>
+		<market id="1"><outcome id="1" odds="1.5" active="1"/></market>
+		<market id="2"><outcome id="1" odds="1.5" active="1"/></market>
+		<market id="3"><outcome id="1" odds="1.5" active="1"/></market>
+	</odds>`))
+	apply(uof.NewBetStopMessage(uof.ProducerPrematch, "sr:match:1", []int{2}, uof.MarketStatusSuspended, uof.BetStopOriginHandover))
+	apply(uof.NewBetStopMessage(uof.ProducerPrematch, "sr:match:1", []int{3}, uof.MarketStatusSuspended, uof.BetStopOriginNextBetstop))
+	assert.True(t, check(1).Accept)
+
+	// producer down and back up lifts only its own suspension
+	apply(producersChangeMessage(uof.ProducerPrematch, uof.ProducerStatusDown))
+	assert.Equal(t, RejectBetStop, check(1).Reason)
+	apply(producersChangeMessage(uof.ProducerPrematch, uof.ProducerStatusActive))
+	assert.True(t, check(1).Accept)
+	assert.Equal(t, RejectBetStop, check(2).Reason)
+	assert.Equal(t, RejectBetStop, check(3).Reason)
+}
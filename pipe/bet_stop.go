@@ -37,6 +37,9 @@ func (b *betStop) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc cha
 	for m := range in {
 		switch m.Type {
 		case uof.MessageTypeBetStop:
+			if !unpackBody(m, errc, uof.MessageTypeBetStop) {
+				continue
+			}
 			b.enrich(m)
 		case uof.MessageTypeMarkets:
 			b.refresh(m)
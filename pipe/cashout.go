@@ -0,0 +1,152 @@
+package pipe
+
+import (
+	"sync"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+type cashoutAPI interface {
+	CashoutProbabilities(eventURN uof.URN) (*uof.CashoutProbabilities, []byte, error)
+	MarketCashoutProbabilities(eventURN uof.URN, marketID int) (*uof.CashoutProbabilities, []byte, error)
+	SpecifierCashoutProbabilities(eventURN uof.URN, marketID int, specifiers string) (*uof.CashoutProbabilities, []byte, error)
+}
+
+type cashoutRequest struct {
+	eventURN    uof.URN
+	marketID    int
+	specifiers  string
+	requestedAt int
+}
+
+// Cashout gets cashout probabilities from the api and emits them as
+// MessageTypeCashoutProbabilities messages. Probabilities are requested on
+// demand, by Request, and on odds change with markets available for cashout,
+// not more often than interval for the event.
+type Cashout struct {
+	interval  time.Duration
+	requests  chan cashoutRequest
+	em        *expireMap
+	rateLimit chan struct{}
+
+	api      cashoutAPI
+	errc     chan<- error
+	out      chan<- *uof.Message
+	subProcs *sync.WaitGroup
+}
+
+// NewCashout creates cashout stage. Zero interval disables requests on odds
+// change, probabilities are then got only by Request.
+func NewCashout(interval time.Duration) *Cashout {
+	return &Cashout{
+		interval:  interval,
+		requests:  make(chan cashoutRequest, 1024),
+		em:        newExpireMap(interval),
+		rateLimit: make(chan struct{}, ConcurentAPICallsLimit),
+		subProcs:  &sync.WaitGroup{},
+	}
+}
+
+// Request queues request for the event probabilities. Zero marketID requests
+// all event markets, empty specifiers all lines of the market. Returns false
+// if the request queue is full.
+func (c *Cashout) Request(eventURN uof.URN, marketID int, specifiers string) bool {
+	req := cashoutRequest{
+		eventURN:    eventURN,
+		marketID:    marketID,
+		specifiers:  specifiers,
+		requestedAt: uof.CurrentTimestamp(),
+	}
+	select {
+	case c.requests <- req:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stage returns pipe stage which uses api to get probabilities.
+func (c *Cashout) Stage(api cashoutAPI) InnerStage {
+	c.api = api
+	return StageWithSubProcessesSync(c.loop)
+}
+
+func (c *Cashout) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc chan<- error) *sync.WaitGroup {
+	c.errc, c.out = errc, out
+
+	done := make(chan struct{})
+	c.subProcs.Add(1)
+	go func() {
+		defer c.subProcs.Done()
+		for {
+			select {
+			case req := <-c.requests:
+				c.get(req)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for m := range in {
+		if !unpackBody(m, errc, uof.MessageTypeOddsChange) {
+			continue
+		}
+		out <- m
+		if c.interval > 0 && m.Is(uof.MessageTypeOddsChange) && cashoutAvailable(m.OddsChange) {
+			c.onOddsChange(m.EventURN, m.ReceivedAt)
+		}
+	}
+	close(done)
+	return c.subProcs
+}
+
+func cashoutAvailable(oc *uof.OddsChange) bool {
+	if oc == nil {
+		return false
+	}
+	for _, m := range oc.Markets {
+		if m.CashoutStatus != nil && *m.CashoutStatus == uof.CashoutStatusAvailable {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cashout) onOddsChange(eventURN uof.URN, requestedAt int) {
+	if eventURN == uof.NoURN || c.em.freshURN(eventURN) {
+		return
+	}
+	c.em.insertURN(eventURN)
+	c.get(cashoutRequest{eventURN: eventURN, requestedAt: requestedAt})
+}
+
+func (c *Cashout) get(req cashoutRequest) {
+	c.subProcs.Add(1)
+	go func() {
+		defer c.subProcs.Done()
+		c.rateLimit <- struct{}{}
+		defer func() { <-c.rateLimit }()
+
+		var cp *uof.CashoutProbabilities
+		var raw []byte
+		var err error
+		switch {
+		case req.marketID == 0:
+			cp, raw, err = c.api.CashoutProbabilities(req.eventURN)
+		case req.specifiers == "":
+			cp, raw, err = c.api.MarketCashoutProbabilities(req.eventURN, req.marketID)
+		default:
+			cp, raw, err = c.api.SpecifierCashoutProbabilities(req.eventURN, req.marketID, req.specifiers)
+		}
+		if err != nil {
+			if req.marketID == 0 && !uof.IsApiNotFoundErr(err) {
+				c.em.removeURN(req.eventURN)
+			}
+			c.errc <- err
+			return
+		}
+		c.out <- uof.NewCashoutProbabilitiesMessage(cp, req.requestedAt, raw)
+	}()
+}
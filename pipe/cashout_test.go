@@ -0,0 +1,85 @@
+package pipe
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cashoutAPIMock struct {
+	requests []string
+	sync.Mutex
+}
+
+func (m *cashoutAPIMock) get(req string) (*uof.CashoutProbabilities, []byte, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.requests = append(m.requests, req)
+	return &uof.CashoutProbabilities{EventURN: "sr:match:123", EventID: 123}, nil, nil
+}
+
+func (m *cashoutAPIMock) CashoutProbabilities(eventURN uof.URN) (*uof.CashoutProbabilities, []byte, error) {
+	return m.get(eventURN.String())
+}
+
+func (m *cashoutAPIMock) MarketCashoutProbabilities(eventURN uof.URN, marketID int) (*uof.CashoutProbabilities, []byte, error) {
+	return m.get(fmt.Sprintf("%s/%d", eventURN, marketID))
+}
+
+func (m *cashoutAPIMock) SpecifierCashoutProbabilities(eventURN uof.URN, marketID int, specifiers string) (*uof.CashoutProbabilities, []byte, error) {
+	return m.get(fmt.Sprintf("%s/%d/%s", eventURN, marketID, specifiers))
+}
+
+func TestCashoutPipe(t *testing.T) {
+	a := &cashoutAPIMock{}
+	c := NewCashout(time.Hour)
+	in := make(chan *uof.Message)
+	out, _ := c.Stage(a)(in)
+
+	// odds change without markets available for cashout
+	m := oddsChangeMessage(t)
+	in <- m
+	assert.Equal(t, m, <-out)
+
+	available := uof.CashoutStatusAvailable
+	m = oddsChangeMessage(t)
+	require.NoError(t, m.Unpack())
+	m.OddsChange.Markets[0].CashoutStatus = &available
+	in <- m
+	assert.Equal(t, m, <-out)
+	om := <-out
+	assert.Equal(t, uof.MessageTypeCashoutProbabilities, om.Type)
+	assert.Equal(t, m.ReceivedAt, om.RequestedAt)
+
+	// throttled by interval
+	in <- m
+	assert.Equal(t, m, <-out)
+
+	assert.True(t, c.Request("sr:match:123", 18, "total=2.5"))
+	om = <-out
+	assert.Equal(t, uof.MessageTypeCashoutProbabilities, om.Type)
+	assert.True(t, c.Request("sr:match:123", 18, ""))
+	<-out
+
+	// event with urn type unknown to the sdk is also throttled by urn
+	for _, urn := range []uof.URN{"sr:zdero:1", "sr:zdero:1", "sr:zdero:2"} {
+		m = oddsChangeMessage(t)
+		require.NoError(t, m.Unpack())
+		m.OddsChange.Markets[0].CashoutStatus = &available
+		m.EventURN = urn
+		in <- m
+		<-out
+	}
+	<-out
+	<-out
+
+	close(in)
+	for range out {
+	}
+	assert.ElementsMatch(t, []string{"sr:match:1234", "sr:match:123/18/total=2.5", "sr:match:123/18", "sr:zdero:1", "sr:zdero:2"}, a.requests)
+}
@@ -36,6 +36,9 @@ func (p *competitor) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc
 	p.errc, p.out = errc, out
 
 	for m := range in {
+		if !unpackBody(m, errc, uof.MessageTypeOddsChange) {
+			continue
+		}
 		out <- m
 		if m.Is(uof.MessageTypeOddsChange) {
 			m.OddsChange.EachCompetitor(func(competitorID int) {
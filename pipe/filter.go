@@ -0,0 +1,142 @@
+package pipe
+
+import (
+	"path"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+// Filter is predicate on message.
+type Filter func(m *uof.Message) bool
+
+// Types matches messages of any of the types.
+func Types(types ...uof.MessageType) Filter {
+	return func(m *uof.Message) bool {
+		for _, t := range types {
+			if m.Type == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Kinds matches messages of any of the kinds (event, lexicon, system).
+func Kinds(kinds ...uof.MessageKind) Filter {
+	return func(m *uof.Message) bool {
+		for _, k := range kinds {
+			if m.Type.Kind() == k {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Producers matches messages from any of the producers.
+func Producers(producers ...uof.Producer) Filter {
+	return func(m *uof.Message) bool {
+		for _, p := range producers {
+			if m.Producer == p {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Sports matches messages for any of the sports.
+func Sports(sportIDs ...int) Filter {
+	return func(m *uof.Message) bool {
+		for _, id := range sportIDs {
+			if m.SportID == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Scopes matches messages with any of the scopes.
+func Scopes(scopes ...uof.MessageScope) Filter {
+	return func(m *uof.Message) bool {
+		for _, s := range scopes {
+			if m.Scope == s {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Priorities matches messages with any of the priorities.
+func Priorities(priorities ...uof.MessagePriority) Filter {
+	return func(m *uof.Message) bool {
+		for _, p := range priorities {
+			if m.Priority == p {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Langs matches messages in any of the languages.
+func Langs(langs ...uof.Lang) Filter {
+	return func(m *uof.Message) bool {
+		for _, l := range langs {
+			if m.Lang == l {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// EventURNs matches messages with event urn matching any of the shell
+// patterns. For example "sr:match:*" or "vf:*". Pattern syntax is the same as
+// in path.Match. Malformed pattern matches nothing.
+func EventURNs(patterns ...string) Filter {
+	return func(m *uof.Message) bool {
+		if m.EventURN.Empty() {
+			return false
+		}
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, m.EventURN.String()); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// All matches messages matched by all of the filters.
+func All(filters ...Filter) Filter {
+	return func(m *uof.Message) bool {
+		for _, f := range filters {
+			if !f(m) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Any matches messages matched by any of the filters.
+func Any(filters ...Filter) Filter {
+	return func(m *uof.Message) bool {
+		for _, f := range filters {
+			if f(m) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches messages not matched by the filter.
+func Not(filter Filter) Filter {
+	return func(m *uof.Message) bool {
+		return !filter(m)
+	}
+}
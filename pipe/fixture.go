@@ -51,8 +51,12 @@ func (f *fixture) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc cha
 		f.getFixture(u, uof.CurrentTimestamp())
 	}
 	for m := range in {
+		// virtual odds change urn is in the header, body is not needed
+		if !unpackBody(m, errc, uof.MessageTypeFixtureChange) {
+			continue
+		}
 		out <- m
-		if u := f.eventURN(m); u != uof.NoURN {
+		if u := fixtureEventURN(m); u != uof.NoURN {
 			f.getFixture(u, m.ReceivedAt)
 		}
 	}
@@ -60,7 +64,8 @@ func (f *fixture) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc cha
 	return f.subProcs
 }
 
-func (f *fixture) eventURN(m *uof.Message) uof.URN {
+// fixtureEventURN returns urn of the event for which fixture should be fetched
+func fixtureEventURN(m *uof.Message) uof.URN {
 	if m.Producer.Virtuals() && m.Is(uof.MessageTypeOddsChange) {
 		return m.EventURN
 	}
@@ -92,8 +97,11 @@ func (f *fixture) preloadLoop(in <-chan *uof.Message) []uof.URN {
 			if !ok {
 				return urns
 			}
+			if !unpackBody(m, f.errc, uof.MessageTypeFixtureChange) {
+				continue
+			}
 			f.out <- m
-			if u := f.eventURN(m); u != uof.NoURN {
+			if u := fixtureEventURN(m); u != uof.NoURN {
 				urns = append(urns, u)
 			}
 		case <-done:
@@ -114,7 +122,7 @@ func (f *fixture) preload() {
 			in, errc := f.api.Fixtures(lang, f.preloadTo)
 			for x := range in {
 				f.out <- uof.NewFixtureMessage(lang, x, uof.CurrentTimestamp(), nil)
-				f.em.insert(x.URN.EventID())
+				f.em.insertURN(x.URN)
 			}
 			for err := range errc {
 				f.errc <- err
@@ -125,11 +133,10 @@ func (f *fixture) preload() {
 }
 
 func (f *fixture) getFixture(eventURN uof.URN, receivedAt int) {
-	key := eventURN.EventID()
-	if f.em.fresh(key) {
+	if f.em.freshURN(eventURN) {
 		return
 	}
-	f.em.insert(key)
+	f.em.insertURN(eventURN)
 
 	f.subProcs.Add(len(f.languages))
 	for _, lang := range f.languages {
@@ -149,7 +156,7 @@ func (f *fixture) getFixture(eventURN uof.URN, receivedAt int) {
 				x, raw, err := f.api.Fixture(lang, eventURN)
 				if err != nil {
 					if !uof.IsApiNotFoundErr(err) {
-						f.em.remove(key)
+						f.em.removeURN(eventURN)
 					}
 					f.errc <- err
 					return
@@ -0,0 +1,163 @@
+package pipe
+
+import (
+	"sort"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+type handoverKey struct {
+	eventURN uof.URN
+	marketID int
+}
+
+// pendingHandover is prematch market handed over to the live producer, waiting
+// for the live odds change.
+type pendingHandover struct {
+	sportID  int
+	deadline time.Time
+}
+
+type liveMarkets struct {
+	ids       map[int]struct{}
+	updatedAt time.Time
+}
+
+type handover struct {
+	timeout time.Duration
+	live    map[uof.URN]*liveMarkets // market ids seen from the live producer
+	pending map[handoverKey]*pendingHandover
+	out     chan<- *uof.Message
+}
+
+// Handover handles markets which prematch producer hands over to the live
+// odds producer (MarketStatusHandedOver). If the live odds change for the
+// market is not received yet, SDK generated bet stop with
+// MarketStatusSuspended is emitted for the prematch market. If live odds
+// change does not arrive in timeout, SDK generated bet stop with
+// MarketStatusInactive deactivates the prematch market.
+func Handover(timeout time.Duration) InnerStage {
+	h := newHandover(timeout)
+	return Stage(h.loop)
+}
+
+func newHandover(timeout time.Duration) *handover {
+	return &handover{
+		timeout: timeout,
+		live:    make(map[uof.URN]*liveMarkets),
+		pending: make(map[handoverKey]*pendingHandover),
+	}
+}
+
+func (h *handover) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc chan<- error) {
+	h.out = out
+	ticker := time.NewTicker(h.checkInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case m, ok := <-in:
+			if !ok {
+				return
+			}
+			if !unpackBody(m, errc, uof.MessageTypeOddsChange) {
+				continue
+			}
+			h.handle(m, time.Now())
+		case now := <-ticker.C:
+			h.release(now)
+		}
+	}
+}
+
+// how often to check for expired hand overs
+func (h *handover) checkInterval() time.Duration {
+	i := h.timeout / 4
+	if i < time.Millisecond {
+		i = time.Millisecond
+	}
+	return i
+}
+
+func (h *handover) handle(m *uof.Message, now time.Time) {
+	h.out <- m
+	if m.Type != uof.MessageTypeOddsChange || m.OddsChange == nil || m.EventURN == uof.NoURN {
+		return
+	}
+	switch m.Producer {
+	case uof.ProducerLiveOdds:
+		h.liveOddsChange(m, now)
+	case uof.ProducerPrematch:
+		h.prematchOddsChange(m, now)
+	}
+}
+
+func (h *handover) liveOddsChange(m *uof.Message, now time.Time) {
+	lm, ok := h.live[m.EventURN]
+	if !ok {
+		lm = &liveMarkets{ids: make(map[int]struct{})}
+		h.live[m.EventURN] = lm
+	}
+	lm.updatedAt = now
+	for _, mkt := range m.OddsChange.Markets {
+		lm.ids[mkt.ID] = struct{}{}
+		delete(h.pending, handoverKey{eventURN: m.EventURN, marketID: mkt.ID})
+	}
+}
+
+func (h *handover) prematchOddsChange(m *uof.Message, now time.Time) {
+	var suspend []int
+	for _, mkt := range m.OddsChange.Markets {
+		if mkt.Status != uof.MarketStatusHandedOver {
+			continue
+		}
+		if lm, ok := h.live[m.EventURN]; ok {
+			if _, ok := lm.ids[mkt.ID]; ok {
+				continue // live producer already took over
+			}
+		}
+		k := handoverKey{eventURN: m.EventURN, marketID: mkt.ID}
+		if _, ok := h.pending[k]; ok {
+			continue
+		}
+		h.pending[k] = &pendingHandover{sportID: m.SportID, deadline: now.Add(h.timeout)}
+		suspend = append(suspend, mkt.ID)
+	}
+	if len(suspend) == 0 {
+		return
+	}
+	sort.Ints(suspend)
+	g := uof.NewBetStopMessage(uof.ProducerPrematch, m.EventURN, suspend, uof.MarketStatusSuspended, uof.BetStopOriginHandover)
+	g.SportID = m.SportID
+	h.out <- g
+}
+
+// release deactivates markets not taken over by the live producer until the
+// deadline
+func (h *handover) release(now time.Time) {
+	var expired []handoverKey
+	for k, p := range h.pending {
+		if !now.Before(p.deadline) {
+			expired = append(expired, k)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		if expired[i].eventURN != expired[j].eventURN {
+			return expired[i].eventURN < expired[j].eventURN
+		}
+		return expired[i].marketID < expired[j].marketID
+	})
+	for _, k := range expired {
+		g := uof.NewBetStopMessage(uof.ProducerPrematch, k.eventURN, []int{k.marketID}, uof.MarketStatusInactive, uof.BetStopOriginHandover)
+		g.SportID = h.pending[k].sportID
+		h.out <- g
+		delete(h.pending, k)
+	}
+
+	// forget live markets of the events without updates
+	for urn, lm := range h.live {
+		if now.Sub(lm.updatedAt) > eventStateTTL*time.Millisecond {
+			delete(h.live, urn)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package pipe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandover(t *testing.T) {
+	h := newHandover(time.Minute)
+	out := make(chan *uof.Message, 16)
+	h.out = out
+	now := time.Now()
+
+	handedOver := `<odds><market id="1" status="-2"/><market id="2" status="-2"/><market id="3"/></odds>`
+	// live odds already received for market 1
+	h.handle(oddsDeltaMessage(t, "odds_change", "1", `<odds><market id="1"><outcome id="1" odds="1.5"/></market></odds>`), now)
+	<-out
+	h.handle(oddsDeltaMessage(t, "odds_change", "3", handedOver), now)
+	<-out
+	g := <-out
+	assert.True(t, g.Generated)
+	assert.Equal(t, uof.ProducerPrematch, g.Producer)
+	assert.Equal(t, []int{2}, g.BetStop.MarketIDs)
+	assert.Equal(t, uof.MarketStatusSuspended, g.BetStop.Status)
+	assert.Len(t, h.pending, 1)
+
+	// repeated hand over is not suspended again
+	h.handle(oddsDeltaMessage(t, "odds_change", "3", handedOver), now)
+	<-out
+	assert.Len(t, out, 0)
+
+	// live producer never took over
+	h.release(now.Add(time.Second))
+	assert.Len(t, out, 0)
+	h.release(now.Add(time.Minute))
+	g = <-out
+	assert.Equal(t, []int{2}, g.BetStop.MarketIDs)
+	assert.Equal(t, uof.MarketStatusInactive, g.BetStop.Status)
+	assert.Len(t, h.pending, 0)
+
+	// live odds change clears pending hand over
+	m := oddsDeltaMessage(t, "odds_change", "3", `<odds><market id="4" status="-2"/></odds>`)
+	h.handle(m, now)
+	<-out
+	<-out
+	h.handle(oddsDeltaMessage(t, "odds_change", "1", `<odds><market id="4"><outcome id="1" odds="1.5"/></market></odds>`), now)
+	<-out
+	h.release(now.Add(time.Hour))
+	assert.Len(t, out, 0)
+}
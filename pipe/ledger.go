@@ -0,0 +1,460 @@
+package pipe
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+// LedgerChange is the type of the settlement ledger entry.
+type LedgerChange int8
+
+const (
+	LedgerSettle LedgerChange = iota + 1
+	LedgerRollbackSettle
+	LedgerCancel
+	LedgerRollbackCancel
+)
+
+func (c LedgerChange) String() string {
+	switch c {
+	case LedgerSettle:
+		return "settle"
+	case LedgerRollbackSettle:
+		return "rollback settle"
+	case LedgerCancel:
+		return "cancel"
+	case LedgerRollbackCancel:
+		return "rollback cancel"
+	}
+	return "unknown"
+}
+
+// LedgerEntry is one change in the settlement ledger. Settlement entries are
+// for the outcome, cancel entries are for the whole market and have zero
+// OutcomeID.
+type LedgerEntry struct {
+	Seq       int
+	EventURN  uof.URN
+	MarketID  int
+	LineID    int
+	OutcomeID int
+	Change    LedgerChange
+	// settlement, for settle entries
+	Outcome   *uof.BetSettlementOutcome
+	Certainty *int8
+	// cancel window, for cancel and rollback cancel entries
+	Cancel *uof.BetCancel
+	// source message
+	Producer  uof.Producer
+	RequestID *int
+	Timestamp int
+	// ReceivedAt of the message which made the change, never before
+	// AppliedAt of the previous entry
+	AppliedAt int
+}
+
+// OutcomeSettlement is the current settlement state of the outcome.
+type OutcomeSettlement struct {
+	// nil if outcome is not settled
+	Outcome   *uof.BetSettlementOutcome
+	Certainty *int8
+	// bet cancels of the outcome market which are not rolled back
+	Cancels []uof.BetCancel
+}
+
+type ledgerMarketKey struct {
+	marketID int
+	lineID   int
+}
+
+type ledgerOutcome struct {
+	outcome   uof.BetSettlementOutcome
+	certainty *int8
+}
+
+type ledgerMarket struct {
+	outcomes map[int]ledgerOutcome
+	cancels  []uof.BetCancel
+}
+
+// SettlementLedger applies bet settlement, bet cancel and their rollback
+// messages. Messages can be applied more than once (recovery, replay), only
+// effective changes are recorded. Each change is kept in the audit trail.
+// Audit trail entries older than retention are pruned, settlement state is
+// kept until Forget. State is built by the Stage, queries can be called
+// concurrently from any goroutine.
+type SettlementLedger struct {
+	sync.RWMutex
+	events    map[uof.URN]map[ledgerMarketKey]*ledgerMarket
+	rollbacks map[uof.URN][]uof.RollbackBetCancel // rollback bet cancels by event
+	entries   []LedgerEntry                       // ordered by Seq and AppliedAt
+	seq       int
+	retention int // in milliseconds
+}
+
+// NewSettlementLedger creates empty ledger which keeps audit trail entries
+// for retention. Zero retention keeps all entries.
+func NewSettlementLedger(retention time.Duration) *SettlementLedger {
+	return &SettlementLedger{
+		events:    make(map[uof.URN]map[ledgerMarketKey]*ledgerMarket),
+		rollbacks: make(map[uof.URN][]uof.RollbackBetCancel),
+		retention: int(retention / time.Millisecond),
+	}
+}
+
+// Stage returns pipe stage which applies messages to the ledger.
+func (l *SettlementLedger) Stage() InnerStage {
+	return Stage(func(in <-chan *uof.Message, out chan<- *uof.Message, errc chan<- error) {
+		for m := range in {
+			if !unpackBody(m, errc, uof.MessageTypeBetSettlement, uof.MessageTypeRollbackBetSettlement,
+				uof.MessageTypeBetCancel, uof.MessageTypeRollbackBetCancel) {
+				continue
+			}
+			l.Apply(m)
+			out <- m
+		}
+	})
+}
+
+// Apply applies settlement related message to the ledger. Returns entries
+// recorded for the message, nil if message made no change.
+func (l *SettlementLedger) Apply(m *uof.Message) []LedgerEntry {
+	l.Lock()
+	defer l.Unlock()
+	n := len(l.entries)
+	switch m.Type {
+	case uof.MessageTypeBetSettlement:
+		if m.BetSettlement != nil {
+			l.settle(m)
+		}
+	case uof.MessageTypeRollbackBetSettlement:
+		if m.RollbackBetSettlement != nil {
+			l.rollbackSettle(m)
+		}
+	case uof.MessageTypeBetCancel:
+		if m.BetCancel != nil {
+			l.cancel(m)
+		}
+	case uof.MessageTypeRollbackBetCancel:
+		if m.RollbackBetCancel != nil {
+			l.rollbackCancel(m)
+		}
+	}
+	if len(l.entries) == n {
+		return nil
+	}
+	entries := append([]LedgerEntry(nil), l.entries[n:]...)
+	l.prune()
+	return entries
+}
+
+// prune removes entries older than retention from the audit trail
+func (l *SettlementLedger) prune() {
+	if l.retention == 0 || len(l.entries) == 0 {
+		return
+	}
+	before := l.entries[len(l.entries)-1].AppliedAt - l.retention
+	if i := l.search(before - 1); i > 0 {
+		l.entries = l.entries[i:]
+	}
+}
+
+// search returns position of the first entry applied after t
+func (l *SettlementLedger) search(t int) int {
+	return sort.Search(len(l.entries), func(i int) bool {
+		return l.entries[i].AppliedAt > t
+	})
+}
+
+func (l *SettlementLedger) market(eventURN uof.URN, k ledgerMarketKey, create bool) *ledgerMarket {
+	markets, ok := l.events[eventURN]
+	if !ok {
+		if !create {
+			return nil
+		}
+		markets = make(map[ledgerMarketKey]*ledgerMarket)
+		l.events[eventURN] = markets
+	}
+	lm, ok := markets[k]
+	if !ok && create {
+		lm = &ledgerMarket{outcomes: make(map[int]ledgerOutcome)}
+		markets[k] = lm
+	}
+	return lm
+}
+
+func (l *SettlementLedger) record(m *uof.Message, e LedgerEntry, requestID *int, timestamp int) {
+	l.seq++
+	e.Seq = l.seq
+	e.EventURN = m.EventURN
+	e.Producer = m.Producer
+	e.RequestID = requestID
+	e.Timestamp = timestamp
+	e.AppliedAt = m.ReceivedAt
+	if n := len(l.entries); n > 0 && e.AppliedAt < l.entries[n-1].AppliedAt {
+		e.AppliedAt = l.entries[n-1].AppliedAt
+	}
+	l.entries = append(l.entries, e)
+}
+
+func (l *SettlementLedger) settle(m *uof.Message) {
+	bs := m.BetSettlement
+	for _, mkt := range bs.Markets {
+		lm := l.market(m.EventURN, ledgerMarketKey{marketID: mkt.ID, lineID: mkt.LineID}, true)
+		for _, o := range mkt.Outcomes {
+			prev, ok := lm.outcomes[o.ID]
+			if ok && prev.outcome == o && int8PtrEqual(prev.certainty, bs.Certainty) {
+				continue
+			}
+			lm.outcomes[o.ID] = ledgerOutcome{outcome: o, certainty: bs.Certainty}
+			outcome := o
+			l.record(m, LedgerEntry{
+				MarketID:  mkt.ID,
+				LineID:    mkt.LineID,
+				OutcomeID: o.ID,
+				Change:    LedgerSettle,
+				Outcome:   &outcome,
+				Certainty: bs.Certainty,
+			}, bs.RequestID, bs.Timestamp)
+		}
+	}
+}
+
+func (l *SettlementLedger) rollbackSettle(m *uof.Message) {
+	rb := m.RollbackBetSettlement
+	for _, mkt := range rb.Markets {
+		lm := l.market(m.EventURN, ledgerMarketKey{marketID: mkt.ID, lineID: mkt.LineID}, false)
+		if lm == nil {
+			continue
+		}
+		var ids []int
+		for id := range lm.outcomes {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			delete(lm.outcomes, id)
+			l.record(m, LedgerEntry{
+				MarketID:  mkt.ID,
+				LineID:    mkt.LineID,
+				OutcomeID: id,
+				Change:    LedgerRollbackSettle,
+			}, rb.RequestID, rb.Timestamp)
+		}
+	}
+}
+
+func (l *SettlementLedger) cancel(m *uof.Message) {
+	bc := m.BetCancel
+	for _, mkt := range bc.Markets {
+		lm := l.market(m.EventURN, ledgerMarketKey{marketID: mkt.ID, lineID: mkt.LineID}, true)
+		c := *bc
+		c.Markets = []uof.BetCancelMarket{mkt}
+		if sameCancel(lm.cancels, c) >= 0 {
+			continue
+		}
+		lm.cancels = append(lm.cancels, c)
+		l.record(m, LedgerEntry{
+			MarketID: mkt.ID,
+			LineID:   mkt.LineID,
+			Change:   LedgerCancel,
+			Cancel:   &c,
+		}, bc.RequestID, bc.Timestamp)
+	}
+}
+
+func (l *SettlementLedger) rollbackCancel(m *uof.Message) {
+	rb := m.RollbackBetCancel
+	l.rollbacks[m.EventURN] = appendRollback(l.rollbacks[m.EventURN], *rb)
+	for _, mkt := range rb.Markets {
+		lm := l.market(m.EventURN, ledgerMarketKey{marketID: mkt.ID, lineID: mkt.LineID}, false)
+		if lm == nil {
+			continue
+		}
+		i := sameCancel(lm.cancels, uof.BetCancel{StartTime: rb.StartTime, EndTime: rb.EndTime})
+		if i < 0 {
+			continue
+		}
+		c := lm.cancels[i]
+		lm.cancels = append(lm.cancels[:i], lm.cancels[i+1:]...)
+		l.record(m, LedgerEntry{
+			MarketID: mkt.ID,
+			LineID:   mkt.LineID,
+			Change:   LedgerRollbackCancel,
+			Cancel:   &c,
+		}, rb.RequestID, rb.Timestamp)
+	}
+}
+
+// appendRollback appends rollback which is not already in rollbacks, repeated
+// messages are applied once
+func appendRollback(rollbacks []uof.RollbackBetCancel, rb uof.RollbackBetCancel) []uof.RollbackBetCancel {
+	for _, p := range rollbacks {
+		if intPtrEqual(p.StartTime, rb.StartTime) && intPtrEqual(p.EndTime, rb.EndTime) &&
+			reflect.DeepEqual(p.Markets, rb.Markets) {
+			return rollbacks
+		}
+	}
+	return append(rollbacks, rb)
+}
+
+// sameCancel finds index of the cancel with the same time window, -1 if not
+// found
+func sameCancel(cancels []uof.BetCancel, c uof.BetCancel) int {
+	for i, p := range cancels {
+		if intPtrEqual(p.StartTime, c.StartTime) && intPtrEqual(p.EndTime, c.EndTime) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Settlement returns current settlement of the outcome. Market is identified
+// by id and specifiers as written in the feed. Returns false if there is
+// nothing in the ledger for the outcome market.
+func (l *SettlementLedger) Settlement(eventURN uof.URN, marketID int, specifiers string, outcomeID int) (OutcomeSettlement, bool) {
+	l.RLock()
+	defer l.RUnlock()
+	lm := l.market(eventURN, ledgerMarketKey{marketID: marketID, lineID: uof.LineID(specifiers)}, false)
+	if lm == nil {
+		return OutcomeSettlement{}, false
+	}
+	var s OutcomeSettlement
+	if o, ok := lm.outcomes[outcomeID]; ok {
+		outcome := o.outcome
+		s.Outcome, s.Certainty = &outcome, o.certainty
+	}
+	s.Cancels = append(s.Cancels, lm.cancels...)
+	return s, true
+}
+
+// BetCancelStatus is the result of the bet cancel evaluation.
+type BetCancelStatus struct {
+	Cancelled bool
+	// bet cancel message which cancels the bet
+	Cancel *uof.BetCancel
+	// event which supersedes the cancelled one, NoURN if the cancel is not
+	// superseded
+	SupersededBy uof.URN
+}
+
+// Cancelled evaluates bet cancels of the market for the bet placed at
+// placedAt (timestamp in milliseconds). Market is identified by id and
+// specifiers as written in the feed. Rolled back cancels are not in the
+// ledger any more. Superseding event has different markets, the cancel stays
+// in effect unless the superseding event has a rollback bet cancel covering
+// the bet, then the chain is followed from that event.
+func (l *SettlementLedger) Cancelled(eventURN uof.URN, marketID int, specifiers string, placedAt int) BetCancelStatus {
+	l.RLock()
+	defer l.RUnlock()
+	k := ledgerMarketKey{marketID: marketID, lineID: uof.LineID(specifiers)}
+	c := l.affectingCancel(eventURN, k, placedAt)
+	if c == nil {
+		return BetCancelStatus{}
+	}
+	s := BetCancelStatus{Cancelled: true, Cancel: c}
+	visited := map[uof.URN]bool{eventURN: true}
+	for c != nil && c.SupercededBy != nil && *c.SupercededBy != "" {
+		urn := uof.URN(*c.SupercededBy)
+		if visited[urn] {
+			break
+		}
+		visited[urn] = true
+		s.SupersededBy = urn
+		if !l.rolledBack(urn, placedAt) {
+			break
+		}
+		s.Cancelled, s.Cancel = false, nil
+		// superseding event can be cancelled and superseded again
+		if c = l.eventCancel(urn, placedAt); c != nil {
+			s.Cancelled, s.Cancel = true, c
+		}
+	}
+	return s
+}
+
+// rolledBack reports whether the event has rollback bet cancel covering the
+// bet placed at placedAt on any of its markets
+func (l *SettlementLedger) rolledBack(eventURN uof.URN, placedAt int) bool {
+	for _, rb := range l.rollbacks[eventURN] {
+		for _, mkt := range rb.Markets {
+			if rb.Affects(mkt.ID, mkt.LineID, placedAt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// eventCancel finds cancel of any event market affecting the bet placed at
+// placedAt
+func (l *SettlementLedger) eventCancel(eventURN uof.URN, placedAt int) *uof.BetCancel {
+	var keys []ledgerMarketKey
+	for k := range l.events[eventURN] {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].marketID != keys[j].marketID {
+			return keys[i].marketID < keys[j].marketID
+		}
+		return keys[i].lineID < keys[j].lineID
+	})
+	for _, k := range keys {
+		if c := l.affectingCancel(eventURN, k, placedAt); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+func (l *SettlementLedger) affectingCancel(eventURN uof.URN, k ledgerMarketKey, placedAt int) *uof.BetCancel {
+	lm := l.market(eventURN, k, false)
+	if lm == nil {
+		return nil
+	}
+	for i := range lm.cancels {
+		if c := &lm.cancels[i]; c.Affects(k.marketID, k.lineID, placedAt) {
+			cc := *c
+			return &cc
+		}
+	}
+	return nil
+}
+
+// Since returns ledger entries applied after t (ReceivedAt of the message) in
+// the order they are recorded. Entries pruned by retention are not returned.
+func (l *SettlementLedger) Since(t int) []LedgerEntry {
+	l.RLock()
+	defer l.RUnlock()
+	i := l.search(t)
+	if i == len(l.entries) {
+		return nil
+	}
+	return append([]LedgerEntry(nil), l.entries[i:]...)
+}
+
+// Forget removes state and audit trail of the event.
+func (l *SettlementLedger) Forget(eventURN uof.URN) {
+	l.Lock()
+	defer l.Unlock()
+	delete(l.events, eventURN)
+	delete(l.rollbacks, eventURN)
+	entries := l.entries[:0]
+	for _, e := range l.entries {
+		if e.EventURN != eventURN {
+			entries = append(entries, e)
+		}
+	}
+	l.entries = entries
+}
+
+func int8PtrEqual(a, b *int8) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
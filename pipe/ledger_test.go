@@ -0,0 +1,158 @@
+package pipe
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettlementLedger(t *testing.T) {
+	l := NewSettlementLedger(0)
+	apply := func(typ, body string, receivedAt int) []LedgerEntry {
+		m := oddsDeltaMessage(t, typ, "1", body)
+		m.ReceivedAt = receivedAt
+		return l.Apply(m)
+	}
+	settlement := `<outcomes><market id="18" specifiers="total=2.5"><outcome id="12" result="1"/><outcome id="13" result="0"/></market></outcomes>`
+
+	entries := apply("bet_settlement", settlement, 1)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, LedgerSettle, entries[0].Change)
+	assert.Equal(t, uof.URN("sr:match:1"), entries[0].EventURN)
+	assert.Equal(t, uof.ProducerLiveOdds, entries[0].Producer)
+	// repeated settlement is not recorded
+	assert.Nil(t, apply("bet_settlement", settlement, 2))
+
+	s, ok := l.Settlement("sr:match:1", 18, "total=2.5", 12)
+	assert.True(t, ok)
+	assert.Equal(t, uof.OutcomeResultWin, s.Outcome.Result)
+	_, ok = l.Settlement("sr:match:1", 18, "total=3.5", 12)
+	assert.False(t, ok)
+
+	// rollback and resettlement
+	entries = apply("rollback_bet_settlement", `<market id="18" specifiers="total=2.5"/>`, 3)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, LedgerRollbackSettle, entries[0].Change)
+	assert.Nil(t, apply("rollback_bet_settlement", `<market id="18" specifiers="total=2.5"/>`, 4))
+	s, _ = l.Settlement("sr:match:1", 18, "total=2.5", 12)
+	assert.Nil(t, s.Outcome)
+	assert.Len(t, apply("bet_settlement", settlement, 5), 2)
+
+	// cancel and rollback cancel
+	cancel := `<market id="18" specifiers="total=2.5"/>`
+	entries = apply("bet_cancel", cancel, 6)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, LedgerCancel, entries[0].Change)
+	assert.Equal(t, 0, entries[0].OutcomeID)
+	assert.Nil(t, apply("bet_cancel", cancel, 7))
+	s, _ = l.Settlement("sr:match:1", 18, "total=2.5", 12)
+	assert.Len(t, s.Cancels, 1)
+	assert.Len(t, apply("rollback_bet_cancel", cancel, 8), 1)
+	assert.Nil(t, apply("rollback_bet_cancel", cancel, 9))
+
+	// audit trail
+	changes := func(entries []LedgerEntry) []LedgerChange {
+		var c []LedgerChange
+		for _, e := range entries {
+			c = append(c, e.Change)
+		}
+		return c
+	}
+	assert.Equal(t, []LedgerChange{LedgerSettle, LedgerSettle, LedgerCancel, LedgerRollbackCancel}, changes(l.Since(3)))
+	assert.Len(t, l.Since(0), 8)
+	assert.Equal(t, 8, l.Since(7)[0].Seq)
+
+	l.Forget("sr:match:1")
+	assert.Len(t, l.Since(0), 0)
+	_, ok = l.Settlement("sr:match:1", 18, "total=2.5", 12)
+	assert.False(t, ok)
+}
+
+func TestSettlementLedgerRetention(t *testing.T) {
+	l := NewSettlementLedger(time.Second)
+	apply := func(outcomeID string, receivedAt int) {
+		m := oddsDeltaMessage(t, "bet_settlement", "1", `<outcomes><market id="1"><outcome id="`+outcomeID+`" result="1"/></market></outcomes>`)
+		m.ReceivedAt = receivedAt
+		l.Apply(m)
+	}
+	apply("1", 1000)
+	apply("2", 1500)
+	// replayed message is not applied before the previous entry
+	apply("3", 1200)
+	assert.Equal(t, 1500, l.Since(0)[2].AppliedAt)
+	assert.Len(t, l.Since(1000), 2)
+	assert.Len(t, l.Since(1500), 0)
+
+	// entries older than retention are pruned, state is kept
+	apply("4", 2600)
+	entries := l.Since(0)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 4, entries[0].Seq)
+	s, ok := l.Settlement("sr:match:1", 1, "", 1)
+	assert.True(t, ok)
+	assert.Equal(t, uof.OutcomeResultWin, s.Outcome.Result)
+}
+
+func TestSettlementLedgerCancelled(t *testing.T) {
+	l := NewSettlementLedger(0)
+	apply := func(event, typ, attrs, body string) {
+		m, err := uof.NewQueueMessage("hi.-.live."+typ+".1.sr:match."+strings.TrimPrefix(event, "sr:match:")+".-",
+			[]byte(`<`+typ+` product="1" event_id="`+event+`" timestamp="1" `+attrs+`>`+body+`</`+typ+`>`))
+		assert.NoError(t, err)
+		l.Apply(m)
+	}
+	market := `<market id="18" specifiers="total=2.5"/>`
+
+	assert.False(t, l.Cancelled("sr:match:1", 18, "total=2.5", 1500).Cancelled)
+	apply("sr:match:1", "bet_cancel", `start_time="1000" end_time="2000" superceded_by="sr:match:2"`, market)
+	apply("sr:match:2", "bet_cancel", `start_time="1000" superceded_by="sr:match:3"`, market)
+	apply("sr:match:3", "bet_cancel", `superceded_by="sr:match:1"`, market)
+
+	s := l.Cancelled("sr:match:1", 18, "total=2.5", 1500)
+	assert.True(t, s.Cancelled)
+	// superseding event without rollback keeps the original cancel
+	assert.Equal(t, uof.URN("sr:match:2"), s.SupersededBy)
+	assert.Equal(t, 1000, *s.Cancel.StartTime)
+	assert.Equal(t, 2000, *s.Cancel.EndTime)
+	assert.False(t, l.Cancelled("sr:match:1", 18, "total=2.5", 999).Cancelled)
+	assert.False(t, l.Cancelled("sr:match:1", 18, "total=2.5", 2000).Cancelled)
+	assert.False(t, l.Cancelled("sr:match:1", 18, "total=3.5", 1500).Cancelled)
+
+	// rollback with the same window restores the bet
+	apply("sr:match:1", "rollback_bet_cancel", `start_time="1000" end_time="2000"`, market)
+	assert.False(t, l.Cancelled("sr:match:1", 18, "total=2.5", 1500).Cancelled)
+	s = l.Cancelled("sr:match:2", 18, "total=2.5", 1500)
+	assert.True(t, s.Cancelled)
+	assert.Equal(t, uof.URN("sr:match:3"), s.SupersededBy)
+
+	// superseding event without cancel or rollback
+	apply("sr:match:4", "bet_cancel", `start_time="1000" end_time="2000" superceded_by="sr:match:5"`, market)
+	s = l.Cancelled("sr:match:4", 18, "total=2.5", 1500)
+	assert.True(t, s.Cancelled)
+	assert.Equal(t, uof.URN("sr:match:5"), s.SupersededBy)
+	assert.Equal(t, 1000, *s.Cancel.StartTime)
+	// cancel of the superseding event is for the other market
+	apply("sr:match:5", "bet_cancel", `start_time="1800"`, `<market id="1"/>`)
+	s = l.Cancelled("sr:match:4", 18, "total=2.5", 1500)
+	assert.True(t, s.Cancelled)
+	assert.Equal(t, 1000, *s.Cancel.StartTime)
+
+	// rollback on the superseding event covering the bet follows the chain
+	apply("sr:match:5", "rollback_bet_cancel", `start_time="1000" end_time="1800"`, `<market id="1"/>`)
+	s = l.Cancelled("sr:match:4", 18, "total=2.5", 1500)
+	assert.False(t, s.Cancelled)
+	assert.Nil(t, s.Cancel)
+	assert.Equal(t, uof.URN("sr:match:5"), s.SupersededBy)
+	// bet outside of the rollback window stays cancelled
+	s = l.Cancelled("sr:match:4", 18, "total=2.5", 1900)
+	assert.True(t, s.Cancelled)
+	assert.Equal(t, 1000, *s.Cancel.StartTime)
+	assert.Equal(t, uof.URN("sr:match:5"), s.SupersededBy)
+
+	// forget removes rollbacks
+	l.Forget("sr:match:5")
+	assert.True(t, l.Cancelled("sr:match:4", 18, "total=2.5", 1500).Cancelled)
+}
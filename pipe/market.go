@@ -41,6 +41,9 @@ func (s *markets) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc cha
 
 	s.getAll()
 	for m := range in {
+		if !unpackBody(m, errc, uof.MessageTypeOddsChange) {
+			continue
+		}
 		out <- m
 		if m.Is(uof.MessageTypeOddsChange) {
 			m.OddsChange.EachVariantMarket(func(marketID int, variant string) {
@@ -70,11 +73,10 @@ func (s *markets) getAll() {
 }
 
 func (s *markets) variantMarket(marketID int, variant string, requestedAt int) {
-	if strings.HasPrefix(variant, "pre:playerprops") {
-		// TODO: it is not working for this type of variant markets
+	if !variantSupported(variant) {
 		return
 	}
-	key := uof.Hash(variant)<<32 | marketID
+	key := variantKey(marketID, variant)
 	if s.em.fresh(key) {
 		return
 	}
@@ -95,8 +97,18 @@ func (s *markets) variantMarket(marketID int, variant string, requestedAt int) {
 				s.errc <- err
 				return
 			}
-			s.out <- uof.NewMarketsMessage(lang, ms, requestedAt, raw)
-
+			s.out <- uof.NewMarketVariantMessage(lang, marketID, variant, ms, requestedAt, raw)
 		}(lang)
 	}
 }
+
+// variantSupported reports whether variant market descriptions can be fetched
+// from the api for that variant.
+func variantSupported(variant string) bool {
+	// TODO: it is not working for this type of variant markets
+	return !strings.HasPrefix(variant, "pre:playerprops")
+}
+
+func variantKey(marketID int, variant string) int {
+	return uof.Hash(variant)<<32 | marketID
+}
@@ -0,0 +1,144 @@
+package pipe
+
+import (
+	"sort"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+// how often next betstop timers are checked
+const nextBetstopInterval = time.Second
+
+type betstopKey struct {
+	producer uof.Producer
+	eventURN uof.URN
+	market   lineKey
+}
+
+type betstopTimer struct {
+	at      int // timestamp in milliseconds
+	sportID int
+}
+
+type nextBetstop struct {
+	timers map[betstopKey]betstopTimer
+	out    chan<- *uof.Message
+}
+
+// NextBetstop schedules timer for each active market with the next betstop
+// time in the market metadata (Market.NextBetstop). Odds change for the
+// market reschedules or cancels the timer. If timer fires, before the feed
+// suspends the market, SDK generated bet stop with MarketStatusSuspended is
+// emitted for the market.
+func NextBetstop() InnerStage {
+	n := newNextBetstop()
+	return Stage(n.loop)
+}
+
+func newNextBetstop() *nextBetstop {
+	return &nextBetstop{
+		timers: make(map[betstopKey]betstopTimer),
+	}
+}
+
+func (n *nextBetstop) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc chan<- error) {
+	n.out = out
+	ticker := time.NewTicker(nextBetstopInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case m, ok := <-in:
+			if !ok {
+				return
+			}
+			if !unpackBody(m, errc, uof.MessageTypeOddsChange, uof.MessageTypeBetStop) {
+				continue
+			}
+			n.handle(m, uof.CurrentTimestamp())
+		case <-ticker.C:
+			n.fire(uof.CurrentTimestamp())
+		}
+	}
+}
+
+func (n *nextBetstop) handle(m *uof.Message, now int) {
+	n.out <- m
+	switch m.Type {
+	case uof.MessageTypeOddsChange:
+		if m.OddsChange != nil {
+			n.oddsChange(m, now)
+		}
+	case uof.MessageTypeBetStop:
+		if m.BetStop != nil && !m.Generated {
+			n.betStop(m)
+		}
+	}
+}
+
+func (n *nextBetstop) oddsChange(m *uof.Message, now int) {
+	for _, mkt := range m.OddsChange.Markets {
+		k := betstopKey{producer: m.Producer, eventURN: m.EventURN, market: lineKey{id: mkt.ID, lineID: mkt.LineID}}
+		if mkt.Status != uof.MarketStatusActive || mkt.NextBetstop == nil || *mkt.NextBetstop <= now {
+			delete(n.timers, k)
+			continue
+		}
+		n.timers[k] = betstopTimer{at: *mkt.NextBetstop, sportID: m.SportID}
+	}
+}
+
+// betStop cancels timers of the markets suspended by the feed
+func (n *nextBetstop) betStop(m *uof.Message) {
+	for k := range n.timers {
+		if k.producer == m.Producer && k.eventURN == m.EventURN && betStopCovers(m.BetStop, k.market.id) {
+			delete(n.timers, k)
+		}
+	}
+}
+
+// fire emits bet stop for the markets with expired timers, one message for
+// each event
+func (n *nextBetstop) fire(now int) {
+	type eventKey struct {
+		producer uof.Producer
+		eventURN uof.URN
+	}
+	markets := make(map[eventKey][]int)
+	sports := make(map[eventKey]int)
+	var events []eventKey
+	for k, t := range n.timers {
+		if t.at > now {
+			continue
+		}
+		ek := eventKey{producer: k.producer, eventURN: k.eventURN}
+		if _, ok := markets[ek]; !ok {
+			events = append(events, ek)
+		}
+		markets[ek] = append(markets[ek], k.market.id)
+		sports[ek] = t.sportID
+		delete(n.timers, k)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].eventURN != events[j].eventURN {
+			return events[i].eventURN < events[j].eventURN
+		}
+		return events[i].producer < events[j].producer
+	})
+	for _, ek := range events {
+		ids := uniqueSortedInts(markets[ek])
+		g := uof.NewBetStopMessage(ek.producer, ek.eventURN, ids, uof.MarketStatusSuspended, uof.BetStopOriginNextBetstop)
+		g.SportID = sports[ek]
+		n.out <- g
+	}
+}
+
+func uniqueSortedInts(s []int) []int {
+	sort.Ints(s)
+	u := s[:0]
+	for _, v := range s {
+		if len(u) == 0 || v != u[len(u)-1] {
+			u = append(u, v)
+		}
+	}
+	return u
+}
@@ -0,0 +1,48 @@
+package pipe
+
+import (
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextBetstop(t *testing.T) {
+	n := newNextBetstop()
+	out := make(chan *uof.Message, 16)
+	n.out = out
+
+	n.handle(oddsDeltaMessage(t, "odds_change", "3", `<odds>
+		<market id="1"><market_metadata next_betstop="2000"/><outcome id="1" odds="1.5"/></market>
+		<market id="2"><market_metadata next_betstop="2000"/><outcome id="1" odds="1.5"/></market>
+		<market id="3" status="-1"><market_metadata next_betstop="2000"/></market>
+		<market id="4"><market_metadata next_betstop="500"/><outcome id="1" odds="1.5"/></market>
+		<market id="5"><outcome id="1" odds="1.5"/></market>
+	</odds>`), 1000)
+	<-out
+	assert.Len(t, n.timers, 2)
+
+	// fresh odds change reschedules the timer
+	n.handle(oddsDeltaMessage(t, "odds_change", "3", `<odds>
+		<market id="2"><market_metadata next_betstop="3000"/><outcome id="1" odds="1.5"/></market>
+	</odds>`), 1500)
+	<-out
+
+	n.fire(1999)
+	assert.Len(t, out, 0)
+	n.fire(2000)
+	g := <-out
+	assert.True(t, g.Generated)
+	assert.Equal(t, uof.ProducerPrematch, g.Producer)
+	assert.Equal(t, uof.URN("sr:match:1"), g.EventURN)
+	assert.Equal(t, []int{1}, g.BetStop.MarketIDs)
+	assert.Equal(t, uof.MarketStatusSuspended, g.BetStop.Status)
+	assert.Len(t, n.timers, 1)
+
+	// bet stop from the feed cancels timer
+	n.handle(oddsDeltaMessage(t, "bet_stop", "3", ""), 2500)
+	<-out
+	n.fire(3000)
+	assert.Len(t, out, 0)
+	assert.Len(t, n.timers, 0)
+}
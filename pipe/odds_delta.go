@@ -0,0 +1,239 @@
+package pipe
+
+import (
+	"reflect"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+// events not updated for this long are removed from the stages state
+const eventStateTTL = 12 * 60 * 60 * 1000 // in milliseconds
+
+type deltaKey struct {
+	producer uof.Producer
+	eventURN uof.URN
+}
+
+type lineKey struct {
+	id     int
+	lineID int
+}
+
+type outcomeState struct {
+	odds   *float64
+	active *bool
+}
+
+type marketState struct {
+	status     uof.MarketStatus
+	outcomes   map[int]outcomeState
+	stopOrigin uof.BetStopOrigin // origin of the bet stop which suspended market
+}
+
+type eventState struct {
+	markets       map[lineKey]*marketState
+	bettingStatus *int
+	betstopReason *int
+	eventStatus   *uof.SportEventStatus
+	updatedAt     int
+}
+
+type oddsDelta struct {
+	dropUnchanged bool
+	events        map[deltaKey]*eventState
+	sweptAt       int
+}
+
+// OddsDelta compares each odds change with the previous state of the event
+// and attaches the difference to the OddsChange.Delta. State is kept for each
+// producer of the event. Bet stop and bet stop lift messages are applied to
+// the state as market status changes.
+//
+// Odds change can be partial, it has only markets changed since the previous
+// one, so markets missing from the odds change are left unchanged. Market is
+// reported as removed, and its state forgotten, only when odds change has it
+// with the deactivated or cancelled status.
+//
+// If dropUnchanged is set odds changes without effective change are not
+// passed to the next stage.
+func OddsDelta(dropUnchanged bool) InnerStage {
+	d := &oddsDelta{
+		dropUnchanged: dropUnchanged,
+		events:        make(map[deltaKey]*eventState),
+	}
+	return Stage(d.loop)
+}
+
+func (d *oddsDelta) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc chan<- error) {
+	for m := range in {
+		if !unpackBody(m, errc, uof.MessageTypeOddsChange, uof.MessageTypeBetStop) {
+			continue
+		}
+		switch m.Type {
+		case uof.MessageTypeOddsChange:
+			if m.OddsChange == nil {
+				break
+			}
+			delta := d.oddsChange(m)
+			if d.dropUnchanged && delta.Empty() {
+				continue
+			}
+			m.OddsChange.Delta = delta
+		case uof.MessageTypeBetStop, uof.MessageTypeBetStopLift:
+			d.betStop(m)
+		}
+		out <- m
+		d.sweep(m.ReceivedAt)
+	}
+}
+
+func (d *oddsDelta) event(m *uof.Message) *eventState {
+	k := deltaKey{producer: m.Producer, eventURN: m.EventURN}
+	e, ok := d.events[k]
+	if !ok {
+		e = &eventState{markets: make(map[lineKey]*marketState)}
+		d.events[k] = e
+	}
+	e.updatedAt = m.ReceivedAt
+	return e
+}
+
+func (d *oddsDelta) oddsChange(m *uof.Message) *uof.OddsDelta {
+	oc := m.OddsChange
+	e := d.event(m)
+	delta := &uof.OddsDelta{}
+
+	if !intPtrEqual(e.bettingStatus, oc.BettingStatus) || !intPtrEqual(e.betstopReason, oc.BetstopReason) {
+		delta.BettingStatusChanged = true
+	}
+	e.bettingStatus, e.betstopReason = oc.BettingStatus, oc.BetstopReason
+	if oc.EventStatus != nil {
+		if !reflect.DeepEqual(e.eventStatus, oc.EventStatus) {
+			delta.EventStatusChanged = true
+		}
+		e.eventStatus = oc.EventStatus
+	}
+
+	for _, mkt := range oc.Markets {
+		k := lineKey{id: mkt.ID, lineID: mkt.LineID}
+		ms, ok := e.markets[k]
+		if removedStatus(mkt.Status) {
+			if ok {
+				delta.Markets = append(delta.Markets, uof.MarketDelta{ID: mkt.ID, LineID: mkt.LineID, Change: uof.MarketChangeRemoved, Status: mkt.Status, PrevStatus: ms.status})
+				delete(e.markets, k)
+			}
+			continue
+		}
+		if !ok {
+			ms = &marketState{status: uof.MarketStatusInactive, outcomes: make(map[int]outcomeState)}
+			e.markets[k] = ms
+			delta.Markets = append(delta.Markets, uof.MarketDelta{ID: mkt.ID, LineID: mkt.LineID, Change: uof.MarketChangeNew, Status: mkt.Status, PrevStatus: ms.status})
+		} else if ms.status != mkt.Status {
+			delta.Markets = append(delta.Markets, uof.MarketDelta{ID: mkt.ID, LineID: mkt.LineID, Change: uof.MarketChangeStatus, Status: mkt.Status, PrevStatus: ms.status})
+		}
+		ms.status, ms.stopOrigin = mkt.Status, uof.BetStopOriginFeed
+
+		for _, o := range mkt.Outcomes {
+			prev, ok := ms.outcomes[o.ID]
+			if !ok || !floatPtrEqual(prev.odds, o.Odds) || !boolPtrEqual(prev.active, o.Active) {
+				delta.Outcomes = append(delta.Outcomes, uof.OutcomeDelta{
+					MarketID:   mkt.ID,
+					LineID:     mkt.LineID,
+					ID:         o.ID,
+					Odds:       o.Odds,
+					PrevOdds:   prev.odds,
+					Active:     o.Active,
+					PrevActive: prev.active,
+				})
+			}
+			ms.outcomes[o.ID] = outcomeState{odds: o.Odds, active: o.Active}
+		}
+	}
+	return delta
+}
+
+// removedStatus is market status after which market is not offered any more
+func removedStatus(s uof.MarketStatus) bool {
+	return s == uof.MarketStatusInactive || s == uof.MarketStatusCancelled
+}
+
+// betStop moves active markets of the event to the bet stop status. Bet stop
+// lift restores markets stopped by the SDK generated stop of the same origin.
+func (d *oddsDelta) betStop(m *uof.Message) {
+	bs := m.BetStop
+	if bs == nil {
+		return
+	}
+	e, ok := d.events[deltaKey{producer: m.Producer, eventURN: m.EventURN}]
+	if !ok {
+		return
+	}
+	for k, ms := range e.markets {
+		if !betStopCovers(bs, k.id) {
+			continue
+		}
+		if m.Is(uof.MessageTypeBetStopLift) {
+			if ms.status != uof.MarketStatusActive && liftable(ms.stopOrigin, bs.Origin) {
+				ms.status, ms.stopOrigin = uof.MarketStatusActive, uof.BetStopOriginFeed
+			}
+			continue
+		}
+		if ms.status == uof.MarketStatusActive {
+			ms.status, ms.stopOrigin = bs.Status, bs.Origin
+		}
+	}
+}
+
+// liftable is true if the generated lift of the origin lifts the stop. Only
+// stops generated by the same stage are lifted, never the feed ones.
+func liftable(stop, lift uof.BetStopOrigin) bool {
+	return stop != uof.BetStopOriginFeed && stop == lift
+}
+
+// betStopCovers is true if bet stop is for the market. Bet stop without
+// groups and market ids is for all markets of the event.
+func betStopCovers(bs *uof.BetStop, marketID int) bool {
+	if bs.Groups == nil && len(bs.MarketIDs) == 0 {
+		return true
+	}
+	for _, id := range bs.MarketIDs {
+		if id == marketID {
+			return true
+		}
+	}
+	return false
+}
+
+// sweep removes state of the events without updates in eventStateTTL
+func (d *oddsDelta) sweep(now int) {
+	if now-d.sweptAt < eventStateTTL/12 {
+		return
+	}
+	d.sweptAt = now
+	for k, e := range d.events {
+		if now-e.updatedAt > eventStateTTL {
+			delete(d.events, k)
+		}
+	}
+}
+
+func intPtrEqual(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func floatPtrEqual(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func boolPtrEqual(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,172 @@
+package pipe
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func oddsDeltaMessage(t *testing.T, typ, product, body string) *uof.Message {
+	m, err := uof.NewQueueMessage("hi.-.live."+typ+".1.sr:match.1.-",
+		[]byte(`<`+typ+` product="`+product+`" event_id="sr:match:1" timestamp="1">`+body+`</`+typ+`>`))
+	assert.NoError(t, err)
+	return m
+}
+
+func TestOddsDelta(t *testing.T) {
+	d := &oddsDelta{events: make(map[deltaKey]*eventState)}
+	first := oddsDeltaMessage(t, "odds_change", "1", `<odds>
+		<market id="1"><outcome id="1" odds="1.5" active="1"/><outcome id="2" odds="2.5" active="1"/></market>
+		<market id="18" specifiers="total=2.5"><outcome id="12" odds="1.9" active="1"/></market>
+	</odds>`)
+	delta := d.oddsChange(first)
+	assert.Len(t, delta.Markets, 2)
+	assert.Equal(t, uof.MarketChangeNew, delta.Markets[0].Change)
+	assert.Len(t, delta.Outcomes, 3)
+
+	// same message, no change
+	delta = d.oddsChange(oddsDeltaMessage(t, "odds_change", "1", `<odds>
+		<market id="1"><outcome id="1" odds="1.5" active="1"/><outcome id="2" odds="2.5" active="1"/></market>
+		<market id="18" specifiers="total=2.5"><outcome id="12" odds="1.9" active="1"/></market>
+	</odds>`))
+	assert.True(t, delta.Empty())
+
+	// odds, active and status change, missing market is not removed
+	delta = d.oddsChange(oddsDeltaMessage(t, "odds_change", "1", `<odds betting_status="1">
+		<market id="1" status="-1"><outcome id="1" odds="1.6" active="1"/><outcome id="2" odds="2.5" active="0"/></market>
+	</odds>`))
+	assert.False(t, delta.Empty())
+	assert.True(t, delta.BettingStatusChanged)
+	assert.Equal(t, []uof.MarketDelta{
+		{ID: 1, Change: uof.MarketChangeStatus, Status: uof.MarketStatusSuspended, PrevStatus: uof.MarketStatusActive},
+	}, delta.Markets)
+	assert.Len(t, delta.Outcomes, 2)
+	assert.Equal(t, 1.6, *delta.Outcomes[0].Odds)
+	assert.Equal(t, 1.5, *delta.Outcomes[0].PrevOdds)
+	assert.False(t, *delta.Outcomes[1].Active)
+	assert.True(t, *delta.Outcomes[1].PrevActive)
+
+	// bet stop suspends active markets
+	delta = d.oddsChange(oddsDeltaMessage(t, "odds_change", "1", `<odds betting_status="1">
+		<market id="1"><outcome id="1" odds="1.6" active="1"/><outcome id="2" odds="2.5" active="0"/></market>
+	</odds>`))
+	assert.Len(t, delta.Markets, 1)
+	d.betStop(oddsDeltaMessage(t, "bet_stop", "1", ""))
+	delta = d.oddsChange(oddsDeltaMessage(t, "odds_change", "1", `<odds betting_status="1">
+		<market id="1"><outcome id="1" odds="1.6" active="1"/><outcome id="2" odds="2.5" active="0"/></market>
+	</odds>`))
+	assert.Equal(t, uof.MarketStatusSuspended, delta.Markets[0].PrevStatus)
+
+	// market is removed by the explicit deactivated status
+	delta = d.oddsChange(oddsDeltaMessage(t, "odds_change", "1", `<odds betting_status="1">
+		<market id="18" specifiers="total=2.5" status="0"/>
+	</odds>`))
+	assert.Equal(t, []uof.MarketDelta{
+		{ID: 18, LineID: first.OddsChange.Markets[1].LineID, Change: uof.MarketChangeRemoved, Status: uof.MarketStatusInactive, PrevStatus: uof.MarketStatusSuspended},
+	}, delta.Markets)
+	assert.Len(t, delta.Outcomes, 0)
+	// unknown market deactivated, nothing to remove
+	delta = d.oddsChange(oddsDeltaMessage(t, "odds_change", "1", `<odds betting_status="1">
+		<market id="18" specifiers="total=2.5" status="0"/>
+	</odds>`))
+	assert.True(t, delta.Empty())
+
+	// prematch producer state is separate
+	delta = d.oddsChange(oddsDeltaMessage(t, "odds_change", "3", `<odds>
+		<market id="1"><outcome id="1" odds="1.5"/></market>
+		<market id="2"><outcome id="1" odds="1.5"/></market>
+	</odds>`))
+	assert.Len(t, delta.Markets, 2)
+	delta = d.oddsChange(oddsDeltaMessage(t, "odds_change", "3", `<odds>
+		<market id="2" status="-4"/>
+	</odds>`))
+	assert.Equal(t, uof.MarketChangeRemoved, delta.Markets[0].Change)
+	assert.Equal(t, uof.MarketStatusCancelled, delta.Markets[0].Status)
+}
+
+func TestOddsDeltaPartial(t *testing.T) {
+	d := &oddsDelta{events: make(map[deltaKey]*eventState)}
+	full := oddsChangeMessage(t)
+	d.oddsChange(full)
+	e := d.events[deltaKey{producer: full.Producer, eventURN: full.EventURN}]
+	markets := len(e.markets)
+	assert.Equal(t, 5, markets) // deactivated market 49 is not in the state
+
+	// odds change with only one market changed
+	buf, err := ioutil.ReadFile("../testdata/odds_change-partial.xml")
+	assert.NoError(t, err)
+	partial, err := uof.NewQueueMessage("hi.pre.-.odds_change.1.sr:match.1234.-", buf)
+	assert.NoError(t, err)
+	assert.False(t, partial.Producer.Prematch())
+	delta := d.oddsChange(partial)
+	assert.Len(t, delta.Markets, 0)
+	assert.Len(t, delta.Outcomes, 1)
+	assert.Equal(t, 1.15, *delta.Outcomes[0].Odds)
+	assert.Len(t, e.markets, markets)
+}
+
+func TestOddsDeltaLiftOrigin(t *testing.T) {
+	d := &oddsDelta{events: make(map[deltaKey]*eventState)}
+	d.oddsChange(oddsDeltaMessage(t, "odds_change", "3", `<odds>
+		<market id="1"><outcome id="1" odds="1.5" active="1"/></market>
+		<market id="2"><outcome id="1" odds="1.5" active="1"/></market>
+	</odds>`))
+	d.betStop(uof.NewBetStopMessage(uof.ProducerPrematch, "sr:match:1", []int{2}, uof.MarketStatusSuspended, uof.BetStopOriginHandover))
+	d.betStop(uof.NewBetStopMessage(uof.ProducerPrematch, "sr:match:1", nil, uof.MarketStatusSuspended, uof.BetStopOriginProducerDown))
+	d.betStop(uof.NewBetStopLiftMessage(uof.ProducerPrematch, "sr:match:1", nil, uof.BetStopOriginProducerDown))
+
+	e := d.events[deltaKey{producer: uof.ProducerPrematch, eventURN: "sr:match:1"}]
+	assert.Equal(t, uof.MarketStatusActive, e.markets[lineKey{id: 1}].status)
+	assert.Equal(t, uof.MarketStatusSuspended, e.markets[lineKey{id: 2}].status)
+}
+
+func TestOddsDeltaEventURN(t *testing.T) {
+	d := &oddsDelta{events: make(map[deltaKey]*eventState)}
+	// same id, different urn types
+	for _, urn := range []string{"vf:match:1", "vf:season:1"} {
+		parts := strings.Split(urn, ":")
+		m, err := uof.NewQueueMessage("hi.-.live.odds_change.1."+parts[0]+":"+parts[1]+"."+parts[2]+".-",
+			[]byte(`<odds_change product="1" event_id="`+urn+`" timestamp="1"><odds>
+			<market id="1"><outcome id="1" odds="1.5" active="1"/></market></odds></odds_change>`))
+		assert.NoError(t, err)
+		delta := d.oddsChange(m)
+		assert.Equal(t, uof.MarketChangeNew, delta.Markets[0].Change)
+	}
+	assert.Len(t, d.events, 2)
+}
+
+func TestOddsDeltaStage(t *testing.T) {
+	in := make(chan *uof.Message)
+	out, errc := OddsDelta(true)(in)
+	body := `<odds><market id="1"><outcome id="1" odds="1.5"/></market></odds>`
+	go func() {
+		in <- oddsDeltaMessage(t, "odds_change", "1", body)
+		in <- oddsDeltaMessage(t, "odds_change", "1", body) // dropped
+		in <- uof.NewConnnectionMessage(uof.ConnectionStatusUp)
+		close(in)
+	}()
+	var msgs []*uof.Message
+	for m := range out {
+		msgs = append(msgs, m)
+	}
+	for err := range errc {
+		assert.NoError(t, err)
+	}
+	assert.Len(t, msgs, 2)
+	assert.NotNil(t, msgs[0].OddsChange.Delta)
+	assert.Equal(t, uof.MessageTypeConnection, msgs[1].Type)
+}
+
+func TestOddsDeltaSweep(t *testing.T) {
+	d := &oddsDelta{events: make(map[deltaKey]*eventState)}
+	m := oddsDeltaMessage(t, "odds_change", "1", "")
+	m.ReceivedAt = 1
+	d.oddsChange(m)
+	d.sweep(eventStateTTL)
+	assert.Len(t, d.events, 1)
+	d.sweep(2 * eventStateTTL)
+	assert.Len(t, d.events, 0)
+}
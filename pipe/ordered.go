@@ -0,0 +1,310 @@
+package pipe
+
+import (
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+// lexiconKey identifies one lexicon message in one language. Fixtures are
+// identified by the event urn, others by id.
+type lexiconKey struct {
+	typ  uof.MessageType
+	id   int
+	urn  uof.URN
+	lang uof.Lang
+}
+
+// heldMessage is event message waiting for lexicon messages it depends on.
+type heldMessage struct {
+	m        *uof.Message
+	requires []lexiconKey
+	deadline time.Time
+}
+
+// Keys of the lexicon messages which are not used for that long are removed.
+// Lexicon stages get them again after their expire interval, which is not
+// longer than this.
+const lexiconExpireAfter = 24 * time.Hour
+
+type ordered struct {
+	languages   []uof.Lang
+	timeout     time.Duration
+	liveTimeout time.Duration
+	requested   map[lexiconKey]time.Time            // requested by the lexicon stages, with last use time
+	seen        map[lexiconKey]time.Time            // already emitted, with last use time
+	held        map[uof.URN][]*heldMessage          // waiting messages by event, in arrival order
+	waiting     map[lexiconKey]map[uof.URN]struct{} // events with messages waiting for the lexicon key
+	out         chan<- *uof.Message
+	expireAfter time.Duration
+	cleanedAt   time.Time
+}
+
+// Ordered holds event messages until the lexicon messages they depend on have
+// been emitted. Lexicon stages (Markets, Fixture, Player) pass event messages
+// immediately and make api calls in the background, so consumer can get odds
+// change before fixture, variant market description or player it refers to.
+// This stage must be placed after lexicon stages. It follows which lexicon
+// messages the previous stages should emit and delays event messages until
+// those are seen. Order of the messages for one event is preserved.
+//
+// Message is never held longer than timeout. Live messages are held no more
+// than liveTimeout. If liveTimeout is zero timeout is used for all messages.
+func Ordered(languages []uof.Lang, timeout, liveTimeout time.Duration) InnerStage {
+	o := newOrdered(languages, timeout, liveTimeout)
+	return Stage(o.loop)
+}
+
+func newOrdered(languages []uof.Lang, timeout, liveTimeout time.Duration) *ordered {
+	if liveTimeout <= 0 || liveTimeout > timeout {
+		liveTimeout = timeout
+	}
+	return &ordered{
+		languages:   languages,
+		timeout:     timeout,
+		liveTimeout: liveTimeout,
+		requested:   make(map[lexiconKey]time.Time),
+		seen:        make(map[lexiconKey]time.Time),
+		held:        make(map[uof.URN][]*heldMessage),
+		waiting:     make(map[lexiconKey]map[uof.URN]struct{}),
+		expireAfter: lexiconExpireAfter,
+		cleanedAt:   time.Now(),
+	}
+}
+
+func (o *ordered) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc chan<- error) {
+	o.out = out
+	ticker := time.NewTicker(o.checkInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case m, ok := <-in:
+			if !ok {
+				o.flush()
+				return
+			}
+			if !unpackBody(m, errc, uof.MessageTypeOddsChange, uof.MessageTypeFixtureChange) {
+				continue
+			}
+			o.handle(m, time.Now())
+		case now := <-ticker.C:
+			o.release(now)
+			o.cleanup(now)
+		}
+	}
+}
+
+// how often to check for expired messages
+func (o *ordered) checkInterval() time.Duration {
+	i := o.liveTimeout / 4
+	if i < time.Millisecond {
+		i = time.Millisecond
+	}
+	return i
+}
+
+func (o *ordered) handle(m *uof.Message, now time.Time) {
+	switch m.Type.Kind() {
+	case uof.MessageKindLexicon:
+		o.out <- m
+		o.releaseWaiting(o.markSeen(m, now), now)
+	case uof.MessageKindEvent:
+		o.hold(m, now)
+	default:
+		o.out <- m
+	}
+}
+
+func (o *ordered) hold(m *uof.Message, now time.Time) {
+	requires := o.requires(m, now)
+	queue := o.held[m.EventURN]
+	if len(queue) == 0 && len(requires) == 0 {
+		o.out <- m
+		return
+	}
+	deadline := now.Add(o.timeout)
+	if live(m) {
+		deadline = now.Add(o.liveTimeout)
+	}
+	o.held[m.EventURN] = append(queue, &heldMessage{m: m, requires: requires, deadline: deadline})
+	for _, k := range requires {
+		events, ok := o.waiting[k]
+		if !ok {
+			events = make(map[uof.URN]struct{})
+			o.waiting[k] = events
+		}
+		events[m.EventURN] = struct{}{}
+	}
+}
+
+func live(m *uof.Message) bool {
+	return m.Scope == uof.MessageScopeLive || m.Scope == uof.MessageScopePrematchAndLive
+}
+
+// requires returns lexicon messages which are expected to be emitted by the
+// lexicon stages but are not seen yet.
+func (o *ordered) requires(m *uof.Message, now time.Time) []lexiconKey {
+	var keys []lexiconKey
+	add := func(k lexiconKey) {
+		for _, lang := range o.languages {
+			k.lang = lang
+			if _, ok := o.seen[k]; !ok {
+				keys = append(keys, k)
+				continue
+			}
+			o.seen[k] = now
+		}
+	}
+	request := func(k lexiconKey) {
+		for _, lang := range o.languages {
+			k.lang = lang
+			o.requested[k] = now
+		}
+	}
+
+	// same rules as in fixture stage
+	if u := fixtureEventURN(m); u != uof.NoURN && !u.IsTournament() {
+		request(lexiconKey{typ: uof.MessageTypeFixture, urn: u})
+	}
+	if fixture := (lexiconKey{typ: uof.MessageTypeFixture, urn: m.EventURN}); o.isRequested(fixture) {
+		add(fixture)
+	}
+
+	if m.Is(uof.MessageTypeOddsChange) && m.OddsChange != nil {
+		add(lexiconKey{typ: uof.MessageTypeMarkets})
+		m.OddsChange.EachVariantMarket(func(marketID int, variant string) {
+			if variantSupported(variant) {
+				add(lexiconKey{typ: uof.MessageTypeMarkets, id: variantKey(marketID, variant)})
+			}
+		})
+		m.OddsChange.EachPlayer(func(playerID int) {
+			add(lexiconKey{typ: uof.MessageTypePlayer, id: playerID})
+		})
+	}
+	return keys
+}
+
+func (o *ordered) isRequested(k lexiconKey) bool {
+	for _, lang := range o.languages {
+		k.lang = lang
+		if _, ok := o.requested[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// markSeen marks lexicon keys of the message as seen, returns marked keys
+func (o *ordered) markSeen(m *uof.Message, now time.Time) []lexiconKey {
+	var keys []lexiconKey
+	mark := func(k lexiconKey) {
+		k.lang = m.Lang
+		o.seen[k] = now
+		keys = append(keys, k)
+	}
+	switch m.Type {
+	case uof.MessageTypeFixture:
+		if m.Fixture != nil {
+			mark(lexiconKey{typ: uof.MessageTypeFixture, urn: m.Fixture.URN})
+		}
+	case uof.MessageTypePlayer:
+		if m.Player != nil {
+			mark(lexiconKey{typ: uof.MessageTypePlayer, id: m.Player.ID})
+		}
+	case uof.MessageTypeMarkets:
+		if v := m.MarketVariant; v != nil {
+			mark(lexiconKey{typ: uof.MessageTypeMarkets, id: variantKey(v.MarketID, v.Variant)})
+			return keys
+		}
+		// response with all markets
+		mark(lexiconKey{typ: uof.MessageTypeMarkets})
+		for _, d := range m.Markets {
+			if d.Variant != "" {
+				mark(lexiconKey{typ: uof.MessageTypeMarkets, id: variantKey(d.ID, d.Variant)})
+			}
+		}
+	}
+	return keys
+}
+
+// releaseWaiting releases messages of the events waiting for the keys
+func (o *ordered) releaseWaiting(keys []lexiconKey, now time.Time) {
+	for _, k := range keys {
+		for eventURN := range o.waiting[k] {
+			o.releaseEvent(eventURN, now)
+		}
+		delete(o.waiting, k)
+	}
+}
+
+// release sends all held messages which are ready or expired
+func (o *ordered) release(now time.Time) {
+	for eventURN := range o.held {
+		o.releaseEvent(eventURN, now)
+	}
+}
+
+// releaseEvent sends held messages of the event which are ready or expired,
+// in order until the first one which must wait
+func (o *ordered) releaseEvent(eventURN uof.URN, now time.Time) {
+	queue := o.held[eventURN]
+	i := 0
+	for ; i < len(queue); i++ {
+		h := queue[i]
+		if !o.ready(h) && now.Before(h.deadline) {
+			break
+		}
+		o.out <- h.m
+	}
+	if i == len(queue) {
+		delete(o.held, eventURN)
+		return
+	}
+	o.held[eventURN] = queue[i:]
+}
+
+func (o *ordered) ready(h *heldMessage) bool {
+	for _, k := range h.requires {
+		if _, ok := o.seen[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanup removes lexicon keys which are not used for expireAfter, and
+// waiting events released by the timeout
+func (o *ordered) cleanup(now time.Time) {
+	if now.Sub(o.cleanedAt) < o.expireAfter/4 {
+		return
+	}
+	o.cleanedAt = now
+	for _, keys := range []map[lexiconKey]time.Time{o.requested, o.seen} {
+		for k, t := range keys {
+			if now.Sub(t) > o.expireAfter {
+				delete(keys, k)
+			}
+		}
+	}
+	for k, events := range o.waiting {
+		for eventURN := range events {
+			if _, ok := o.held[eventURN]; !ok {
+				delete(events, eventURN)
+			}
+		}
+		if len(events) == 0 {
+			delete(o.waiting, k)
+		}
+	}
+}
+
+// flush sends all held messages
+func (o *ordered) flush() {
+	for _, queue := range o.held {
+		for _, h := range queue {
+			o.out <- h.m
+		}
+	}
+	o.held = make(map[uof.URN][]*heldMessage)
+	o.waiting = make(map[lexiconKey]map[uof.URN]struct{})
+}
@@ -0,0 +1,165 @@
+package pipe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedHoldsUntilFixture(t *testing.T) {
+	o := newOrdered([]uof.Lang{uof.LangEN}, time.Hour, time.Hour)
+	out := make(chan *uof.Message, 16)
+	o.out = out
+	now := time.Now()
+
+	fc := fixtureChangeMsg(t)
+	o.handle(fc, now)
+	assert.Len(t, out, 0)
+
+	// next message for the same event waits behind first
+	bs := &uof.Message{Header: uof.Header{Type: uof.MessageTypeBetStop, EventID: fc.EventID, EventURN: fc.EventURN}}
+	o.handle(bs, now)
+	assert.Len(t, out, 0)
+
+	// messages for other events are passing
+	other := &uof.Message{Header: uof.Header{Type: uof.MessageTypeBetStop, EventID: 1, EventURN: "sr:match:1"}}
+	o.handle(other, now)
+	assert.Equal(t, other, <-out)
+
+	// fixture releases held messages in order
+	f := uof.NewFixtureMessage(uof.LangEN, uof.Fixture{ID: fc.EventID, URN: fc.EventURN}, 0, nil)
+	o.handle(f, now)
+	assert.Equal(t, f, <-out)
+	assert.Equal(t, fc, <-out)
+	assert.Equal(t, bs, <-out)
+	assert.Len(t, o.held, 0)
+
+	// fixture is already seen
+	o.handle(bs, now)
+	assert.Equal(t, bs, <-out)
+}
+
+func TestOrderedTimeout(t *testing.T) {
+	o := newOrdered([]uof.Lang{uof.LangEN}, time.Hour, time.Second)
+	out := make(chan *uof.Message, 16)
+	o.out = out
+	now := time.Now()
+
+	m := oddsChangeMessage(t)
+	o.handle(m, now)
+	assert.Len(t, out, 0)
+	h := o.held[m.EventURN][0]
+	assert.Equal(t, now.Add(time.Hour), h.deadline)
+	// markets, variant market and players
+	assert.Len(t, h.requires, 1+1+41)
+
+	o.release(now.Add(time.Minute))
+	assert.Len(t, out, 0)
+	o.release(now.Add(time.Hour))
+	assert.Equal(t, m, <-out)
+
+	// live message is held shorter
+	m.Scope = uof.MessageScopeLive
+	o.handle(m, now)
+	o.release(now.Add(time.Second))
+	assert.Equal(t, m, <-out)
+}
+
+func TestOrderedPipe(t *testing.T) {
+	stage := Ordered([]uof.Lang{uof.LangEN}, time.Hour, 0)
+	in := make(chan *uof.Message)
+	out, _ := stage(in)
+
+	m := fixtureChangeMsg(t)
+	in <- m
+	c := uof.NewConnnectionMessage(uof.ConnectionStatusUp)
+	in <- c
+	assert.Equal(t, c, <-out)
+
+	// held messages are flushed on close
+	close(in)
+	assert.Equal(t, m, <-out)
+	_, ok := <-out
+	assert.False(t, ok)
+}
+
+func TestOrderedMarketsRequest(t *testing.T) {
+	o := newOrdered([]uof.Lang{uof.LangEN}, time.Hour, time.Hour)
+	out := make(chan *uof.Message, 16)
+	o.out = out
+	now := time.Now()
+	all := lexiconKey{typ: uof.MessageTypeMarkets, lang: uof.LangEN}
+	variant := lexiconKey{typ: uof.MessageTypeMarkets, id: variantKey(145, "sr:point_range:76+"), lang: uof.LangEN}
+
+	// variant response with one description is not all markets response
+	ms := uof.MarketDescriptions{{ID: 145, Variant: "sr:point_range:76+"}}
+	o.handle(uof.NewMarketVariantMessage(uof.LangEN, 145, "sr:point_range:76+", ms, 0, nil), now)
+	assert.Contains(t, o.seen, variant)
+	assert.NotContains(t, o.seen, all)
+
+	// all markets response with one description
+	o.handle(uof.NewMarketsMessage(uof.LangEN, uof.MarketDescriptions{{ID: 1}}, 0, nil), now)
+	assert.Contains(t, o.seen, all)
+	assert.Len(t, out, 2)
+}
+
+func TestOrderedCleanup(t *testing.T) {
+	o := newOrdered([]uof.Lang{uof.LangEN}, time.Hour, time.Hour)
+	out := make(chan *uof.Message, 16)
+	o.out = out
+	now := time.Now()
+
+	fc := fixtureChangeMsg(t)
+	o.handle(fc, now)
+	o.handle(uof.NewFixtureMessage(uof.LangEN, uof.Fixture{ID: fc.EventID, URN: fc.EventURN}, 0, nil), now)
+	o.handle(uof.NewFixtureMessage(uof.LangEN, uof.Fixture{ID: 1}, 0, nil), now)
+	assert.Len(t, o.requested, 1)
+	assert.Len(t, o.seen, 2)
+
+	// used key is kept
+	later := now.Add(o.expireAfter / 2)
+	o.handle(fc, later)
+	o.cleanup(now.Add(o.expireAfter + time.Minute))
+	assert.Len(t, o.requested, 1)
+	assert.Len(t, o.seen, 1)
+
+	o.cleanup(later.Add(o.expireAfter + time.Minute))
+	assert.Len(t, o.requested, 0)
+	assert.Len(t, o.seen, 0)
+}
+
+func TestOrderedWaiting(t *testing.T) {
+	o := newOrdered([]uof.Lang{uof.LangEN}, time.Hour, time.Hour)
+	out := make(chan *uof.Message, 16)
+	o.out = out
+	now := time.Now()
+
+	fixtureChange := func(id string) *uof.Message {
+		m, err := uof.NewQueueMessage("hi.pre.-.fixture_change.1.sr:match."+id+".-",
+			[]byte(`<fixture_change event_id="sr:match:`+id+`" product="3" start_time="1511107200000"/>`))
+		assert.NoError(t, err)
+		return m
+	}
+	fc1, fc2 := fixtureChange("1"), fixtureChange("2")
+	o.handle(fc1, now)
+	o.handle(fc2, now)
+	assert.Len(t, o.waiting, 2)
+
+	// fixture releases only the event waiting for it
+	f := uof.NewFixtureMessage(uof.LangEN, uof.Fixture{ID: 1, URN: "sr:match:1"}, 0, nil)
+	o.handle(f, now)
+	assert.Equal(t, f, <-out)
+	assert.Equal(t, fc1, <-out)
+	assert.Len(t, out, 0)
+	assert.Len(t, o.held, 1)
+	assert.Len(t, o.waiting, 1)
+
+	// event released by timeout is removed from the waiting on cleanup
+	o.release(now.Add(time.Hour))
+	assert.Equal(t, fc2, <-out)
+	assert.Len(t, o.waiting, 1)
+	o.cleanup(now.Add(o.expireAfter))
+	assert.Len(t, o.waiting, 0)
+}
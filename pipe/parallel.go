@@ -0,0 +1,63 @@
+package pipe
+
+import (
+	"github.com/minus5/go-uof-sdk"
+)
+
+// ShardKey returns key by which message is assigned to the consumer worker.
+// Messages with the same key are consumed by the same worker in the order in
+// which they are received.
+type ShardKey func(m *uof.Message) int
+
+// EventKey shards messages by event. All messages of one event are consumed by
+// the same worker.
+func EventKey(m *uof.Message) int {
+	return m.EventID
+}
+
+// ParallelConsumer starts workers instances of the consumer. Each one has its
+// own `in` chan of size buffer. Messages are distributed to the workers by the
+// key so the order of messages with the same key is preserved.
+//
+// Lexicon messages (markets, fixtures, players...) are not sharded, they have
+// no event. They are sent to all workers, so each worker gets lexicon in order
+// with the event messages it consumes.
+//
+// System messages (alive, connection, producers change...) are not sharded.
+// They are sent to all workers, or if systemLane is set, to the one additional
+// worker dedicated only to the system messages.
+//
+// Messages sent to more than one worker are the same instances, workers must
+// not modify them.
+func ParallelConsumer(consumer ConsumerStage, workers, buffer int, key ShardKey, systemLane bool) InnerStage {
+	if workers < 1 {
+		workers = 1
+	}
+	if key == nil {
+		key = EventKey
+	}
+	lanes := make([]consumerLane, workers)
+	if systemLane {
+		lanes = append(lanes, consumerLane{})
+	}
+	for i := range lanes {
+		lanes[i] = consumerLane{consumer: consumer, buffer: buffer}
+	}
+	return fanOut(lanes, func(m *uof.Message, send func(lane int)) {
+		kind := m.Type.Kind()
+		switch {
+		case kind == uof.MessageKindEvent:
+			send(shard(key(m), workers))
+		case kind == uof.MessageKindSystem && systemLane:
+			send(workers)
+		default:
+			for i := 0; i < workers; i++ {
+				send(i)
+			}
+		}
+	})
+}
+
+func shard(key, workers int) int {
+	return int(uint(key) % uint(workers))
+}
@@ -0,0 +1,129 @@
+package pipe
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+type shardRecorder struct {
+	events  map[int][]int            // event id -> timestamps in consume order
+	workers map[int]map[int]struct{} // event id -> workers
+	system  int                      // number of consumed system messages
+	sync.Mutex
+}
+
+func (r *shardRecorder) consumer() ConsumerStage {
+	var worker int
+	var mu sync.Mutex
+	return func(in <-chan *uof.Message) error {
+		mu.Lock()
+		worker++
+		w := worker
+		mu.Unlock()
+		for m := range in {
+			r.Lock()
+			if m.Type.Kind() == uof.MessageKindSystem {
+				r.system++
+			} else {
+				r.events[m.EventID] = append(r.events[m.EventID], m.Timestamp)
+				if r.workers[m.EventID] == nil {
+					r.workers[m.EventID] = make(map[int]struct{})
+				}
+				r.workers[m.EventID][w] = struct{}{}
+			}
+			r.Unlock()
+		}
+		return nil
+	}
+}
+
+func runParallel(t *testing.T, systemLane bool) *shardRecorder {
+	r := &shardRecorder{events: make(map[int][]int), workers: make(map[int]map[int]struct{})}
+	stage := ParallelConsumer(r.consumer(), 4, 2, EventKey, systemLane)
+	in := make(chan *uof.Message)
+	out, errc := stage(in)
+	go func() {
+		in <- uof.NewConnnectionMessage(uof.ConnectionStatusUp)
+		for ts := 1; ts <= 100; ts++ {
+			in <- &uof.Message{Header: uof.Header{Type: uof.MessageTypeOddsChange, EventID: ts % 10, Timestamp: ts}}
+		}
+		close(in)
+	}()
+	cnt := 0
+	for range out {
+		cnt++
+	}
+	for err := range errc {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 101, cnt)
+	return r
+}
+
+func TestParallelConsumer(t *testing.T) {
+	r := runParallel(t, false)
+	assert.Len(t, r.events, 10)
+	for eventID, tss := range r.events {
+		assert.Len(t, tss, 10)
+		// order within event is preserved
+		assert.True(t, sort.IntsAreSorted(tss))
+		// all event messages are consumed by one worker
+		assert.Len(t, r.workers[eventID], 1)
+	}
+	// system message is sent to each worker
+	assert.Equal(t, 4, r.system)
+}
+
+func TestParallelConsumerSystemLane(t *testing.T) {
+	r := runParallel(t, true)
+	assert.Len(t, r.events, 10)
+	assert.Equal(t, 1, r.system)
+}
+
+func TestParallelConsumerLexicon(t *testing.T) {
+	var mu sync.Mutex
+	lexicon := make(map[int]int) // worker -> number of lexicon messages
+	var worker int
+	consumer := func(in <-chan *uof.Message) error {
+		mu.Lock()
+		worker++
+		w := worker
+		mu.Unlock()
+		for m := range in {
+			if m.Type.Kind() == uof.MessageKindLexicon {
+				mu.Lock()
+				lexicon[w]++
+				mu.Unlock()
+			}
+		}
+		return nil
+	}
+	stage := ParallelConsumer(consumer, 4, 0, EventKey, true)
+	in := make(chan *uof.Message)
+	out, errc := stage(in)
+	go func() {
+		in <- uof.NewMarketsMessage(uof.LangEN, nil, 0, nil)
+		in <- uof.NewPlayerMessage(uof.LangEN, &uof.Player{ID: 1}, 0, nil)
+		close(in)
+	}()
+	for range out {
+	}
+	for err := range errc {
+		assert.NoError(t, err)
+	}
+	// lexicon is sent to each worker, not to the system lane
+	assert.Len(t, lexicon, 4)
+	for _, n := range lexicon {
+		assert.Equal(t, 2, n)
+	}
+}
+
+func TestShard(t *testing.T) {
+	assert.Equal(t, 1, shard(5, 4))
+	s := shard(-5, 4)
+	assert.True(t, s >= 0 && s < 4)
+}
@@ -94,30 +94,83 @@ func Consumer(consumer ConsumerStage) InnerStage {
 }
 
 func BufferedConsumer(consumer ConsumerStage, buffer int) InnerStage {
+	lanes := []consumerLane{{consumer: consumer, buffer: buffer}}
+	return fanOut(lanes, func(m *uof.Message, send func(lane int)) {
+		send(0)
+	})
+}
+
+// consumerLane is one consumer of the fan out stage.
+type consumerLane struct {
+	consumer ConsumerStage
+	buffer   int
+}
+
+// fanOut passes all messages from in to out and to the consumers of the lanes
+// selected by dispatch. Each consumer gets its own `in` chan of the lane buffer
+// size. Message is sent to the lanes before it is sent to out.
+//
+// Consumers get unpacked messages. Lazy message is unpacked in the lane, so
+// lanes of the parallel consumer parse in parallel. Malformed message is
+// reported to errc and not passed to the consumer.
+//
+// The same message is passed to all selected lanes and to out, consumers run
+// concurrently with each other and with the next stages. Messages are
+// read-only after the fan out: consumer must not modify the message, copy it
+// if it needs a changed one.
+func fanOut(lanes []consumerLane, dispatch func(m *uof.Message, send func(lane int))) InnerStage {
 	return func(in <-chan *uof.Message) (<-chan *uof.Message, <-chan error) {
 		out := make(chan *uof.Message)
-		looperIn := make(chan *uof.Message, buffer)
-		errc := make(chan error, 1)
+		looperIns := make([]chan *uof.Message, len(lanes))
+		for i, l := range lanes {
+			looperIns[i] = make(chan *uof.Message, l.buffer)
+		}
+		errc := make(chan error, len(lanes))
 
-		go func() { // tee in to out na looperIn
+		go func() { // tee in to out and to the lanes
 			defer close(out)
-			defer close(looperIn)
+			defer func() {
+				for _, c := range looperIns {
+					close(c)
+				}
+			}()
 			for m := range in {
-				looperIn <- m
+				dispatch(m, func(lane int) {
+					looperIns[lane] <- m
+				})
 				out <- m
 			}
 		}()
 
-		go func() {
-			defer close(errc)
-
-			if err := consumer(looperIn); err != nil {
-				errc <- err
-			}
-			go func() { // for unclean exit; drain this chan
-				for range looperIn {
+		var wg sync.WaitGroup
+		wg.Add(len(lanes))
+		for i, l := range lanes {
+			go func(consumer ConsumerStage, looperIn chan *uof.Message) {
+				defer wg.Done()
+				unpacked := make(chan *uof.Message)
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					if err := consumer(unpacked); err != nil {
+						errc <- err
+					}
+					for range unpacked { // for unclean exit; drain this chan
+					}
+				}()
+				for m := range looperIn {
+					if err := m.Unpack(); err != nil {
+						errc <- err
+						continue
+					}
+					unpacked <- m
 				}
-			}()
+				close(unpacked)
+				<-done
+			}(l.consumer, looperIns[i])
+		}
+		go func() {
+			wg.Wait()
+			close(errc)
 		}()
 		return out, errc
 	}
@@ -236,8 +289,11 @@ func Simple(each func(m *uof.Message) error) InnerStage {
 	}
 }
 
+// expireMap remembers keys for the expireAfter interval. Keys are ids, or
+// urns for the urn methods.
 type expireMap struct {
 	m        map[int]int
+	urns     map[uof.URN]int
 	interval time.Duration
 	sync.Mutex
 }
@@ -245,6 +301,7 @@ type expireMap struct {
 func newExpireMap(expireAfter time.Duration) *expireMap {
 	em := &expireMap{
 		m:        make(map[int]int),
+		urns:     make(map[uof.URN]int),
 		interval: expireAfter,
 	}
 	go func() {
@@ -263,6 +320,11 @@ func (em *expireMap) cleanup() {
 			delete(em.m, k)
 		}
 	}
+	for k, v := range em.urns {
+		if em.expired(v) {
+			delete(em.urns, k)
+		}
+	}
 }
 
 func (em *expireMap) expired(v int) bool {
@@ -279,6 +341,16 @@ func (em *expireMap) fresh(k int) bool {
 	return false
 }
 
+func (em *expireMap) freshURN(k uof.URN) bool {
+	em.Lock()
+	defer em.Unlock()
+
+	if v, ok := em.urns[k]; ok {
+		return v > em.checkpoint()
+	}
+	return false
+}
+
 func (em *expireMap) checkpoint() int {
 	return int(time.Now().UnixNano()) - int(em.interval)
 }
@@ -290,9 +362,41 @@ func (em *expireMap) insert(key int) {
 	em.m[key] = int(time.Now().UnixNano())
 }
 
+func (em *expireMap) insertURN(key uof.URN) {
+	em.Lock()
+	defer em.Unlock()
+
+	em.urns[key] = int(time.Now().UnixNano())
+}
+
 func (em *expireMap) remove(key int) {
 	em.Lock()
 	defer em.Unlock()
 
 	delete(em.m, key)
 }
+
+func (em *expireMap) removeURN(key uof.URN) {
+	em.Lock()
+	defer em.Unlock()
+
+	delete(em.urns, key)
+}
+
+// unpackBody unpacks body of the lazy message if it is of any of the types.
+// Stages which use message body call it before passing message to the next
+// stage. Malformed lazy message is handled the same as malformed message in
+// the queue: error is reported and message is dropped. Returns false if the
+// message should be dropped.
+func unpackBody(m *uof.Message, errc chan<- error, types ...uof.MessageType) bool {
+	for _, t := range types {
+		if m.Type == t {
+			if err := m.Unpack(); err != nil {
+				errc <- err
+				return false
+			}
+			return true
+		}
+	}
+	return true
+}
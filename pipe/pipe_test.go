@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/minus5/go-uof-sdk"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -11,4 +12,73 @@ func TestExpireMap(t *testing.T) {
 	em := newExpireMap(time.Minute)
 	em.insert(1)
 	assert.True(t, em.fresh(1))
+	assert.False(t, em.fresh(2))
+
+	// urns are separate from ids
+	em.insertURN("sr:match:1")
+	assert.True(t, em.freshURN("sr:match:1"))
+	assert.False(t, em.freshURN("vf:match:1"))
+	em.removeURN("sr:match:1")
+	assert.False(t, em.freshURN("sr:match:1"))
+	assert.True(t, em.fresh(1))
+}
+
+func TestUnpackBodyMalformed(t *testing.T) {
+	m, err := uof.NewLazyQueueMessage("hi.-.live.bet_stop.1.sr:match.1234.-", []byte(`<bet_stop product="1" timestamp="1234"><x`))
+	assert.NoError(t, err)
+	errc := make(chan error, 1)
+
+	// other types are not unpacked
+	assert.True(t, unpackBody(m, errc, uof.MessageTypeOddsChange))
+	assert.Len(t, errc, 0)
+
+	// malformed message is reported and dropped
+	assert.False(t, unpackBody(m, errc, uof.MessageTypeBetStop))
+	assert.Error(t, <-errc)
+
+	stage := BetStop()
+	in := make(chan *uof.Message, 2)
+	in <- m
+	in <- uof.NewConnnectionMessage(uof.ConnectionStatusUp)
+	close(in)
+	out, errs := stage(in)
+	go func() {
+		for range errs {
+		}
+	}()
+	om := <-out
+	assert.Equal(t, uof.MessageTypeConnection, om.Type)
+}
+
+func TestConsumerMalformed(t *testing.T) {
+	m, err := uof.NewLazyQueueMessage("hi.-.live.bet_cancel.1.sr:match.1234.-", []byte(`<bet_cancel product="1" timestamp="1234"><x`))
+	assert.NoError(t, err)
+	ok, err := uof.NewLazyQueueMessage("hi.-.live.bet_cancel.1.sr:match.1234.-", []byte(`<bet_cancel event_id="sr:match:1234" product="1" timestamp="1234"/>`))
+	assert.NoError(t, err)
+
+	var consumed []*uof.Message
+	stage := Consumer(func(in <-chan *uof.Message) error {
+		for m := range in {
+			consumed = append(consumed, m)
+		}
+		return nil
+	})
+	in := make(chan *uof.Message, 2)
+	in <- m
+	in <- ok
+	close(in)
+	out, errc := stage(in)
+	go func() {
+		for range out {
+		}
+	}()
+	var errs []error
+	for err := range errc {
+		errs = append(errs, err)
+	}
+	// malformed message is reported, consumer gets unpacked messages
+	assert.Len(t, errs, 1)
+	assert.Len(t, consumed, 1)
+	assert.Equal(t, ok, consumed[0])
+	assert.NotNil(t, consumed[0].BetCancel)
 }
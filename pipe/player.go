@@ -41,7 +41,9 @@ func (p *player) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc chan
 	p.errc, p.out = errc, out
 
 	requests := make(chan playerGetRequest, 1024)
+	p.subProcs.Add(1)
 	go func() {
+		defer p.subProcs.Done()
 		for req := range requests {
 			req.oddsChange.EachPlayer(func(playerID int) {
 				p.get(playerID, req.requestedAt)
@@ -50,6 +52,9 @@ func (p *player) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc chan
 	}()
 
 	for m := range in {
+		if !unpackBody(m, errc, uof.MessageTypeOddsChange) {
+			continue
+		}
 		out <- m
 		if m.Is(uof.MessageTypeOddsChange) {
 			requests <- playerGetRequest{oddsChange: m.OddsChange, requestedAt: m.ReceivedAt}
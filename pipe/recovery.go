@@ -33,13 +33,15 @@ type recoveryProducer struct {
 	recoveryRequestCancel context.CancelFunc
 }
 
-func (p *recoveryProducer) setStatus(newStatus uof.ProducerStatus) {
+// after is the last status change across all producers, status changes of
+// two producers in the same millisecond would otherwise be reported as one.
+func (p *recoveryProducer) setStatus(newStatus uof.ProducerStatus, after int) {
 	if p.status != newStatus {
 		p.status = newStatus
 		ct := uof.CurrentTimestamp()
-		if p.statusChangedAt >= ct {
-			// ensure monotonic increase (for tests)
-			ct = p.statusChangedAt + 1
+		if after >= ct {
+			// ensure monotonic increase
+			ct = after + 1
 		}
 		p.statusChangedAt = ct
 	}
@@ -100,7 +102,7 @@ func (r *recovery) cancelSubProcs() {
 }
 
 func (r *recovery) requestRecovery(p *recoveryProducer) {
-	p.setStatus(uof.ProducerStatusInRecovery)
+	p.setStatus(uof.ProducerStatusInRecovery, r.statusChangedAt())
 	p.requestID = r.nextRequestID()
 
 	if cancel := p.recoveryRequestCancel; cancel != nil {
@@ -173,7 +175,7 @@ func (r *recovery) snapshotComplete(producer uof.Producer, requestID int) {
 	if p.requestID != requestID {
 		r.log(fmt.Errorf("unexpected requestID %d, expected %d, for producer %s", requestID, p.requestID, producer))
 	}
-	p.setStatus(uof.ProducerStatusActive)
+	p.setStatus(uof.ProducerStatusActive, r.statusChangedAt())
 	p.requestID = 0
 }
 
@@ -189,7 +191,7 @@ func (r *recovery) connectionUp() {
 // set status of all producers to down
 func (r *recovery) connectionDown() {
 	for _, p := range r.producers {
-		p.setStatus(uof.ProducerStatusDown)
+		p.setStatus(uof.ProducerStatusDown, r.statusChangedAt())
 	}
 }
 
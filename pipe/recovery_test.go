@@ -2,6 +2,7 @@ package pipe
 
 import (
 	"testing"
+	"time"
 
 	"github.com/minus5/go-uof-sdk"
 	"github.com/stretchr/testify/assert"
@@ -206,3 +207,47 @@ func TestRecoveryRequests(t *testing.T) {
 	assert.Equal(t, uof.ProducerLiveOdds, producersChangeMessage.Producers[1].Producer)
 	assert.Equal(t, uof.ProducerStatusInRecovery, producersChangeMessage.Producers[1].Status)
 }
+
+// Status changes of two producers in the same millisecond are reported in two
+// producers change messages.
+func TestRecoverySameMillisecond(t *testing.T) {
+	var ps uof.ProducersChange
+	ps.Add(uof.ProducerPrematch, 0)
+	ps.Add(uof.ProducerLiveOdds, 0)
+	r := newRecovery(&recoveryAPIMock{}, ps)
+	// status changes in the future, current timestamp is not after them
+	future := uof.CurrentTimestamp() + 60*1000
+	for i, p := range r.producers {
+		p.status = uof.ProducerStatusInRecovery
+		p.requestID = i + 1
+		p.statusChangedAt = future
+	}
+	in := make(chan *uof.Message)
+	out := make(chan *uof.Message, 16)
+	go r.loop(in, out, make(chan error, 16))
+
+	var changes []uof.ProducersChange
+	for i, p := range r.producers {
+		in <- &uof.Message{
+			Header: uof.Header{Type: uof.MessageTypeSnapshotComplete},
+			Body: uof.Body{SnapshotComplete: &uof.SnapshotComplete{
+				Producer:  p.producer,
+				RequestID: i + 1},
+			},
+		}
+		<-out // snapshot complete
+		select {
+		case m := <-out:
+			changes = append(changes, m.Producers)
+		case <-time.After(time.Second):
+			t.Fatalf("producers change for %s not sent", p.producer)
+		}
+	}
+	close(in)
+
+	assert.Equal(t, uof.ProducerStatusActive, changes[0][0].Status)
+	assert.Equal(t, uof.ProducerStatusInRecovery, changes[0][1].Status)
+	assert.Equal(t, uof.ProducerStatusActive, changes[1][1].Status)
+	assert.Equal(t, future+1, changes[1][0].Timestamp)
+	assert.Equal(t, future+2, changes[1][1].Timestamp)
+}
@@ -0,0 +1,62 @@
+package pipe
+
+import (
+	"sync/atomic"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+// Route sends messages matched by Filter to the Consumer. Consumer gets `in`
+// chan of size Buffer.
+type Route struct {
+	Filter   Filter
+	Consumer ConsumerStage
+	Buffer   int
+}
+
+// Router dispatches messages to the consumers by routes.
+type Router struct {
+	routes    []Route
+	unmatched uint64
+}
+
+// NewRouter creates router with the routes. Message is sent to each route it
+// matches, in the order of the routes. Messages which match no route are
+// counted and can be retrieved by Unmatched.
+func NewRouter(routes ...Route) *Router {
+	return &Router{routes: routes}
+}
+
+// Unmatched returns number of messages which matched no route.
+func (r *Router) Unmatched() uint64 {
+	return atomic.LoadUint64(&r.unmatched)
+}
+
+// Stage returns router as pipe stage. All messages are also passed to the
+// next stage.
+//
+// Filters which select by event (EventURNs, Sports, Producers, Scopes) don't
+// match lexicon messages, they have no event. Combine them with
+// Any(..., Kinds(uof.MessageKindLexicon)) for the consumers which need lexicon.
+//
+// Filters see only the message header, lazy message is unpacked later, in
+// the route. Message which matches more than one route is passed to all of
+// them as the same instance, route consumers must not modify it.
+func (r *Router) Stage() InnerStage {
+	lanes := make([]consumerLane, len(r.routes))
+	for i, rt := range r.routes {
+		lanes[i] = consumerLane{consumer: rt.Consumer, buffer: rt.Buffer}
+	}
+	return fanOut(lanes, func(m *uof.Message, send func(lane int)) {
+		matched := false
+		for i, rt := range r.routes {
+			if rt.Filter == nil || rt.Filter(m) {
+				send(i)
+				matched = true
+			}
+		}
+		if !matched {
+			atomic.AddUint64(&r.unmatched, 1)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package pipe
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilters(t *testing.T) {
+	m := &uof.Message{Header: uof.Header{
+		Type:     uof.MessageTypeOddsChange,
+		Scope:    uof.MessageScopeLive,
+		Priority: uof.MessagePriorityHigh,
+		SportID:  1,
+		EventURN: "sr:match:1234",
+		Producer: uof.ProducerLiveOdds,
+	}}
+
+	assert.True(t, Types(uof.MessageTypeBetStop, uof.MessageTypeOddsChange)(m))
+	assert.False(t, Types(uof.MessageTypeBetStop)(m))
+	assert.True(t, Kinds(uof.MessageKindEvent)(m))
+	assert.True(t, Producers(uof.ProducerLiveOdds)(m))
+	assert.False(t, Producers(uof.ProducerPrematch)(m))
+	assert.True(t, Sports(1, 2)(m))
+	assert.False(t, Sports(2)(m))
+	assert.True(t, Scopes(uof.MessageScopeLive)(m))
+	assert.True(t, Priorities(uof.MessagePriorityHigh)(m))
+	assert.False(t, Langs(uof.LangEN)(m))
+	assert.True(t, EventURNs("vf:*", "sr:match:*")(m))
+	assert.False(t, EventURNs("sr:stage:*")(m))
+	assert.False(t, EventURNs("[")(m))
+
+	assert.True(t, All(Sports(1), Scopes(uof.MessageScopeLive))(m))
+	assert.False(t, All(Sports(1), Scopes(uof.MessageScopePrematch))(m))
+	assert.True(t, Any(Sports(2), Scopes(uof.MessageScopeLive))(m))
+	assert.True(t, Not(Sports(2))(m))
+}
+
+func TestRouter(t *testing.T) {
+	counter := func(cnt *int) ConsumerStage {
+		return func(in <-chan *uof.Message) error {
+			for range in {
+				*cnt++
+			}
+			return nil
+		}
+	}
+	var odds, system, all int
+	r := NewRouter(
+		Route{Filter: Types(uof.MessageTypeOddsChange), Consumer: counter(&odds)},
+		Route{Filter: Kinds(uof.MessageKindSystem), Consumer: counter(&system), Buffer: 16},
+		Route{Filter: Sports(1), Consumer: counter(&all)},
+	)
+	in := make(chan *uof.Message)
+	out, errc := r.Stage()(in)
+	go func() {
+		in <- uof.NewConnnectionMessage(uof.ConnectionStatusUp)
+		in <- &uof.Message{Header: uof.Header{Type: uof.MessageTypeOddsChange, SportID: 1}}
+		in <- &uof.Message{Header: uof.Header{Type: uof.MessageTypeOddsChange, SportID: 2}}
+		in <- &uof.Message{Header: uof.Header{Type: uof.MessageTypeBetStop, SportID: 2}}
+		close(in)
+	}()
+	cnt := 0
+	for range out {
+		cnt++
+	}
+	for err := range errc {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 4, cnt)
+	assert.Equal(t, 2, odds)
+	assert.Equal(t, 1, system)
+	assert.Equal(t, 1, all)
+	assert.Equal(t, uint64(1), r.Unmatched())
+}
+
+func TestRouterLazy(t *testing.T) {
+	var mu sync.Mutex
+	var consumed []*uof.Message
+	consumer := func(in <-chan *uof.Message) error {
+		for m := range in {
+			mu.Lock()
+			consumed = append(consumed, m)
+			mu.Unlock()
+		}
+		return nil
+	}
+	r := NewRouter(
+		Route{Filter: Types(uof.MessageTypeBetCancel), Consumer: consumer},
+		Route{Filter: Sports(1), Consumer: consumer},
+	)
+	m, err := uof.NewLazyQueueMessage("hi.-.live.bet_cancel.1.sr:match.1234.-",
+		[]byte(`<bet_cancel event_id="sr:match:1234" product="1" timestamp="1234"/>`))
+	assert.NoError(t, err)
+	in := make(chan *uof.Message, 1)
+	in <- m
+	close(in)
+	out, errc := r.Stage()(in)
+	for range out {
+	}
+	for err := range errc {
+		assert.NoError(t, err)
+	}
+	// both routes get the same unpacked message
+	assert.Len(t, consumed, 2)
+	assert.True(t, consumed[0] == consumed[1])
+	assert.NotNil(t, consumed[0].BetCancel)
+}
@@ -29,6 +29,9 @@ func InnerFileStore(root string) InnerStage {
 	})
 }
 
+// FileStore stores messages as json. Consumer wrappers (Consumer,
+// ParallelConsumer...) pass only unpacked messages, malformed lazy message is
+// reported to the pipe errors before it gets here.
 func FileStore(root string) ConsumerStage {
 	return func(in <-chan *uof.Message) error {
 		for m := range in {
@@ -41,6 +44,26 @@ func FileStore(root string) ConsumerStage {
 	}
 }
 
+// BinaryFileStore stores messages in the compact binary format, see
+// uof.Message.MarshalBinary. Files written by older binary versions are still
+// readable by uof.Message.UnmarshalBinary. Malformed lazy message is reported
+// by the consumer wrapper, same as in FileStore.
+func BinaryFileStore(root string) ConsumerStage {
+	return func(in <-chan *uof.Message) error {
+		for m := range in {
+			buf, err := m.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			fn := root + "/" + filename(m)
+			if err := save(fn, buf); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 // filename returns unique filename for the message
 func filename(m *uof.Message) string {
 	producer := m.Producer.Code()
@@ -50,6 +73,9 @@ func filename(m *uof.Message) string {
 
 	switch m.Type.Kind() {
 	case uof.MessageKindEvent:
+		if m.Type == uof.MessageTypeCashoutProbabilities {
+			return fmt.Sprintf("/state/%s/cashout/%d/%13d", producer, m.EventID, m.ReceivedAt)
+		}
 		if m.Type == uof.MessageTypeOddsChange {
 			return fmt.Sprintf("/log/events/%s/%d/%13d", producer, m.EventID, m.ReceivedAt)
 		}
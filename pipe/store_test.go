@@ -0,0 +1,44 @@
+package pipe
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryFileStore(t *testing.T) {
+	root, err := ioutil.TempDir("", "uof")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	m := uof.NewConnnectionMessage(uof.ConnectionStatusUp)
+	in := make(chan *uof.Message, 1)
+	in <- m
+	close(in)
+	assert.NoError(t, BinaryFileStore(root)(in))
+
+	buf, err := ioutil.ReadFile(root + "/" + filename(m))
+	assert.NoError(t, err)
+	var c uof.Message
+	assert.NoError(t, c.UnmarshalBinary(buf))
+	assert.Equal(t, m.Header, c.Header)
+	assert.Equal(t, m.Body, c.Body)
+}
+
+func TestLazyMessageFilename(t *testing.T) {
+	buf := []byte(`<bet_cancel event_id="sr:match:1234" product="1" timestamp="1234"/>`)
+	key := "hi.-.live.bet_cancel.1.sr:match.1234.-"
+	lm, err := uof.NewLazyQueueMessage(key, buf)
+	assert.NoError(t, err)
+	m, err := uof.NewQueueMessage(key, buf)
+	assert.NoError(t, err)
+	m.ReceivedAt = lm.ReceivedAt
+
+	// lazy message is stored under the same producer
+	assert.Equal(t, filename(m), filename(lm))
+	assert.True(t, Producers(uof.ProducerLiveOdds)(lm))
+	assert.Nil(t, lm.BetCancel)
+}
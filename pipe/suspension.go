@@ -0,0 +1,213 @@
+package pipe
+
+import (
+	"sort"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+type openEvent struct {
+	sportID   int
+	active    map[lineKey]struct{} // active market lines
+	updatedAt int
+}
+
+type suspendedEvent struct {
+	sportID     int
+	suspendedAt int
+}
+
+type suspension struct {
+	status    map[uof.Producer]uof.ProducerStatus
+	open      map[uof.Producer]map[uof.URN]*openEvent
+	suspended map[uof.Producer]map[uof.URN]suspendedEvent
+	sweptAt   int
+}
+
+// Suspension emits SDK generated bet stop messages (Header.Generated is set,
+// BetStop.Origin is BetStopOriginProducerDown) when producer goes down. Bet
+// stop is emitted for each event on which the producer has active markets.
+// When the producer is active again, after the recovery is completed, bet
+// stop lift message (MessageTypeBetStopLift) with the same origin is emitted
+// for the same events, as a signal that generated suspension is lifted. It
+// lifts only suspensions of that origin, not the ones made by Handover or
+// NextBetstop. Actual markets state is already delivered by the recovery odds
+// changes.
+//
+// Events without updates for 12 hours are forgotten, suspended events which
+// are not lifted in that time too.
+//
+// Stage should be placed after the Recovery stage, which sends producers
+// status changes.
+func Suspension() InnerStage {
+	return Stage(newSuspension().loop)
+}
+
+func newSuspension() *suspension {
+	return &suspension{
+		status:    make(map[uof.Producer]uof.ProducerStatus),
+		open:      make(map[uof.Producer]map[uof.URN]*openEvent),
+		suspended: make(map[uof.Producer]map[uof.URN]suspendedEvent),
+	}
+}
+
+func (s *suspension) loop(in <-chan *uof.Message, out chan<- *uof.Message, errc chan<- error) {
+	for m := range in {
+		if !unpackBody(m, errc, uof.MessageTypeOddsChange, uof.MessageTypeBetStop, uof.MessageTypeBetSettlement) {
+			continue
+		}
+		out <- m
+		switch m.Type {
+		case uof.MessageTypeOddsChange:
+			s.oddsChange(m)
+		case uof.MessageTypeBetStop:
+			s.betStop(m)
+		case uof.MessageTypeBetSettlement:
+			s.betSettlement(m)
+		case uof.MessageTypeProducersChange:
+			for _, g := range s.producersChange(m) {
+				out <- g
+			}
+		}
+		s.sweep(m.ReceivedAt)
+	}
+}
+
+// sweep removes events without updates and suspensions not lifted in
+// eventStateTTL
+func (s *suspension) sweep(now int) {
+	if now-s.sweptAt < eventStateTTL/12 {
+		return
+	}
+	s.sweptAt = now
+	for _, events := range s.open {
+		for urn, e := range events {
+			if now-e.updatedAt > eventStateTTL {
+				delete(events, urn)
+			}
+		}
+	}
+	for _, events := range s.suspended {
+		for urn, e := range events {
+			if now-e.suspendedAt > eventStateTTL {
+				delete(events, urn)
+			}
+		}
+	}
+}
+
+func (s *suspension) event(producer uof.Producer, urn uof.URN) (*openEvent, bool) {
+	events, ok := s.open[producer]
+	if !ok {
+		return nil, false
+	}
+	e, ok := events[urn]
+	return e, ok
+}
+
+func (s *suspension) removeIfClosed(producer uof.Producer, urn uof.URN) {
+	if e, ok := s.event(producer, urn); ok && len(e.active) == 0 {
+		delete(s.open[producer], urn)
+	}
+}
+
+func (s *suspension) oddsChange(m *uof.Message) {
+	if m.OddsChange == nil || m.EventURN == uof.NoURN {
+		return
+	}
+	e, ok := s.event(m.Producer, m.EventURN)
+	if !ok {
+		events, ok := s.open[m.Producer]
+		if !ok {
+			events = make(map[uof.URN]*openEvent)
+			s.open[m.Producer] = events
+		}
+		e = &openEvent{active: make(map[lineKey]struct{})}
+		events[m.EventURN] = e
+	}
+	e.sportID, e.updatedAt = m.SportID, m.ReceivedAt
+	for _, mkt := range m.OddsChange.Markets {
+		k := lineKey{id: mkt.ID, lineID: mkt.LineID}
+		if mkt.Status == uof.MarketStatusActive {
+			e.active[k] = struct{}{}
+		} else {
+			delete(e.active, k)
+		}
+	}
+	s.removeIfClosed(m.Producer, m.EventURN)
+}
+
+func (s *suspension) betStop(m *uof.Message) {
+	if m.BetStop == nil || m.Generated {
+		return
+	}
+	e, ok := s.event(m.Producer, m.EventURN)
+	if !ok {
+		return
+	}
+	for k := range e.active {
+		if betStopCovers(m.BetStop, k.id) {
+			delete(e.active, k)
+		}
+	}
+	s.removeIfClosed(m.Producer, m.EventURN)
+}
+
+func (s *suspension) betSettlement(m *uof.Message) {
+	if m.BetSettlement == nil {
+		return
+	}
+	e, ok := s.event(m.Producer, m.EventURN)
+	if !ok {
+		return
+	}
+	for _, mkt := range m.BetSettlement.Markets {
+		delete(e.active, lineKey{id: mkt.ID, lineID: mkt.LineID})
+	}
+	s.removeIfClosed(m.Producer, m.EventURN)
+}
+
+// producersChange returns generated messages for the producers status changes
+func (s *suspension) producersChange(m *uof.Message) []*uof.Message {
+	var generated []*uof.Message
+	for _, pc := range m.Producers {
+		prev := s.status[pc.Producer]
+		s.status[pc.Producer] = pc.Status
+		p := pc.Producer
+
+		if prev == uof.ProducerStatusActive && pc.Status != uof.ProducerStatusActive {
+			suspended, ok := s.suspended[p]
+			if !ok {
+				suspended = make(map[uof.URN]suspendedEvent)
+				s.suspended[p] = suspended
+			}
+			var urns []uof.URN
+			for urn := range s.open[p] {
+				urns = append(urns, urn)
+			}
+			sort.Slice(urns, func(i, j int) bool { return urns[i] < urns[j] })
+			for _, urn := range urns {
+				sportID := s.open[p][urn].sportID
+				g := uof.NewBetStopMessage(p, urn, nil, uof.MarketStatusSuspended, uof.BetStopOriginProducerDown)
+				g.SportID = sportID
+				generated = append(generated, g)
+				suspended[urn] = suspendedEvent{sportID: sportID, suspendedAt: m.ReceivedAt}
+			}
+		}
+
+		if pc.Status == uof.ProducerStatusActive && len(s.suspended[p]) > 0 {
+			var urns []uof.URN
+			for urn := range s.suspended[p] {
+				urns = append(urns, urn)
+			}
+			sort.Slice(urns, func(i, j int) bool { return urns[i] < urns[j] })
+			for _, urn := range urns {
+				g := uof.NewBetStopLiftMessage(p, urn, nil, uof.BetStopOriginProducerDown)
+				g.SportID = s.suspended[p][urn].sportID
+				generated = append(generated, g)
+			}
+			delete(s.suspended, p)
+		}
+	}
+	return generated
+}
@@ -0,0 +1,72 @@
+package pipe
+
+import (
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func producersChangeMessage(p uof.Producer, status uof.ProducerStatus) *uof.Message {
+	pc := uof.ProducersChange{}
+	pc.Add(p, 0)
+	pc[0].Status = status
+	return uof.NewProducersChangeMessage(pc)
+}
+
+func TestSuspension(t *testing.T) {
+	in := make(chan *uof.Message)
+	out, errc := Suspension()(in)
+	go func() {
+		in <- producersChangeMessage(uof.ProducerLiveOdds, uof.ProducerStatusActive)
+		in <- oddsDeltaMessage(t, "odds_change", "1", `<odds><market id="1"><outcome id="1" odds="1.5"/></market></odds>`)
+		in <- producersChangeMessage(uof.ProducerLiveOdds, uof.ProducerStatusDown)
+		in <- producersChangeMessage(uof.ProducerLiveOdds, uof.ProducerStatusInRecovery)
+		in <- producersChangeMessage(uof.ProducerLiveOdds, uof.ProducerStatusActive)
+		close(in)
+	}()
+	var generated []*uof.Message
+	for m := range out {
+		if m.Generated {
+			generated = append(generated, m)
+		}
+	}
+	for err := range errc {
+		assert.NoError(t, err)
+	}
+	assert.Len(t, generated, 2)
+	assert.Equal(t, uof.MessageTypeBetStop, generated[0].Type)
+	assert.Equal(t, uof.URN("sr:match:1"), generated[0].EventURN)
+	assert.Equal(t, uof.MarketStatusSuspended, generated[0].BetStop.Status)
+	assert.Equal(t, uof.MessageTypeBetStopLift, generated[1].Type)
+	assert.Equal(t, uof.BetStopOriginProducerDown, generated[1].BetStop.Origin)
+}
+
+func TestSuspensionClosedEvent(t *testing.T) {
+	s := newSuspension()
+	s.producersChange(producersChangeMessage(uof.ProducerLiveOdds, uof.ProducerStatusActive))
+	s.oddsChange(oddsDeltaMessage(t, "odds_change", "1", `<odds><market id="1"><outcome id="1" odds="1.5"/></market></odds>`))
+	assert.Len(t, s.open[uof.ProducerLiveOdds], 1)
+	s.betStop(oddsDeltaMessage(t, "bet_stop", "1", ""))
+	assert.Len(t, s.open[uof.ProducerLiveOdds], 0)
+	assert.Len(t, s.producersChange(producersChangeMessage(uof.ProducerLiveOdds, uof.ProducerStatusDown)), 0)
+}
+
+func TestSuspensionSweep(t *testing.T) {
+	s := newSuspension()
+	s.producersChange(producersChangeMessage(uof.ProducerLiveOdds, uof.ProducerStatusActive))
+	oc := oddsDeltaMessage(t, "odds_change", "1", `<odds><market id="1"><outcome id="1" odds="1.5"/></market></odds>`)
+	oc.ReceivedAt = 1000
+	s.oddsChange(oc)
+	down := producersChangeMessage(uof.ProducerLiveOdds, uof.ProducerStatusDown)
+	down.ReceivedAt = 2000
+	assert.Len(t, s.producersChange(down), 1)
+
+	s.sweep(1000 + eventStateTTL)
+	assert.Len(t, s.open[uof.ProducerLiveOdds], 1)
+	assert.Len(t, s.suspended[uof.ProducerLiveOdds], 1)
+	s.sweep(2000 + eventStateTTL + eventStateTTL/12)
+	assert.Len(t, s.open[uof.ProducerLiveOdds], 0)
+	assert.Len(t, s.suspended[uof.ProducerLiveOdds], 0)
+	assert.Len(t, s.producersChange(producersChangeMessage(uof.ProducerLiveOdds, uof.ProducerStatusActive)), 0)
+}
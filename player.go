@@ -11,18 +11,18 @@ type PlayerProfile struct {
 }
 
 type Player struct {
-	ID           int       `xml:"id,attr" json:"id" bson:"id,omitempty"`
-	Type         string    `xml:"type,attr,omitempty" json:"type,omitempty" bson:"type,omitempty"`
-	DateOfBirth  time.Time `xml:"date_of_birth,attr,omitempty" json:"dateOfBirth,omitempty" bson:"dateOfBirth,omitempty"`
-	Nationality  string    `xml:"nationality,attr,omitempty" json:"nationality,omitempty" bson:"nationality,omitempty"`
-	CountryCode  string    `xml:"country_code,attr,omitempty" json:"countryCode,omitempty" bson:"countryCode,omitempty"`
-	Height       int       `xml:"height,attr,omitempty" json:"height,omitempty" bson:"height,omitempty"`
-	Weight       int       `xml:"weight,attr,omitempty" json:"weight,omitempty" bson:"weight,omitempty"`
-	JerseyNumber int       `xml:"jersey_number,attr,omitempty" json:"jerseyNumber,omitempty" bson:"jerseyNumber,omitempty"`
-	Name         string    `xml:"name,attr,omitempty" json:"name,omitempty" bson:"name,omitempty"`
-	FullName     string    `xml:"full_name,attr,omitempty" json:"fullName,omitempty" bson:"fullName,omitempty"`
-	Nickname     string    `xml:"nickname,attr,omitempty" json:"nickname,omitempty" bson:"nickname,omitempty"`
-	Gender       Gender    `xml:"gender,attr,omitempty" json:"gender,omitempty" bson:"gender,omitempty"`
+	ID           int       `xml:"id,attr" json:"id" bson:"id,omitempty" uof:"1"`
+	Type         string    `xml:"type,attr,omitempty" json:"type,omitempty" bson:"type,omitempty" uof:"2"`
+	DateOfBirth  time.Time `xml:"date_of_birth,attr,omitempty" json:"dateOfBirth,omitempty" bson:"dateOfBirth,omitempty" uof:"3"`
+	Nationality  string    `xml:"nationality,attr,omitempty" json:"nationality,omitempty" bson:"nationality,omitempty" uof:"4"`
+	CountryCode  string    `xml:"country_code,attr,omitempty" json:"countryCode,omitempty" bson:"countryCode,omitempty" uof:"5"`
+	Height       int       `xml:"height,attr,omitempty" json:"height,omitempty" bson:"height,omitempty" uof:"6"`
+	Weight       int       `xml:"weight,attr,omitempty" json:"weight,omitempty" bson:"weight,omitempty" uof:"7"`
+	JerseyNumber int       `xml:"jersey_number,attr,omitempty" json:"jerseyNumber,omitempty" bson:"jerseyNumber,omitempty" uof:"8"`
+	Name         string    `xml:"name,attr,omitempty" json:"name,omitempty" bson:"name,omitempty" uof:"9"`
+	FullName     string    `xml:"full_name,attr,omitempty" json:"fullName,omitempty" bson:"fullName,omitempty" uof:"10"`
+	Nickname     string    `xml:"nickname,attr,omitempty" json:"nickname,omitempty" bson:"nickname,omitempty" uof:"11"`
+	Gender       Gender    `xml:"gender,attr,omitempty" json:"gender,omitempty" bson:"gender,omitempty" uof:"12"`
 }
 
 func (t *Player) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
@@ -37,7 +37,12 @@ func (t *Player) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	if err := d.DecodeElement(&overlay, &start); err != nil {
 		return err
 	}
-	t.ID = toPlayerID(overlay.ID)
+	if overlay.ID != "" {
+		var err error
+		if t.ID, err = toURNID(overlay.ID, URNTypePlayer); err != nil {
+			return err
+		}
+	}
 	t.DateOfBirth = dateToTime(overlay.DateOfBirth)
 	t.Gender = toGender(overlay.Gender)
 	return nil
@@ -3,10 +3,10 @@ package uof
 type ProducersChange []ProducerChange
 
 type ProducerChange struct {
-	Producer   Producer       `json:"producer" bson:"producer"`
-	Status     ProducerStatus `json:"status" bson:"status"`
-	RecoveryID int            `json:"recoveryId,omitempty" bson:"recoveryId,omitempty"`
-	Timestamp  int            `json:"timestamp,omitempty" bson:"timestamp,omitempty"`
+	Producer   Producer       `json:"producer" bson:"producer" uof:"1"`
+	Status     ProducerStatus `json:"status" bson:"status" uof:"2"`
+	RecoveryID int            `json:"recoveryId,omitempty" bson:"recoveryId,omitempty" uof:"3"`
+	Timestamp  int            `json:"timestamp,omitempty" bson:"timestamp,omitempty" uof:"4"`
 }
 
 func (p *ProducersChange) Add(producer Producer, timestamp int) {
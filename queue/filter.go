@@ -0,0 +1,92 @@
+package queue
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+// Filter decides by the message header, parsed from the routing key, whether
+// the message should be unpacked and passed to the pipe.
+type Filter func(h *uof.Header) bool
+
+// SportsFilter passes messages for any of the sports.
+func SportsFilter(sportIDs ...int) Filter {
+	return func(h *uof.Header) bool {
+		for _, id := range sportIDs {
+			if h.SportID == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// EventTypesFilter passes messages for the events of any of the types. Event
+// type is urn without id, for example: "sr:match", "vf:season".
+func EventTypesFilter(eventTypes ...string) Filter {
+	return func(h *uof.Header) bool {
+		for _, t := range eventTypes {
+			if strings.HasPrefix(h.EventURN.String(), t+":") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MessageTypesFilter passes messages of any of the types.
+func MessageTypesFilter(types ...uof.MessageType) Filter {
+	return func(h *uof.Header) bool {
+		for _, t := range types {
+			if h.Type == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// BindingKeys creates queue binding keys for messages of the sports with the
+// priorities. Empty sports or priorities list means all. Binding key for the
+// system messages is always included.
+//
+// Routing key format is:
+//
+//	<priority>.<pre>.<live>.<message_type>.<sport_id>.<urn_type>.<event_id>.<node_id>
+//
+// Reference: https://docs.betradar.com/display/BD/UOF+-+Messages
+func BindingKeys(sportIDs []int, priorities []uof.MessagePriority) []string {
+	if len(sportIDs) == 0 && len(priorities) == 0 {
+		return []string{bindingKeyAll}
+	}
+	sports := []string{"*"}
+	if len(sportIDs) > 0 {
+		sports = sports[:0]
+		for _, id := range sportIDs {
+			sports = append(sports, fmt.Sprintf("%d", id))
+		}
+	}
+	prios := []string{"*"}
+	if len(priorities) > 0 {
+		prios = prios[:0]
+		for _, p := range priorities {
+			prios = append(prios, priorityCode(p))
+		}
+	}
+	var keys []string
+	for _, p := range prios {
+		for _, s := range sports {
+			keys = append(keys, fmt.Sprintf("%s.*.*.*.%s.#", p, s))
+		}
+	}
+	return append(keys, bindingKeySystem)
+}
+
+func priorityCode(p uof.MessagePriority) string {
+	if p == uof.MessagePriorityHigh {
+		return "hi"
+	}
+	return "lo"
+}
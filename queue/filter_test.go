@@ -0,0 +1,41 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindingKeys(t *testing.T) {
+	assert.Equal(t, []string{bindingKeyAll}, BindingKeys(nil, nil))
+	assert.Equal(t, []string{"*.*.*.*.1.#", "*.*.*.*.2.#", bindingKeySystem}, BindingKeys([]int{1, 2}, nil))
+	assert.Equal(t, []string{"hi.*.*.*.*.#", bindingKeySystem}, BindingKeys(nil, []uof.MessagePriority{uof.MessagePriorityHigh}))
+	assert.Equal(t, []string{"hi.*.*.*.5.#", "lo.*.*.*.5.#", bindingKeySystem},
+		BindingKeys([]int{5}, []uof.MessagePriority{uof.MessagePriorityHigh, uof.MessagePriorityLow}))
+}
+
+func TestFilters(t *testing.T) {
+	h, err := uof.ParseRoutingKey("hi.-.live.odds_change.4.sr:match.11784628.-")
+	assert.NoError(t, err)
+
+	assert.True(t, SportsFilter(1, 4)(&h))
+	assert.False(t, SportsFilter(1)(&h))
+	assert.True(t, EventTypesFilter("vf:match", "sr:match")(&h))
+	assert.False(t, EventTypesFilter("sr:stage")(&h))
+	assert.True(t, MessageTypesFilter(uof.MessageTypeOddsChange)(&h))
+	assert.False(t, MessageTypesFilter(uof.MessageTypeBetStop)(&h))
+
+	c := &Connection{}
+	assert.True(t, c.pass(&h))
+	c.Filter(SportsFilter(1))
+	assert.False(t, c.pass(&h))
+	c.Filter(SportsFilter(4), MessageTypesFilter(uof.MessageTypeOddsChange))
+	assert.True(t, c.pass(&h))
+
+	// system messages are always passing
+	h, err = uof.ParseRoutingKey("-.-.-.alive.-.-.-.-")
+	assert.NoError(t, err)
+	c.Filter(SportsFilter(1))
+	assert.True(t, c.pass(&h))
+}
@@ -8,6 +8,7 @@ package queue
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 
 	"github.com/minus5/go-uof-sdk"
@@ -38,13 +39,20 @@ const (
 
 // Dial connects to the queue chosen by environment
 func Dial(ctx context.Context, env uof.Environment, bookmakerID, token string, bind int8) (*Connection, error) {
+	return DialBindingKeys(ctx, env, bookmakerID, token, bindingKeysFor(bind))
+}
+
+// DialBindingKeys connects to the queue chosen by environment and binds to the
+// bindingKeys. Use BindingKeys to create keys for specific sports and
+// priorities.
+func DialBindingKeys(ctx context.Context, env uof.Environment, bookmakerID, token string, bindingKeys []string) (*Connection, error) {
 	switch env {
 	case uof.Replay:
-		return DialReplay(ctx, bookmakerID, token, bind)
+		return dial(ctx, replayServer, bookmakerID, token, bindingKeys)
 	case uof.Staging:
-		return DialStaging(ctx, bookmakerID, token, bind)
+		return dial(ctx, stagingServer, bookmakerID, token, bindingKeys)
 	case uof.Production:
-		return DialProduction(ctx, bookmakerID, token, bind)
+		return dial(ctx, productionServer, bookmakerID, token, bindingKeys)
 	default:
 		return nil, uof.Notice("queue dial", fmt.Errorf("unknown environment %d", env))
 	}
@@ -52,23 +60,69 @@ func Dial(ctx context.Context, env uof.Environment, bookmakerID, token string, b
 
 // Dial connects to the production queue
 func DialProduction(ctx context.Context, bookmakerID, token string, bind int8) (*Connection, error) {
-	return dial(ctx, productionServer, bookmakerID, token, bind)
+	return dial(ctx, productionServer, bookmakerID, token, bindingKeysFor(bind))
 }
 
 // DialStaging connects to the staging queue
 func DialStaging(ctx context.Context, bookmakerID, token string, bind int8) (*Connection, error) {
-	return dial(ctx, stagingServer, bookmakerID, token, bind)
+	return dial(ctx, stagingServer, bookmakerID, token, bindingKeysFor(bind))
 }
 
 // DialReplay connects to the replay server
 func DialReplay(ctx context.Context, bookmakerID, token string, bind int8) (*Connection, error) {
-	return dial(ctx, replayServer, bookmakerID, token, bind)
+	return dial(ctx, replayServer, bookmakerID, token, bindingKeysFor(bind))
+}
+
+// ErrSourceEnded should be returned from the Source dial when there are no
+// more deliveries. Connection is then not redialed.
+var ErrSourceEnded = errors.New("source ended")
+
+// Source creates connection which consumes deliveries from the chan returned
+// by dial instead of the amqp server. Dial is called on connect and on each
+// reconnect, connection is lost when the deliveries chan is closed. Used for
+// testing with the in process message source.
+func Source(dial func() (<-chan amqp.Delivery, error)) (*Connection, error) {
+	msgs, err := dial()
+	if err != nil {
+		return nil, uof.Notice("conn.Source", err)
+	}
+	errs := make(chan *amqp.Error)
+	close(errs)
+	return &Connection{
+		msgs: msgs,
+		errs: errs,
+		reDial: func() (*Connection, error) {
+			return Source(dial)
+		},
+	}, nil
 }
 
 type Connection struct {
-	msgs   <-chan amqp.Delivery
-	errs   <-chan *amqp.Error
-	reDial func() (*Connection, error)
+	msgs    <-chan amqp.Delivery
+	errs    <-chan *amqp.Error
+	reDial  func() (*Connection, error)
+	filters []Filter
+	lazy    bool
+	capture func(routingKey string, body []byte)
+}
+
+// Lazy sets connection to create lazy messages. Body of the lazy message is
+// unpacked on the first call to Message.Unpack, so stages which only pass or
+// store raw message don't pay the unpacking cost.
+func (c *Connection) Lazy() {
+	c.lazy = true
+}
+
+// Filter sets filters which are applied on the message header, parsed from
+// the routing key, before the message body is unpacked. Only messages which
+// pass all filters are unpacked. System messages are never filtered out.
+func (c *Connection) Filter(filters ...Filter) {
+	c.filters = filters
+}
+
+// Capture calls cb with each delivery, before filtering and parsing.
+func (c *Connection) Capture(cb func(routingKey string, body []byte)) {
+	c.capture = cb
 }
 
 func (c *Connection) Listen() (<-chan *uof.Message, <-chan error) {
@@ -93,8 +147,19 @@ func (c *Connection) drain(out chan<- *uof.Message, errc chan<- error) {
 		close(errsDone)
 	}()
 
-	for m := range c.msgs {
-		m, err := uof.NewQueueMessage(m.RoutingKey, m.Body)
+	for d := range c.msgs {
+		if c.capture != nil {
+			c.capture(d.RoutingKey, d.Body)
+		}
+		h, err := uof.ParseRoutingKey(d.RoutingKey)
+		if err != nil {
+			errc <- uof.Notice("conn.DeliveryParse", err)
+			continue
+		}
+		if !c.pass(&h) {
+			continue
+		}
+		m, err := c.newMessage(h, d.Body)
 		if err != nil {
 			errc <- uof.Notice("conn.DeliveryParse", err)
 			continue
@@ -104,18 +169,38 @@ func (c *Connection) drain(out chan<- *uof.Message, errc chan<- error) {
 	<-errsDone
 }
 
-func dial(ctx context.Context, server, bookmakerID, token string, bind int8) (*Connection, error) {
-	addr := fmt.Sprintf("amqps://%s:@%s//unifiedfeed/%s", token, server, bookmakerID)
+func (c *Connection) newMessage(h uof.Header, body []byte) (*uof.Message, error) {
+	if c.lazy {
+		return uof.NewLazyQueueMessageWithHeader(h, body)
+	}
+	return uof.NewQueueMessageWithHeader(h, body)
+}
 
-	var bindingKeys []string
+func (c *Connection) pass(h *uof.Header) bool {
+	if h.Type.Kind() == uof.MessageKindSystem {
+		return true
+	}
+	for _, f := range c.filters {
+		if !f(h) {
+			return false
+		}
+	}
+	return true
+}
+
+func bindingKeysFor(bind int8) []string {
 	switch bind {
 	case BindVirtuals:
-		bindingKeys = []string{bindingKeyVirtuals, bindingKeySystem}
+		return []string{bindingKeyVirtuals, bindingKeySystem}
 	case BindSports:
-		bindingKeys = []string{bindingKeyPrematch, bindingKeyLive, bindingKeySystem}
+		return []string{bindingKeyPrematch, bindingKeyLive, bindingKeySystem}
 	default:
-		bindingKeys = []string{bindingKeyAll}
+		return []string{bindingKeyAll}
 	}
+}
+
+func dial(ctx context.Context, server, bookmakerID, token string, bindingKeys []string) (*Connection, error) {
+	addr := fmt.Sprintf("amqps://%s:@%s//unifiedfeed/%s", token, server, bookmakerID)
 
 	tls := &tls.Config{
 		ServerName:         server,
@@ -181,7 +266,7 @@ func dial(ctx context.Context, server, bookmakerID, token string, bind int8) (*C
 		msgs: msgs,
 		errs: errs,
 		reDial: func() (*Connection, error) {
-			return dial(ctx, server, bookmakerID, token, bind)
+			return dial(ctx, server, bookmakerID, token, bindingKeys)
 		},
 	}
 
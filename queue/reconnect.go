@@ -31,8 +31,14 @@ func WithReconnect(ctx context.Context, conn *Connection) func() (<-chan *uof.Me
 
 		reconnect := func() error {
 			nc, err := conn.reDial()
+			if errors.Is(err, ErrSourceEnded) {
+				return backoff.Permanent(err)
+			}
 			if err == nil {
 				// TODO send reconnect notification
+				nc.filters = conn.filters
+				nc.lazy = conn.lazy
+				nc.capture = conn.capture
 				conn = nc // replace existing with new connection
 			}
 			if err != nil {
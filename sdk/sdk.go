@@ -9,23 +9,34 @@ import (
 	"github.com/minus5/go-uof-sdk/api"
 	"github.com/minus5/go-uof-sdk/pipe"
 	"github.com/minus5/go-uof-sdk/queue"
+	"github.com/minus5/go-uof-sdk/session"
 )
 
 var defaultLanuages = uof.Languages("en,de")
 
 type Config struct {
-	BookmakerID   string
-	Token         string
-	Fixtures      time.Time
-	Recovery      []uof.ProducerChange
-	Stages        []pipe.InnerStage
-	Replay        func(*api.ReplayAPI) error
-	Env           uof.Environment
-	Staging       bool
-	BindVirtuals  bool
-	BindSports    bool
-	Languages     []uof.Lang
-	BookLiveEvery time.Duration
+	BookmakerID      string
+	Token            string
+	Fixtures         time.Time
+	Recovery         []uof.ProducerChange
+	Cashout          *pipe.Cashout
+	Stages           []pipe.InnerStage
+	Replay           func(*api.ReplayAPI) error
+	Env              uof.Environment
+	Staging          bool
+	BindVirtuals     bool
+	BindSports       bool
+	Languages        []uof.Lang
+	BookLiveEvery    time.Duration
+	OrderTimeout     time.Duration
+	OrderLiveTimeout time.Duration
+	BindingKeys      []string
+	QueueFilters     []queue.Filter
+	LazyUnpack       bool
+	Conn             *queue.Connection
+	APIURL           string
+	Capture          *session.Recorder
+	Playback         *session.Session
 }
 
 // Option sets attributes on the Config.
@@ -42,16 +53,29 @@ func RunSimple(ctx context.Context, options ...Option) error {
 // Run starts uof connector.
 //
 // Call to Run blocks until stopped by context, or error occurred.
-// Order in which options are set is not important.
 // Credentials and one of Callback or Pipe are functional minimum.
+//
+// Options which add stages (Consumer, BufferedConsumer, ParallelConsumer,
+// KeyedConsumer, Router, OddsDelta, BetAcceptance, Suspension, Handover,
+// NextBetstop, SettlementLedger and Callback) add them in the order they are
+// set. Each of those stages gets messages after all stages set before it, so
+// for example consumer set before OddsDelta gets odds changes without delta.
+// Lexicon stages, Ordered, Recovery and Cashout are always placed before them.
+// Order of the other options is not important.
 func Run(ctx context.Context, options ...Option) (<-chan error, error) {
 	c := config(options...)
 	qc, apiConn, err := connect(ctx, c)
 	if err != nil {
 		return nil, err
 	}
-	if c.Replay != nil {
-		rpl, err := api.Replay(ctx, c.Token)
+	if c.Replay != nil && c.Playback == nil {
+		replay := api.Replay
+		if c.APIURL != "" {
+			replay = func(ctx context.Context, token string) (*api.ReplayAPI, error) {
+				return api.ReplayCustom(ctx, c.APIURL, token)
+			}
+		}
+		rpl, err := replay(ctx, c.Token)
 		if err != nil {
 			return nil, err
 		}
@@ -70,9 +94,15 @@ func Run(ctx context.Context, options ...Option) (<-chan error, error) {
 		//pipe.Competitor(apiConn, c.Languages),
 		pipe.BetStop(),
 	}
+	if c.OrderTimeout > 0 {
+		stages = append(stages, pipe.Ordered(c.Languages, c.OrderTimeout, c.OrderLiveTimeout))
+	}
 	if len(c.Recovery) > 0 {
 		stages = append(stages, pipe.Recovery(apiConn, c.Recovery))
 	}
+	if c.Cashout != nil {
+		stages = append(stages, c.Cashout.Stage(apiConn))
+	}
 	stages = append(stages, c.Stages...)
 
 	errc := pipe.Build(
@@ -132,14 +162,41 @@ func connect(ctx context.Context, c Config) (*queue.Connection, *api.API, error)
 	if c.BindSports {
 		bind = queue.BindSports
 	}
-	conn, err := queue.Dial(ctx, c.Env, c.BookmakerID, c.Token, bind)
+	var conn *queue.Connection
+	var err error
+	switch {
+	case c.Playback != nil:
+		conn, err = c.Playback.Connection()
+	case c.Conn != nil:
+		conn = c.Conn
+	case len(c.BindingKeys) > 0:
+		conn, err = queue.DialBindingKeys(ctx, c.Env, c.BookmakerID, c.Token, c.BindingKeys)
+	default:
+		conn, err = queue.Dial(ctx, c.Env, c.BookmakerID, c.Token, bind)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
-	stg, err := api.Dial(ctx, c.Env, c.Token)
+	conn.Filter(c.QueueFilters...)
+	if c.LazyUnpack {
+		conn.Lazy()
+	}
+	var stg *api.API
+	switch {
+	case c.Playback != nil:
+		stg = c.Playback.API()
+	case c.APIURL != "":
+		stg, err = api.Custom(ctx, c.APIURL, c.Token)
+	default:
+		stg, err = api.Dial(ctx, c.Env, c.Token)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
+	if c.Capture != nil {
+		conn.Capture(c.Capture.Delivery)
+		stg.Capture(c.Capture.Exchange)
+	}
 	return conn, stg, nil
 }
 
@@ -184,6 +241,71 @@ func BindSports() Option {
 	}
 }
 
+// Bind binds only to messages of the sports with the priorities. Empty list
+// means all sports or all priorities. System messages are always received.
+func Bind(sportIDs []int, priorities []uof.MessagePriority) Option {
+	return func(c *Config) {
+		c.BindingKeys = queue.BindingKeys(sportIDs, priorities)
+	}
+}
+
+// QueueFilter filters queue messages by the routing key before the message body
+// is unpacked. Message is passed to the pipe only if it passes all the filters.
+// System messages are never filtered out.
+//
+// Use queue.SportsFilter, queue.EventTypesFilter or queue.MessageTypesFilter.
+func QueueFilter(filters ...queue.Filter) Option {
+	return func(c *Config) {
+		c.QueueFilters = append(c.QueueFilters, filters...)
+	}
+}
+
+// LazyUnpack postpones unpacking of the queue message body until the first
+// call to Message.Unpack. Message header is parsed from the routing key and
+// the root element attributes. Useful for pipes which only pass or store raw
+// messages.
+//
+// Stages unpack only the message types they use, but Markets, Player and
+// BetStop stages, which Run always installs, unpack every odds_change and
+// bet_stop, and consumers get unpacked messages. So with Run only messages
+// dropped by the stages before any consumer stay unparsed. The option pays off
+// with a custom pipe (pipe.Build) without those stages. Malformed message is
+// reported and dropped by the first stage which unpacks it, same as malformed
+// message without LazyUnpack in the queue.
+func LazyUnpack() Option {
+	return func(c *Config) {
+		c.LazyUnpack = true
+	}
+}
+
+// Connection uses already established queue connection and api on the
+// apiURL instead of connecting to the Betradar environment. Used for testing
+// with the uoftest fake environment.
+func Connection(conn *queue.Connection, apiURL string) Option {
+	return func(c *Config) {
+		c.Conn = conn
+		c.APIURL = apiURL
+	}
+}
+
+// Capture records all queue deliveries and api responses of the session. Use
+// Playback to replay captured session offline.
+func Capture(rec *session.Recorder) Option {
+	return func(c *Config) {
+		c.Capture = rec
+	}
+}
+
+// Playback replays captured session instead of connecting to the Betradar
+// environment. Queue deliveries are replayed in order, api requests get the
+// recorded responses. Errors channel is closed after the last delivery is
+// processed.
+func Playback(s *session.Session) Option {
+	return func(c *Config) {
+		c.Playback = s
+	}
+}
+
 // Replay forces use of replay environment.
 // Callback will be called to start replay after establishing connection.
 func Replay(cb func(*api.ReplayAPI) error) Option {
@@ -199,6 +321,8 @@ func Replay(cb func(*api.ReplayAPI) error) Option {
 // In chan will be closed on SDK tear down.
 // If the consumer returns an error it is handled as fatal. Immediately closes SDK connection.
 // Can be called multiple times.
+// Consumer gets the same messages as other consumers and the next stages,
+// concurrently with them, it must not modify them.
 func Consumer(consumer pipe.ConsumerStage) Option {
 	return func(c *Config) {
 		c.Stages = append(c.Stages, pipe.Consumer(consumer))
@@ -212,6 +336,94 @@ func BufferedConsumer(consumer pipe.ConsumerStage, buffer int) Option {
 	}
 }
 
+// ParallelConsumer starts workers instances of the consumer, each with `in`
+// chan of size buffer. Messages are sharded to the workers by EventID so the
+// order of messages for one event is preserved. Lexicon and system messages
+// are sent to all workers.
+func ParallelConsumer(consumer pipe.ConsumerStage, workers, buffer int) Option {
+	return KeyedConsumer(consumer, workers, buffer, pipe.EventKey, false)
+}
+
+// KeyedConsumer same as ParallelConsumer but event messages are sharded by
+// custom key. If systemLane is set system messages are not sent to all workers but to
+// the one additional worker dedicated to the system messages.
+func KeyedConsumer(consumer pipe.ConsumerStage, workers, buffer int, key pipe.ShardKey, systemLane bool) Option {
+	return func(c *Config) {
+		c.Stages = append(c.Stages, pipe.ParallelConsumer(consumer, workers, buffer, key, systemLane))
+	}
+}
+
+// Router dispatches messages to the consumers by router routes.
+//
+// Routes are declared when the router is created:
+//
+//	router := pipe.NewRouter(
+//	  pipe.Route{Filter: pipe.Types(uof.MessageTypeOddsChange), Consumer: odds},
+//	  pipe.Route{Filter: pipe.Sports(1, 2), Consumer: football, Buffer: 1024},
+//	)
+//
+// Router keeps the count of messages which matched no route.
+// Can be called multiple times.
+func Router(router *pipe.Router) Option {
+	return func(c *Config) {
+		c.Stages = append(c.Stages, router.Stage())
+	}
+}
+
+// OddsDelta attaches to each odds change difference to the previous state of
+// the event (OddsChange.Delta). If dropUnchanged is set odds changes without
+// effective change are dropped. Stage is added at the current position in
+// the list of stages, so consumers added before get all odds changes.
+func OddsDelta(dropUnchanged bool) Option {
+	return func(c *Config) {
+		c.Stages = append(c.Stages, pipe.OddsDelta(dropUnchanged))
+	}
+}
+
+// BetAcceptance adds stage which maintains state of the bet acceptance. Use
+// acceptance.Check to decide whether the selection is bettable. Producers
+// state comes from the Recovery option, without it all selections are
+// rejected.
+func BetAcceptance(acceptance *pipe.BetAcceptance) Option {
+	return func(c *Config) {
+		c.Stages = append(c.Stages, acceptance.Stage())
+	}
+}
+
+// Suspension adds stage which emits SDK generated bet stop for each event
+// with active markets when producer goes down, and lifts it with the bet stop
+// lift message (uof.MessageTypeBetStopLift) when the producer is active again.
+func Suspension() Option {
+	return func(c *Config) {
+		c.Stages = append(c.Stages, pipe.Suspension())
+	}
+}
+
+// Handover adds stage which suspends prematch markets handed over to the live
+// producer until live odds arrive, and deactivates them if live odds do not
+// arrive in timeout.
+func Handover(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.Stages = append(c.Stages, pipe.Handover(timeout))
+	}
+}
+
+// NextBetstop adds stage which suspends markets at the next betstop time from
+// the market metadata if the feed has not done that already.
+func NextBetstop() Option {
+	return func(c *Config) {
+		c.Stages = append(c.Stages, pipe.NextBetstop())
+	}
+}
+
+// SettlementLedger adds stage which applies settlement, cancel and rollback
+// messages to the ledger.
+func SettlementLedger(ledger *pipe.SettlementLedger) Option {
+	return func(c *Config) {
+		c.Stages = append(c.Stages, ledger.Stage())
+	}
+}
+
 // Callback sets handler for all messages.
 //
 // If returns error will break the pipe and force exit from sdk.Run.
@@ -235,6 +447,18 @@ func Recovery(pc []uof.ProducerChange) Option {
 	}
 }
 
+// Cashout adds stage which gets cashout probabilities from the api, on
+// demand by cashout.Request and on odds change with markets available for
+// cashout. Probabilities are delivered as MessageTypeCashoutProbabilities
+// messages, use payout.CashoutValue to calculate value of the open bet.
+//
+// Ref: https://docs.betradar.com/display/BD/UOF+-+Cashout+Probabilities+API
+func Cashout(cashout *pipe.Cashout) Option {
+	return func(c *Config) {
+		c.Cashout = cashout
+	}
+}
+
 // Fixtures gets live and pre-match fixtures at start-up.
 //
 // It gets fixture for all matches which starts before `to` time.
@@ -257,3 +481,15 @@ func BookLiveMatches(every time.Duration) Option {
 		c.BookLiveEvery = every
 	}
 }
+
+// Ordered holds event messages until fixture, market descriptions and players
+// they depend on are delivered to the consumers.
+//
+// No message will be held longer than timeout. Live messages are held no longer
+// than liveTimeout. Order of the messages for one event is preserved.
+func Ordered(timeout, liveTimeout time.Duration) Option {
+	return func(c *Config) {
+		c.OrderTimeout = timeout
+		c.OrderLiveTimeout = liveTimeout
+	}
+}
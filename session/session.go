@@ -0,0 +1,127 @@
+// Package session captures queue deliveries and api responses of the sdk
+// session, and plays them back offline. Captured session is json lines file,
+// one delivery or api exchange per line, in order of arrival.
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/minus5/go-uof-sdk/api"
+	"github.com/minus5/go-uof-sdk/queue"
+	"github.com/streadway/amqp"
+)
+
+// Record is one line of the captured session. Exactly one field is set.
+type Record struct {
+	Delivery *Delivery     `json:"delivery,omitempty"`
+	Exchange *api.Exchange `json:"exchange,omitempty"`
+}
+
+// Delivery is queue message.
+type Delivery struct {
+	RoutingKey string `json:"routingKey"`
+	Body       string `json:"body"`
+}
+
+// Recorder writes session records. It is safe for concurrent use.
+type Recorder struct {
+	enc *json.Encoder
+	err error
+	sync.Mutex
+}
+
+// NewRecorder creates recorder writing to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+// Delivery records queue delivery.
+func (r *Recorder) Delivery(routingKey string, body []byte) {
+	r.write(Record{Delivery: &Delivery{RoutingKey: routingKey, Body: string(body)}})
+}
+
+// Exchange records api request and response.
+func (r *Recorder) Exchange(x api.Exchange) {
+	r.write(Record{Exchange: &x})
+}
+
+func (r *Recorder) write(rec Record) {
+	r.Lock()
+	defer r.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.err = r.enc.Encode(rec)
+}
+
+// Err returns first write error.
+func (r *Recorder) Err() error {
+	r.Lock()
+	defer r.Unlock()
+	return r.err
+}
+
+// Session is captured sdk session.
+type Session struct {
+	Deliveries []Delivery
+	Exchanges  []api.Exchange
+}
+
+// Load reads captured session.
+func Load(rd io.Reader) (*Session, error) {
+	s := &Session{}
+	sc := bufio.NewScanner(rd)
+	sc.Buffer(nil, 64*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		var rec Record
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("session line %d: %w", line, err)
+		}
+		if rec.Delivery != nil {
+			s.Deliveries = append(s.Deliveries, *rec.Delivery)
+		}
+		if rec.Exchange != nil {
+			s.Exchanges = append(s.Exchanges, *rec.Exchange)
+		}
+	}
+	return s, sc.Err()
+}
+
+// LoadFile reads captured session from the file.
+func LoadFile(filename string) (*Session, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// API returns api which serves recorded responses.
+func (s *Session) API() *api.API {
+	return api.Playback(s.Exchanges)
+}
+
+// Connection returns queue connection which delivers recorded messages. After
+// the last one connection is lost and not redialed, so the pipe started on it
+// ends.
+func (s *Session) Connection() (*queue.Connection, error) {
+	var dialed bool
+	return queue.Source(func() (<-chan amqp.Delivery, error) {
+		if dialed {
+			return nil, queue.ErrSourceEnded
+		}
+		dialed = true
+		out := make(chan amqp.Delivery, len(s.Deliveries))
+		for _, d := range s.Deliveries {
+			out <- amqp.Delivery{RoutingKey: d.RoutingKey, Body: []byte(d.Body)}
+		}
+		close(out)
+		return out, nil
+	})
+}
@@ -0,0 +1,133 @@
+package session_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/minus5/go-uof-sdk/sdk"
+	"github.com/minus5/go-uof-sdk/session"
+	"github.com/minus5/go-uof-sdk/uoftest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOddsChange = `<odds_change product="1" event_id="sr:match:1" timestamp="1234">
+	<odds>
+		<market id="40"><outcome id="sr:player:1" odds="2.5"/></market>
+	</odds>
+</odds_change>`
+
+func readFile(t *testing.T, name string) []byte {
+	buf, err := ioutil.ReadFile("../testdata/" + name)
+	require.NoError(t, err)
+	return buf
+}
+
+// collector of the messages keys, without connection messages and timestamps
+type collector struct {
+	keys []string
+	sync.Mutex
+}
+
+func (c *collector) callback(m *uof.Message) error {
+	var key string
+	switch m.Type {
+	case uof.MessageTypeConnection:
+		return nil
+	case uof.MessageTypeProducersChange:
+		key = m.Type.String()
+		for _, p := range m.Producers {
+			key += fmt.Sprintf(" %s:%d", p.Producer.Code(), p.Status)
+		}
+	default:
+		key = fmt.Sprintf("%s %s %s %s", m.Type, m.Lang, m.EventURN, m.Raw)
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.keys = append(c.keys, key)
+	return nil
+}
+
+func (c *collector) sorted() []string {
+	c.Lock()
+	defer c.Unlock()
+	keys := append([]string(nil), c.keys...)
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *collector) count(typ uof.MessageType) int {
+	c.Lock()
+	defer c.Unlock()
+	var n int
+	for _, k := range c.keys {
+		if len(k) > len(typ.String()) && k[:len(typ.String())+1] == typ.String()+" " {
+			n++
+		}
+	}
+	return n
+}
+
+func options(c *collector) []sdk.Option {
+	return []sdk.Option{
+		sdk.Languages([]uof.Lang{uof.LangEN}),
+		sdk.Recovery([]uof.ProducerChange{{Producer: uof.ProducerLiveOdds}}),
+		sdk.Callback(c.callback),
+	}
+}
+
+func TestCapturePlayback(t *testing.T) {
+	env := uoftest.NewEnv()
+	env.Markets(uof.LangEN, readFile(t, "markets-0.xml"))
+	env.Fixture(uof.LangEN, "sr:match:1", readFile(t, "fixture-0.xml"))
+	env.Player(uof.LangEN, 1, readFile(t, "player_profile_m.xml"))
+
+	// capture
+	var buf bytes.Buffer
+	rec := session.NewRecorder(&buf)
+	captured := &collector{}
+	opts, err := env.Options()
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	errc, err := sdk.Run(ctx, append(append(opts, sdk.Capture(rec)), options(captured)...)...)
+	require.NoError(t, err)
+	env.Send("hi.-.live.odds_change.1.sr:match.1.-", []byte(testOddsChange))
+	env.Send("hi.pre.-.fixture_change.1.sr:match.1.-", []byte(`<fixture_change product="3" event_id="sr:match:1" timestamp="1234"/>`))
+	timeout := time.After(5 * time.Second)
+	for captured.count(uof.MessageTypeFixture) == 0 || captured.count(uof.MessageTypePlayer) == 0 ||
+		captured.count(uof.MessageTypeMarkets) == 0 || captured.count(uof.MessageTypeSnapshotComplete) == 0 {
+		select {
+		case <-timeout:
+			t.Fatalf("timeout, captured: %v", captured.sorted())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cancel()
+	env.Close()
+	for range errc {
+	}
+	require.NoError(t, rec.Err())
+
+	// playback
+	s, err := session.Load(&buf)
+	require.NoError(t, err)
+	assert.Len(t, s.Deliveries, 3)
+	assert.NotEmpty(t, s.Exchanges)
+	played := &collector{}
+	errc, err = sdk.Run(context.Background(), append(options(played), sdk.Playback(s))...)
+	require.NoError(t, err)
+	for err := range errc {
+		// recovery request id is random, differs from the one in snapshot complete
+		assert.Contains(t, err.Error(), "unexpected requestID")
+	}
+	// playback ends with connection lost, producer goes down
+	captured.keys = append(captured.keys, "producer_change liveodds:-1")
+	assert.Equal(t, captured.sorted(), played.sorted())
+}
@@ -0,0 +1,235 @@
+package uof
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Common specifier names.
+const (
+	SpecifierHandicap = "hcp"
+	SpecifierTotal    = "total"
+	SpecifierVariant  = "variant"
+	SpecifierPlayer   = "player"
+	SpecifierQuarter  = "quarternr"
+	SpecifierSet      = "setnr"
+	SpecifierGame     = "gamenr"
+	SpecifierGoal     = "goalnr"
+	SpecifierPeriod   = "periodnr"
+	SpecifierInning   = "inningnr"
+	SpecifierMap      = "mapnr"
+	SpecifierFrom     = "from"
+	SpecifierTo       = "to"
+)
+
+// Specifiers are market specifiers typed by the market description. Values
+// are checked against the type when created, so accessors fail only when the
+// specifier is missing or of the other type.
+type Specifiers struct {
+	values map[string]string
+	types  map[string]SpecifierType
+}
+
+// NewSpecifiers types market specifiers using market description. Specifier
+// which is not in the description has SpecifierTypeUnknown and is accessible
+// only as string.
+func NewSpecifiers(m Market, md *MarketDescription) (Specifiers, error) {
+	s := Specifiers{
+		values: m.Specifiers,
+		types:  make(map[string]SpecifierType, len(m.Specifiers)),
+	}
+	for k, v := range m.Specifiers {
+		typ := SpecifierTypeUnknown
+		if md != nil {
+			for _, d := range md.Specifiers {
+				if d.Name == k {
+					typ = d.Type
+					break
+				}
+			}
+		}
+		if err := checkSpecifier(k, v, typ); err != nil {
+			return Specifiers{}, err
+		}
+		s.types[k] = typ
+	}
+	return s, nil
+}
+
+func checkSpecifier(name, value string, typ SpecifierType) error {
+	var err error
+	switch {
+	case name == SpecifierHandicap:
+		_, err = ParseHandicap(value)
+	case typ == SpecifierTypeInteger:
+		_, err = strconv.Atoi(value)
+	case typ == SpecifierTypeDecimal:
+		_, err = strconv.ParseFloat(value, 64)
+	}
+	if err != nil {
+		return fmt.Errorf("specifier %s=%s: %w", name, value, err)
+	}
+	return nil
+}
+
+// Type returns specifier type, SpecifierTypeUnknown for missing one.
+func (s Specifiers) Type(name string) SpecifierType {
+	if typ, ok := s.types[name]; ok {
+		return typ
+	}
+	return SpecifierTypeUnknown
+}
+
+// Value returns specifier value as written in the feed.
+func (s Specifiers) Value(name string) (string, bool) {
+	v, ok := s.values[name]
+	return v, ok
+}
+
+// Int returns value of the integer specifier.
+func (s Specifiers) Int(name string) (int, bool) {
+	if s.Type(name) != SpecifierTypeInteger {
+		return 0, false
+	}
+	i, _ := strconv.Atoi(s.values[name])
+	return i, true
+}
+
+// Decimal returns value of the decimal or integer specifier.
+func (s Specifiers) Decimal(name string) (float64, bool) {
+	if typ := s.Type(name); typ != SpecifierTypeDecimal && typ != SpecifierTypeInteger {
+		return 0, false
+	}
+	f, _ := strconv.ParseFloat(s.values[name], 64)
+	return f, true
+}
+
+// Handicap returns value of the hcp specifier, decimal or composite.
+func (s Specifiers) Handicap() (Handicap, bool) {
+	v, ok := s.values[SpecifierHandicap]
+	if !ok {
+		return Handicap{}, false
+	}
+	h, _ := ParseHandicap(v)
+	return h, true
+}
+
+// Total returns value of the total specifier.
+func (s Specifiers) Total() (float64, bool) {
+	return s.Decimal(SpecifierTotal)
+}
+
+// Variant returns value of the variant specifier, empty for the markets
+// without variant.
+func (s Specifiers) Variant() string {
+	return s.values[SpecifierVariant]
+}
+
+// Handicap is the value of hcp specifier. Decimal handicap ("-1.5") is the
+// handicap of the home team. Composite one ("0:1") is the head start of each
+// team, it is used in the markets with three way outcome.
+type Handicap struct {
+	Home float64
+	Away float64
+}
+
+// ParseHandicap parses decimal or composite handicap.
+func ParseHandicap(s string) (Handicap, error) {
+	p := strings.Split(s, ":")
+	switch len(p) {
+	case 1:
+		home, err := strconv.ParseFloat(p[0], 64)
+		if err != nil {
+			return Handicap{}, fmt.Errorf("malformed handicap %q", s)
+		}
+		return Handicap{Home: home}, nil
+	case 2:
+		home, err1 := strconv.ParseFloat(p[0], 64)
+		away, err2 := strconv.ParseFloat(p[1], 64)
+		if err1 != nil || err2 != nil || home < 0 || away < 0 {
+			return Handicap{}, fmt.Errorf("malformed handicap %q", s)
+		}
+		return Handicap{Home: home, Away: away}, nil
+	}
+	return Handicap{}, fmt.Errorf("malformed handicap %q", s)
+}
+
+// Value is handicap of the home team, "0:1" is -1.
+func (h Handicap) Value() float64 {
+	return h.Home - h.Away
+}
+
+// MarketLines are all lines of one market in the odds change. Lines differ by
+// specifiers, market id and variant are the same.
+type MarketLines struct {
+	ID        int
+	VariantID int
+	Lines     []Market
+}
+
+// GroupLines groups markets by the id and variant. Groups are in the order of
+// the first line appearance.
+func GroupLines(markets []Market) []MarketLines {
+	var groups []MarketLines
+	idx := make(map[[2]int]int)
+	for _, m := range markets {
+		k := [2]int{m.ID, m.VariantID}
+		i, ok := idx[k]
+		if !ok {
+			i = len(groups)
+			idx[k] = i
+			groups = append(groups, MarketLines{ID: m.ID, VariantID: m.VariantID})
+		}
+		groups[i].Lines = append(groups[i].Lines, m)
+	}
+	return groups
+}
+
+// MainLine returns the most balanced line. That is the one marked as
+// favourite, or if none is marked, active line with the smallest ratio of the
+// highest and lowest odds. Nil if there are no lines.
+func (ml MarketLines) MainLine() *Market {
+	if len(ml.Lines) == 0 {
+		return nil
+	}
+	for i, m := range ml.Lines {
+		if m.Favourite != nil && *m.Favourite {
+			return &ml.Lines[i]
+		}
+	}
+	main := 0
+	var best float64
+	for i, m := range ml.Lines {
+		if m.Status != MarketStatusActive {
+			continue
+		}
+		if r, ok := oddsRatio(m); ok && (best == 0 || r < best) {
+			main, best = i, r
+		}
+	}
+	return &ml.Lines[main]
+}
+
+// oddsRatio is highest to lowest odds of the active outcomes, at least two
+// outcomes have to be priced.
+func oddsRatio(m Market) (float64, bool) {
+	var lo, hi float64
+	var n int
+	for _, o := range m.Outcomes {
+		if o.Odds == nil || *o.Odds <= 0 || (o.Active != nil && !*o.Active) {
+			continue
+		}
+		if n == 0 || *o.Odds < lo {
+			lo = *o.Odds
+		}
+		if n == 0 || *o.Odds > hi {
+			hi = *o.Odds
+		}
+		n++
+	}
+	if n < 2 {
+		return 0, false
+	}
+	return hi / lo, true
+}
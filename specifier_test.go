@@ -0,0 +1,146 @@
+package uof
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMarket(t *testing.T, buf string) Market {
+	var m Market
+	require.NoError(t, xml.Unmarshal([]byte(buf), &m))
+	return m
+}
+
+func TestSpecifiers(t *testing.T) {
+	md := &MarketDescription{ID: 1, Specifiers: []MarketSpecifier{
+		{Name: "total", Type: SpecifierTypeDecimal},
+		{Name: "quarternr", Type: SpecifierTypeInteger},
+		{Name: "hcp", Type: SpecifierTypeString},
+		{Name: "variant", Type: SpecifierTypeVariableText},
+	}}
+	m := testMarket(t, `<market id="1" specifiers="total=2.5|quarternr=3|hcp=0:1|variant=sr:exact_goals:4+" extended_specifiers="x=y"/>`)
+	s, err := NewSpecifiers(m, md)
+	assert.NoError(t, err)
+
+	total, ok := s.Total()
+	assert.True(t, ok)
+	assert.Equal(t, 2.5, total)
+	q, ok := s.Int(SpecifierQuarter)
+	assert.True(t, ok)
+	assert.Equal(t, 3, q)
+	d, ok := s.Decimal(SpecifierQuarter)
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, d)
+	h, ok := s.Handicap()
+	assert.True(t, ok)
+	assert.Equal(t, Handicap{Home: 0, Away: 1}, h)
+	assert.Equal(t, -1.0, h.Value())
+	assert.Equal(t, "sr:exact_goals:4+", s.Variant())
+
+	// other type, not described, missing
+	_, ok = s.Int(SpecifierTotal)
+	assert.False(t, ok)
+	assert.Equal(t, SpecifierTypeUnknown, s.Type("x"))
+	_, ok = s.Decimal("x")
+	assert.False(t, ok)
+	v, ok := s.Value("x")
+	assert.True(t, ok)
+	assert.Equal(t, "y", v)
+	_, ok = s.Int(SpecifierSet)
+	assert.False(t, ok)
+	_, ok = s.Value(SpecifierSet)
+	assert.False(t, ok)
+
+	// value not matching type
+	_, err = NewSpecifiers(testMarket(t, `<market id="1" specifiers="total=x"/>`), md)
+	assert.Error(t, err)
+	_, err = NewSpecifiers(testMarket(t, `<market id="1" specifiers="quarternr=1.5"/>`), md)
+	assert.Error(t, err)
+	_, err = NewSpecifiers(testMarket(t, `<market id="1" specifiers="hcp=1:x"/>`), nil)
+	assert.Error(t, err)
+
+	// without description
+	s, err = NewSpecifiers(testMarket(t, `<market id="1" specifiers="hcp=-1.5|total=2.5"/>`), nil)
+	assert.NoError(t, err)
+	h, ok = s.Handicap()
+	assert.True(t, ok)
+	assert.Equal(t, -1.5, h.Value())
+	_, ok = s.Total()
+	assert.False(t, ok)
+}
+
+func TestSpecifiersWithMarketsDescription(t *testing.T) {
+	buf, err := ioutil.ReadFile("./testdata/markets-1.xml")
+	require.NoError(t, err)
+	ms := &MarketsRsp{}
+	require.NoError(t, xml.Unmarshal(buf, ms))
+
+	s, err := NewSpecifiers(testMarket(t, `<market id="16" specifiers="hcp=-0.25"/>`), ms.Markets.Find(16))
+	assert.NoError(t, err)
+	assert.Equal(t, SpecifierTypeDecimal, s.Type(SpecifierHandicap))
+	h, ok := s.Handicap()
+	assert.True(t, ok)
+	assert.Equal(t, -0.25, h.Value())
+}
+
+func TestParseHandicap(t *testing.T) {
+	data := []struct {
+		s string
+		h Handicap
+	}{
+		{"0", Handicap{}},
+		{"-1.5", Handicap{Home: -1.5}},
+		{"+0.75", Handicap{Home: 0.75}},
+		{"0:1", Handicap{Away: 1}},
+		{"2:0", Handicap{Home: 2}},
+	}
+	for _, d := range data {
+		h, err := ParseHandicap(d.s)
+		assert.NoError(t, err, d.s)
+		assert.Equal(t, d.h, h, d.s)
+	}
+	for _, s := range []string{"", "x", "1:", ":1", "-1:0", "1:2:3"} {
+		_, err := ParseHandicap(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestMainLine(t *testing.T) {
+	var oc OddsChange
+	require.NoError(t, xml.Unmarshal([]byte(`<odds_change product="1" event_id="sr:match:1" timestamp="1"><odds>
+		<market id="18" specifiers="total=1.5"><outcome id="12" odds="3.2"/><outcome id="13" odds="1.3"/></market>
+		<market id="1"><outcome id="1" odds="2.1"/><outcome id="2" odds="3.1"/><outcome id="3" odds="3.3"/></market>
+		<market id="18" specifiers="total=2.5"><outcome id="12" odds="1.95"/><outcome id="13" odds="1.85"/></market>
+		<market id="18" specifiers="total=3.5"><outcome id="12" odds="1.2"/><outcome id="13" odds="4.1"/></market>
+		<market id="16" specifiers="hcp=-0.5"><outcome id="1714" odds="1.9"/><outcome id="1715" odds="1.9" active="0"/></market>
+		<market id="16" specifiers="hcp=-1.5" status="-1"><outcome id="1714" odds="2"/><outcome id="1715" odds="2"/></market>
+		<market id="16" specifiers="hcp=0.5"><outcome id="1714" odds="1.4"/><outcome id="1715" odds="2.8"/></market>
+	</odds></odds_change>`), &oc))
+
+	groups := GroupLines(oc.Markets)
+	assert.Len(t, groups, 3)
+	assert.Equal(t, 18, groups[0].ID)
+	assert.Len(t, groups[0].Lines, 3)
+	assert.Equal(t, 1, groups[1].ID)
+	assert.Len(t, groups[1].Lines, 1)
+
+	// balanced odds
+	assert.Equal(t, "2.5", groups[0].MainLine().Specifiers["total"])
+	assert.Equal(t, 1, groups[1].MainLine().ID)
+	// inactive market and outcome are skipped
+	assert.Equal(t, "0.5", groups[2].MainLine().Specifiers["hcp"])
+
+	// favourite has precedence
+	fav := true
+	groups[0].Lines[2].Favourite = &fav
+	assert.Equal(t, "3.5", groups[0].MainLine().Specifiers["total"])
+
+	// no priced lines
+	assert.Nil(t, MarketLines{}.MainLine())
+	ml := GroupLines([]Market{{ID: 1, LineID: 1}, {ID: 1, LineID: 2}})[0]
+	assert.Equal(t, 1, ml.MainLine().LineID)
+}
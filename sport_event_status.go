@@ -11,86 +11,86 @@ import "encoding/xml"
 // Reference: https://docs.betradar.com/display/BD/UOF+-+Sport+event+status
 type SportEventStatus struct {
 	// High-level generic status of the match.
-	Status EventStatus `xml:"status,attr" json:"status" bson:"status"`
+	Status EventStatus `xml:"status,attr" json:"status" bson:"status" uof:"1"`
 	// Does Betradar have a scout watching the game.
-	Reporting *EventReporting `xml:"reporting,attr,omitempty" json:"reporting,omitempty" bson:"reporting,omitempty"`
+	Reporting *EventReporting `xml:"reporting,attr,omitempty" json:"reporting,omitempty" bson:"reporting,omitempty" uof:"2"`
 	// Current score for the home team.
-	HomeScore *int `xml:"-" json:"homeScore,omitempty" bson:"homeScore,omitempty"`
+	HomeScore *int `xml:"-" json:"homeScore,omitempty" bson:"homeScore,omitempty" uof:"3"`
 	// Current score for the away team.
-	AwayScore *int `xml:"-" json:"awayScore,omitempty" bson:"awayScore,omitempty"`
+	AwayScore *int `xml:"-" json:"awayScore,omitempty" bson:"awayScore,omitempty" uof:"4"`
 	// Sports-specific integer code the represents the live match status (first period, 2nd break, etc.).
-	MatchStatus *int `xml:"match_status,attr" json:"matchStatus" bson:"matchStatus,omitempty"`
+	MatchStatus *int `xml:"match_status,attr" json:"matchStatus" bson:"matchStatus,omitempty" uof:"5"`
 
 	// Tennis
 	// The player who has the serve at that moment.
-	CurrentServer *Team `xml:"current_server,attr,omitempty" json:"currentServer,omitempty" bson:"currentServer,omitempty"`
+	CurrentServer *Team `xml:"current_server,attr,omitempty" json:"currentServer,omitempty" bson:"currentServer,omitempty" uof:"6"`
 	// The point score of the "home" player. The score will be 50 if the "home"
 	// player has advantage. This attribute is also used for the tiebreak score
 	// when the game is in a tiebreak.
 	// (15 30 40 50)
-	HomeGamescore *int `xml:"home_gamescore,attr,omitempty" json:"homeGamescore,omitempty" bson:"homeGamescore,omitempty"`
+	HomeGamescore *int `xml:"home_gamescore,attr,omitempty" json:"homeGamescore,omitempty" bson:"homeGamescore,omitempty" uof:"7"`
 	// The point score of the "away" player. The score will be 50 if the "away"
 	// player has advantage. This attribute is also used for the tiebreak score
 	// when the game is in a tiebreak.
-	AwayGamescore *int  `xml:"away_gamescore,attr,omitempty" json:"awayGamescore,omitempty" bson:"awayGamescore,omitempty"`
-	Tiebreak      *bool `xml:"tiebreak,attr,omitempty" json:"tiebreak,omitempty" bson:"tiebreak,omitempty"`
+	AwayGamescore *int  `xml:"away_gamescore,attr,omitempty" json:"awayGamescore,omitempty" bson:"awayGamescore,omitempty" uof:"8"`
+	Tiebreak      *bool `xml:"tiebreak,attr,omitempty" json:"tiebreak,omitempty" bson:"tiebreak,omitempty" uof:"9"`
 	// Table tennis
-	ExpediteMode *bool `xml:"expedite_mode,attr,omitempty" json:"expediteMode,omitempty" bson:"expediteMode,omitempty"`
+	ExpediteMode *bool `xml:"expedite_mode,attr,omitempty" json:"expediteMode,omitempty" bson:"expediteMode,omitempty" uof:"10"`
 
 	//Ice hockey
-	HomePenaltyScore *int `xml:"home_penalty_score,attr,omitempty" json:"homePenaltyScore,omitempty" bson:"homePenaltyScore,omitempty"`
-	AwayPenaltyScore *int `xml:"away_penalty_score,attr,omitempty" json:"awayPenaltyScore,omitempty" bson:"awayPenaltyScore,omitempty"`
+	HomePenaltyScore *int `xml:"home_penalty_score,attr,omitempty" json:"homePenaltyScore,omitempty" bson:"homePenaltyScore,omitempty" uof:"11"`
+	AwayPenaltyScore *int `xml:"away_penalty_score,attr,omitempty" json:"awayPenaltyScore,omitempty" bson:"awayPenaltyScore,omitempty" uof:"12"`
 
 	// Ice Hockey, Handball, Futsal
-	HomeSuspend *int `xml:"home_suspend,attr,omitempty" json:"homeSuspend,omitempty" bson:"homeSuspend,omitempty"`
-	AwaySuspend *int `xml:"away_suspend,attr,omitempty" json:"awaySuspend,omitempty" bson:"awaySuspend,omitempty"`
+	HomeSuspend *int `xml:"home_suspend,attr,omitempty" json:"homeSuspend,omitempty" bson:"homeSuspend,omitempty" uof:"13"`
+	AwaySuspend *int `xml:"away_suspend,attr,omitempty" json:"awaySuspend,omitempty" bson:"awaySuspend,omitempty" uof:"14"`
 
 	// Baseball
-	Balls      *int    `xml:"balls,attr,omitempty" json:"balls,omitempty" bson:"balls,omitempty"`
-	Strikes    *int    `xml:"strikes,attr,omitempty" json:"strikes,omitempty" bson:"strikes,omitempty"`
-	Outs       *int    `xml:"outs,attr,omitempty" json:"outs,omitempty" bson:"outs,omitempty"`
-	Bases      *string `xml:"bases,attr,omitempty" json:"bases,omitempty" bson:"bases,omitempty"`
-	HomeBatter *int    `xml:"home_batter,attr,omitempty" json:"homeBatter,omitempty" bson:"homeBatter,omitempty"`
-	AwayBatter *int    `xml:"away_batter,attr,omitempty" json:"awayBatter,omitempty" bson:"awayBatter,omitempty"`
+	Balls      *int    `xml:"balls,attr,omitempty" json:"balls,omitempty" bson:"balls,omitempty" uof:"15"`
+	Strikes    *int    `xml:"strikes,attr,omitempty" json:"strikes,omitempty" bson:"strikes,omitempty" uof:"16"`
+	Outs       *int    `xml:"outs,attr,omitempty" json:"outs,omitempty" bson:"outs,omitempty" uof:"17"`
+	Bases      *string `xml:"bases,attr,omitempty" json:"bases,omitempty" bson:"bases,omitempty" uof:"18"`
+	HomeBatter *int    `xml:"home_batter,attr,omitempty" json:"homeBatter,omitempty" bson:"homeBatter,omitempty" uof:"19"`
+	AwayBatter *int    `xml:"away_batter,attr,omitempty" json:"awayBatter,omitempty" bson:"awayBatter,omitempty" uof:"20"`
 
 	// American Football
-	Possession *int `xml:"possession,attr,omitempty" json:"possession,omitempty" bson:"possession,omitempty"`
-	Position   *int `xml:"position,attr,omitempty" json:"position,omitempty" bson:"position,omitempty"`
-	Try        *int `xml:"try,attr,omitempty" json:"try,omitempty" bson:"try,omitempty"`
-	Yards      *int `xml:"yards,attr,omitempty" json:"yards,omitempty" bson:"yards,omitempty"`
+	Possession *int `xml:"possession,attr,omitempty" json:"possession,omitempty" bson:"possession,omitempty" uof:"21"`
+	Position   *int `xml:"position,attr,omitempty" json:"position,omitempty" bson:"position,omitempty" uof:"22"`
+	Try        *int `xml:"try,attr,omitempty" json:"try,omitempty" bson:"try,omitempty" uof:"23"`
+	Yards      *int `xml:"yards,attr,omitempty" json:"yards,omitempty" bson:"yards,omitempty" uof:"24"`
 
 	// Snooker
-	RemainingReds *int `xml:"remaining_reds,attr,omitempty" json:"remainingReds,omitempty" bson:"remainingReds,omitempty"`
+	RemainingReds *int `xml:"remaining_reds,attr,omitempty" json:"remainingReds,omitempty" bson:"remainingReds,omitempty" uof:"25"`
 
 	// Darts and Snooker
-	Visit *int `xml:"visit,attr,omitempty" json:"visit,omitempty" bson:"visit,omitempty"`
+	Visit *int `xml:"visit,attr,omitempty" json:"visit,omitempty" bson:"visit,omitempty" uof:"26"`
 
 	// Darts
-	HomeLegscore *int `xml:"home_legscore,attr,omitempty" json:"homeLegscore,omitempty" bson:"homeLegscore,omitempty"`
-	AwayLegscore *int `xml:"away_legscore,attr,omitempty" json:"awayLegscore,omitempty" bson:"awayLegscore,omitempty"`
-	Throw        *int `xml:"throw,attr,omitempty" json:"throw,omitempty" bson:"throw,omitempty"`
+	HomeLegscore *int `xml:"home_legscore,attr,omitempty" json:"homeLegscore,omitempty" bson:"homeLegscore,omitempty" uof:"27"`
+	AwayLegscore *int `xml:"away_legscore,attr,omitempty" json:"awayLegscore,omitempty" bson:"awayLegscore,omitempty" uof:"28"`
+	Throw        *int `xml:"throw,attr,omitempty" json:"throw,omitempty" bson:"throw,omitempty" uof:"29"`
 
 	// Bowls
-	Delivery           *int `xml:"delivery,attr,omitempty" json:"delivery,omitempty" bson:"delivery,omitempty"`
-	HomeRemainingBowls *int `xml:"home_remaining_bowls,attr,omitempty" json:"homeRemainingBowls,omitempty" bson:"homeRemainingBowls,omitempty"`
-	AwayRemainingBowls *int `xml:"away_remaining_bowls,attr,omitempty" json:"awayRemainingBowls,omitempty" bson:"awayRemainingBowls,omitempty"`
-	CurrentEnd         *int `xml:"current_end,attr,omitempty" json:"currentEnd,omitempty" bson:"currentEnd,omitempty"`
+	Delivery           *int `xml:"delivery,attr,omitempty" json:"delivery,omitempty" bson:"delivery,omitempty" uof:"30"`
+	HomeRemainingBowls *int `xml:"home_remaining_bowls,attr,omitempty" json:"homeRemainingBowls,omitempty" bson:"homeRemainingBowls,omitempty" uof:"31"`
+	AwayRemainingBowls *int `xml:"away_remaining_bowls,attr,omitempty" json:"awayRemainingBowls,omitempty" bson:"awayRemainingBowls,omitempty" uof:"32"`
+	CurrentEnd         *int `xml:"current_end,attr,omitempty" json:"currentEnd,omitempty" bson:"currentEnd,omitempty" uof:"33"`
 
 	// Cricket
-	Innings         *int `xml:"innings,attr,omitempty" json:"innings,omitempty" bson:"innings,omitempty"`
-	Over            *int `xml:"over,attr,omitempty" json:"over,omitempty" bson:"over,omitempty"`
-	HomePenaltyRuns *int `xml:"home_penalty_runs,attr,omitempty" json:"homePenaltyRuns,omitempty" bson:"homePenaltyRuns,omitempty"`
-	AwayPenaltyRuns *int `xml:"away_penalty_runs,attr,omitempty" json:"awayPenaltyRuns,omitempty" bson:"awayPenaltyRuns,omitempty"`
-	HomeDismissals  *int `xml:"home_dismissals,attr,omitempty" json:"homeDismissals,omitempty" bson:"homeDismissals,omitempty"`
-	AwayDismissals  *int `xml:"away_dismissals,attr,omitempty" json:"awayDismissals,omitempty" bson:"awayDismissals,omitempty"`
+	Innings         *int `xml:"innings,attr,omitempty" json:"innings,omitempty" bson:"innings,omitempty" uof:"34"`
+	Over            *int `xml:"over,attr,omitempty" json:"over,omitempty" bson:"over,omitempty" uof:"35"`
+	HomePenaltyRuns *int `xml:"home_penalty_runs,attr,omitempty" json:"homePenaltyRuns,omitempty" bson:"homePenaltyRuns,omitempty" uof:"36"`
+	AwayPenaltyRuns *int `xml:"away_penalty_runs,attr,omitempty" json:"awayPenaltyRuns,omitempty" bson:"awayPenaltyRuns,omitempty" uof:"37"`
+	HomeDismissals  *int `xml:"home_dismissals,attr,omitempty" json:"homeDismissals,omitempty" bson:"homeDismissals,omitempty" uof:"38"`
+	AwayDismissals  *int `xml:"away_dismissals,attr,omitempty" json:"awayDismissals,omitempty" bson:"awayDismissals,omitempty" uof:"39"`
 
 	// CS:GO
-	CurrentCtTeam *Team `xml:"current_ct_team,attr,omitempty" json:"currentCtTeam,omitempty" bson:"currentCtTeam,omitempty"`
+	CurrentCtTeam *Team `xml:"current_ct_team,attr,omitempty" json:"currentCtTeam,omitempty" bson:"currentCtTeam,omitempty" uof:"40"`
 
-	Clock        *Clock        `xml:"clock,omitempty" json:"clock,omitempty" bson:"clock,omitempty"`
-	PeriodScores []PeriodScore `xml:"period_scores>period_score,omitempty" json:"periodScores,omitempty" bson:"periodScores,omitempty"`
-	Results      []Result      `xml:"results>result,omitempty" json:"results,omitempty" bson:"results,omitempty"`
-	Statistics   *Statistics   `xml:"statistics,omitempty" json:"statistics,omitempty" bson:"statistics,omitempty"`
+	Clock        *Clock        `xml:"clock,omitempty" json:"clock,omitempty" bson:"clock,omitempty" uof:"41"`
+	PeriodScores []PeriodScore `xml:"period_scores>period_score,omitempty" json:"periodScores,omitempty" bson:"periodScores,omitempty" uof:"42"`
+	Results      []Result      `xml:"results>result,omitempty" json:"results,omitempty" bson:"results,omitempty" uof:"43"`
+	Statistics   *Statistics   `xml:"statistics,omitempty" json:"statistics,omitempty" bson:"statistics,omitempty" uof:"44"`
 }
 
 func (o *SportEventStatus) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
@@ -120,53 +120,53 @@ func (o *SportEventStatus) UnmarshalXML(d *xml.Decoder, start xml.StartElement)
 type Clock struct {
 	// The playing minute of the match (or minute:second if available)
 	// mm:ss (42:10)
-	MatchTime *ClockTime `xml:"match_time,attr,omitempty" json:"matchTime,omitempty" bson:"matchTime,omitempty"`
+	MatchTime *ClockTime `xml:"match_time,attr,omitempty" json:"matchTime,omitempty" bson:"matchTime,omitempty" uof:"1"`
 	// How far into stoppage time is the match in minutes
 	// mm:ss
-	StoppageTime *ClockTime `xml:"stoppage_time,attr,omitempty" json:"stoppageTime,omitempty" bson:"stoppageTime,omitempty"`
+	StoppageTime *ClockTime `xml:"stoppage_time,attr,omitempty" json:"stoppageTime,omitempty" bson:"stoppageTime,omitempty" uof:"2"`
 	// Set to what the announce stoppage time is announced
 	// mm:ss
-	StoppageTimeAnnounced *ClockTime `xml:"stoppage_time_announced,attr,omitempty" json:"stoppageTimeAnnounced,omitempty" bson:"stoppageTimeAnnounced,omitempty"`
+	StoppageTimeAnnounced *ClockTime `xml:"stoppage_time_announced,attr,omitempty" json:"stoppageTimeAnnounced,omitempty" bson:"stoppageTimeAnnounced,omitempty" uof:"3"`
 	// How many minutes remains of the match
 	// mm:ss
-	RemainingTime *ClockTime `xml:"remaining_time,attr,omitempty" json:"remainingTime,omitempty" bson:"remainingTime,omitempty"`
+	RemainingTime *ClockTime `xml:"remaining_time,attr,omitempty" json:"remainingTime,omitempty" bson:"remainingTime,omitempty" uof:"4"`
 	// How much time remains in the current period
 	// mm:ss
-	RemainingTimeInPeriod *ClockTime `xml:"remaining_time_in_period,attr,omitempty" json:"remainingTimeInPeriod,omitempty" bson:"remainingTimeInPeriod,omitempty"`
+	RemainingTimeInPeriod *ClockTime `xml:"remaining_time_in_period,attr,omitempty" json:"remainingTimeInPeriod,omitempty" bson:"remainingTimeInPeriod,omitempty" uof:"5"`
 	// true if the match clock is stopped otherwise false
-	Stopped *bool `xml:"stopped,attr,omitempty" json:"stopped,omitempty" bson:"stopped,omitempty"`
+	Stopped *bool `xml:"stopped,attr,omitempty" json:"stopped,omitempty" bson:"stopped,omitempty" uof:"6"`
 }
 
 type PeriodScore struct {
 	// The match status of an event gives an indication of which context the
 	// current match is in. Complete list available at:
 	// /v1/descriptions/en/match_status.xml
-	MatchStatusCode *int `xml:"match_status_code,attr" json:"matchStatusCode" bson:"matchStatusCode,omitempty"`
+	MatchStatusCode *int `xml:"match_status_code,attr" json:"matchStatusCode" bson:"matchStatusCode,omitempty" uof:"1"`
 	// Indicates what regular period this is
-	Number *int `xml:"number,attr" json:"number" bson:"number,omitempty"`
+	Number *int `xml:"number,attr" json:"number" bson:"number,omitempty" uof:"2"`
 	// The number of points/goals/games the competitor designated as "home" has
 	// scored for this period.
-	HomeScore *int `xml:"home_score,attr" json:"homeScore" bson:"homeScore,omitempty"`
+	HomeScore *int `xml:"home_score,attr" json:"homeScore" bson:"homeScore,omitempty" uof:"3"`
 	// The number of points/goals/games the competitor designated as "away" has
 	// scored for this period.
-	AwayScore *int `xml:"away_score,attr" json:"awayScore" bson:"awayScore,omitempty"`
+	AwayScore *int `xml:"away_score,attr" json:"awayScore" bson:"awayScore,omitempty" uof:"4"`
 }
 
 type Result struct {
-	MatchStatusCode *int `xml:"match_status_code,attr" json:"matchStatusCode" bson:"matchStatusCode,omitempty"`
-	HomeScore       *int `xml:"home_score,attr" json:"homeScore" bson:"homeScore,omitempty"`
-	AwayScore       *int `xml:"away_score,attr" json:"awayScore" bson:"awayScore,omitempty"`
+	MatchStatusCode *int `xml:"match_status_code,attr" json:"matchStatusCode" bson:"matchStatusCode,omitempty" uof:"1"`
+	HomeScore       *int `xml:"home_score,attr" json:"homeScore" bson:"homeScore,omitempty" uof:"2"`
+	AwayScore       *int `xml:"away_score,attr" json:"awayScore" bson:"awayScore,omitempty" uof:"3"`
 }
 
 type Statistics struct {
-	YellowCards    *StatisticsScore `xml:"yellow_cards,omitempty" json:"yellowCards,omitempty" bson:"yellowCards,omitempty"`
-	RedCards       *StatisticsScore `xml:"red_cards,omitempty" json:"redCards,omitempty" bson:"redCards,omitempty"`
-	GreenCards     *StatisticsScore `xml:"green_cards,omitempty" json:"greenCards,omitempty" bson:"greenCards,omitempty"`
-	YellowRedCards *StatisticsScore `xml:"yellow_red_cards,omitempty" json:"yellowRedCards,omitempty" bson:"yellowRedCards,omitempty"`
-	Corners        *StatisticsScore `xml:"corners,omitempty" json:"corners,omitempty" bson:"corners,omitempty"`
+	YellowCards    *StatisticsScore `xml:"yellow_cards,omitempty" json:"yellowCards,omitempty" bson:"yellowCards,omitempty" uof:"1"`
+	RedCards       *StatisticsScore `xml:"red_cards,omitempty" json:"redCards,omitempty" bson:"redCards,omitempty" uof:"2"`
+	GreenCards     *StatisticsScore `xml:"green_cards,omitempty" json:"greenCards,omitempty" bson:"greenCards,omitempty" uof:"3"`
+	YellowRedCards *StatisticsScore `xml:"yellow_red_cards,omitempty" json:"yellowRedCards,omitempty" bson:"yellowRedCards,omitempty" uof:"4"`
+	Corners        *StatisticsScore `xml:"corners,omitempty" json:"corners,omitempty" bson:"corners,omitempty" uof:"5"`
 }
 
 type StatisticsScore struct {
-	Home int `xml:"home,attr" json:"home" bson:"home,omitempty"`
-	Away int `xml:"away,attr" json:"away" bson:"away,omitempty"`
+	Home int `xml:"home,attr" json:"home" bson:"home,omitempty" uof:"1"`
+	Away int `xml:"away,attr" json:"away" bson:"away,omitempty" uof:"2"`
 }
@@ -0,0 +1,272 @@
+package uoftest
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/minus5/go-uof-sdk/pipe"
+)
+
+// Kinds of the injected faults.
+const (
+	FaultDisconnect     = "disconnect"       // connection down and up messages before the message
+	FaultDrop           = "drop"             // message is not delivered
+	FaultDuplicate      = "duplicate"        // message is delivered twice
+	FaultReorder        = "reorder"          // message is delivered after the next one
+	FaultDelayAlive     = "delay_alive"      // alive is delivered after AliveDelay messages
+	FaultUnsubscribe    = "unsubscribe"      // alive is delivered with subscribed=0
+	FaultAPIServerError = "api_server_error" // api call fails with 503
+	FaultAPINotFound    = "api_not_found"    // api call fails with 404
+	FaultAPITimeout     = "api_timeout"      // api call fails with deadline exceeded
+)
+
+// Chaos injects faults into the messages stream and api calls, for testing
+// recovery of the pipe stages and consumers. Faults are chosen by the random
+// schedule; same seed and same input give the same faults. Probabilities are
+// per message for the stage faults and per call for the api faults.
+type Chaos struct {
+	Disconnect  float64
+	Drop        float64
+	Duplicate   float64
+	Reorder     float64
+	DelayAlive  float64
+	AliveDelay  int // number of messages for which alive is delayed, default 10
+	Unsubscribe float64
+
+	APIServerError float64
+	APINotFound    float64
+	APITimeout     float64
+
+	seed   int64
+	faults map[string]int
+	sync.Mutex
+}
+
+// NewChaos creates chaos with the random schedule seed. Set probabilities of
+// the faults before using it.
+func NewChaos(seed int64) *Chaos {
+	return &Chaos{
+		seed:       seed,
+		AliveDelay: 10,
+		faults:     make(map[string]int),
+	}
+}
+
+// Faults returns number of injected faults by kind.
+func (c *Chaos) Faults() map[string]int {
+	c.Lock()
+	defer c.Unlock()
+	f := make(map[string]int, len(c.faults))
+	for k, v := range c.faults {
+		f[k] = v
+	}
+	return f
+}
+
+func (c *Chaos) inject(kind string) {
+	c.Lock()
+	defer c.Unlock()
+	c.faults[kind]++
+}
+
+// held message, delivered after the number of the next messages
+type held struct {
+	m     *uof.Message
+	after int
+}
+
+// Stage injects faults into the messages stream. Held (reordered or delayed)
+// messages are delivered before the stage ends.
+func (c *Chaos) Stage() pipe.InnerStage {
+	return pipe.Stage(func(in <-chan *uof.Message, out chan<- *uof.Message, errc chan<- error) {
+		rnd := rand.New(rand.NewSource(c.seed))
+		hit := func(p float64) bool {
+			return p > 0 && rnd.Float64() < p
+		}
+		var hs []held
+		release := func() {
+			var keep []held
+			for _, h := range hs {
+				h.after--
+				if h.after <= 0 {
+					out <- h.m
+					continue
+				}
+				keep = append(keep, h)
+			}
+			hs = keep
+		}
+
+		for m := range in {
+			if err := m.Unpack(); err != nil {
+				// same as the pipe stages, malformed message is reported and dropped
+				errc <- err
+				continue
+			}
+			if hit(c.Disconnect) {
+				c.inject(FaultDisconnect)
+				out <- uof.NewConnnectionMessage(uof.ConnectionStatusDown)
+				out <- uof.NewConnnectionMessage(uof.ConnectionStatusUp)
+			}
+			switch {
+			case hit(c.Drop):
+				c.inject(FaultDrop)
+			case m.Alive != nil && hit(c.Unsubscribe):
+				c.inject(FaultUnsubscribe)
+				a := *m.Alive
+				a.Subscribed = 0
+				u := *m
+				u.Alive = &a
+				out <- &u
+			case m.Alive != nil && hit(c.DelayAlive):
+				c.inject(FaultDelayAlive)
+				hs = append(hs, held{m: m, after: c.AliveDelay + 1})
+			case hit(c.Reorder):
+				c.inject(FaultReorder)
+				hs = append(hs, held{m: m, after: 2})
+			case hit(c.Duplicate):
+				c.inject(FaultDuplicate)
+				d := *m
+				out <- m
+				out <- &d
+			default:
+				out <- m
+			}
+			release()
+		}
+		for _, h := range hs {
+			out <- h.m
+		}
+	})
+}
+
+// API is implemented by api.API. It is the union of the api interfaces
+// used by the pipe stages.
+type API interface {
+	Markets(lang uof.Lang) (uof.MarketDescriptions, []byte, error)
+	MarketVariant(lang uof.Lang, marketID int, variant string) (uof.MarketDescriptions, []byte, error)
+	Fixture(lang uof.Lang, eventURN uof.URN) (*uof.Fixture, []byte, error)
+	Tournament(lang uof.Lang, eventURN uof.URN) (*uof.FixtureTournament, []byte, error)
+	Fixtures(lang uof.Lang, to time.Time) (<-chan uof.Fixture, <-chan error)
+	Player(lang uof.Lang, playerID int) (*uof.Player, []byte, error)
+	RequestRecovery(producer uof.Producer, timestamp int, requestID int) error
+	CashoutProbabilities(eventURN uof.URN) (*uof.CashoutProbabilities, []byte, error)
+	MarketCashoutProbabilities(eventURN uof.URN, marketID int) (*uof.CashoutProbabilities, []byte, error)
+	SpecifierCashoutProbabilities(eventURN uof.URN, marketID int, specifiers string) (*uof.CashoutProbabilities, []byte, error)
+}
+
+// API wraps api, calls fail with the api faults probabilities.
+func (c *Chaos) API(api API) API {
+	return &chaosAPI{
+		api:   api,
+		chaos: c,
+		rnd:   rand.New(rand.NewSource(c.seed)),
+	}
+}
+
+type chaosAPI struct {
+	api   API
+	chaos *Chaos
+	rnd   *rand.Rand
+	sync.Mutex
+}
+
+// fault returns error of the injected fault or nil
+func (a *chaosAPI) fault(op string) error {
+	a.Lock()
+	r := a.rnd.Float64()
+	a.Unlock()
+
+	c := a.chaos
+	switch {
+	case r < c.APIServerError:
+		c.inject(FaultAPIServerError)
+		return uof.E("http.StatusCode", uof.APIError{URL: op, StatusCode: http.StatusServiceUnavailable})
+	case r < c.APIServerError+c.APINotFound:
+		c.inject(FaultAPINotFound)
+		return uof.E("http.StatusCode", uof.APIError{URL: op, StatusCode: http.StatusNotFound})
+	case r < c.APIServerError+c.APINotFound+c.APITimeout:
+		c.inject(FaultAPITimeout)
+		return uof.E("client.Do", uof.APIError{URL: op, Inner: context.DeadlineExceeded})
+	}
+	return nil
+}
+
+func (a *chaosAPI) Markets(lang uof.Lang) (uof.MarketDescriptions, []byte, error) {
+	if err := a.fault("markets"); err != nil {
+		return nil, nil, err
+	}
+	return a.api.Markets(lang)
+}
+
+func (a *chaosAPI) MarketVariant(lang uof.Lang, marketID int, variant string) (uof.MarketDescriptions, []byte, error) {
+	if err := a.fault("market variant"); err != nil {
+		return nil, nil, err
+	}
+	return a.api.MarketVariant(lang, marketID, variant)
+}
+
+func (a *chaosAPI) Fixture(lang uof.Lang, eventURN uof.URN) (*uof.Fixture, []byte, error) {
+	if err := a.fault("fixture"); err != nil {
+		return nil, nil, err
+	}
+	return a.api.Fixture(lang, eventURN)
+}
+
+func (a *chaosAPI) Tournament(lang uof.Lang, eventURN uof.URN) (*uof.FixtureTournament, []byte, error) {
+	if err := a.fault("tournament"); err != nil {
+		return nil, nil, err
+	}
+	return a.api.Tournament(lang, eventURN)
+}
+
+func (a *chaosAPI) Fixtures(lang uof.Lang, to time.Time) (<-chan uof.Fixture, <-chan error) {
+	if err := a.fault("fixtures"); err != nil {
+		out := make(chan uof.Fixture)
+		errc := make(chan error, 1)
+		errc <- err
+		close(out)
+		close(errc)
+		return out, errc
+	}
+	return a.api.Fixtures(lang, to)
+}
+
+func (a *chaosAPI) Player(lang uof.Lang, playerID int) (*uof.Player, []byte, error) {
+	if err := a.fault("player"); err != nil {
+		return nil, nil, err
+	}
+	return a.api.Player(lang, playerID)
+}
+
+func (a *chaosAPI) RequestRecovery(producer uof.Producer, timestamp int, requestID int) error {
+	if err := a.fault("recovery"); err != nil {
+		return err
+	}
+	return a.api.RequestRecovery(producer, timestamp, requestID)
+}
+
+func (a *chaosAPI) CashoutProbabilities(eventURN uof.URN) (*uof.CashoutProbabilities, []byte, error) {
+	if err := a.fault("cashout probabilities"); err != nil {
+		return nil, nil, err
+	}
+	return a.api.CashoutProbabilities(eventURN)
+}
+
+func (a *chaosAPI) MarketCashoutProbabilities(eventURN uof.URN, marketID int) (*uof.CashoutProbabilities, []byte, error) {
+	if err := a.fault("cashout probabilities"); err != nil {
+		return nil, nil, err
+	}
+	return a.api.MarketCashoutProbabilities(eventURN, marketID)
+}
+
+func (a *chaosAPI) SpecifierCashoutProbabilities(eventURN uof.URN, marketID int, specifiers string) (*uof.CashoutProbabilities, []byte, error) {
+	if err := a.fault("cashout probabilities"); err != nil {
+		return nil, nil, err
+	}
+	return a.api.SpecifierCashoutProbabilities(eventURN, marketID, specifiers)
+}
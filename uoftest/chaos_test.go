@@ -0,0 +1,115 @@
+package uoftest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/minus5/go-uof-sdk/api"
+	"github.com/minus5/go-uof-sdk/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ API = &api.API{}
+var _ API = &scenarioAPI{}
+
+func testChaos() *Chaos {
+	c := NewChaos(1)
+	c.Disconnect = 0.01
+	c.Drop = 0.05
+	c.Duplicate = 0.05
+	c.Reorder = 0.05
+	c.DelayAlive = 0.2
+	c.Unsubscribe = 0.2
+	return c
+}
+
+func chaosRun(c *Chaos, stages ...pipe.InnerStage) []string {
+	var out []string
+	stages = append([]pipe.InnerStage{c.Stage()}, stages...)
+	stages = append(stages, pipe.Simple(func(m *uof.Message) error {
+		key := m.Type.String()
+		if m.Alive != nil && m.Alive.Subscribed == 0 {
+			key += " unsubscribed"
+		}
+		out = append(out, key+" "+string(m.Raw))
+		return nil
+	}))
+	for range pipe.Build(testSimulator().Source(), stages...) {
+	}
+	return out
+}
+
+func TestChaosStage(t *testing.T) {
+	c := testChaos()
+	out := chaosRun(c)
+	f := c.Faults()
+	for _, kind := range []string{FaultDisconnect, FaultDrop, FaultDuplicate, FaultReorder, FaultDelayAlive, FaultUnsubscribe} {
+		assert.True(t, f[kind] > 0, kind)
+	}
+	in := len(testSimulator().Deliveries())
+	assert.Equal(t, in-f[FaultDrop]+f[FaultDuplicate]+2*f[FaultDisconnect], len(out))
+
+	// same seed, same faults
+	c2 := testChaos()
+	assert.Equal(t, out, chaosRun(c2))
+	assert.Equal(t, f, c2.Faults())
+}
+
+type recoveryAPIMock struct {
+	API
+	requests []RecoveryRequest
+	sync.Mutex
+}
+
+func (a *recoveryAPIMock) RequestRecovery(producer uof.Producer, timestamp int, requestID int) error {
+	a.Lock()
+	defer a.Unlock()
+	a.requests = append(a.requests, RecoveryRequest{Producer: producer, Timestamp: timestamp, RequestID: requestID})
+	return nil
+}
+
+func TestChaosRecovery(t *testing.T) {
+	c := NewChaos(4)
+	c.Disconnect = 0.02
+	c.Unsubscribe = 0.2
+	a := &recoveryAPIMock{}
+	out := chaosRun(c, pipe.Recovery(a, uof.ProducersChange{{Producer: uof.ProducerLiveOdds}, {Producer: uof.ProducerPrematch}}))
+
+	// both producers recovered on each disconnect, one on each unsubscribed alive
+	f := c.Faults()
+	assert.True(t, f[FaultDisconnect] > 0 && f[FaultUnsubscribe] > 0, f)
+	var unsubscribed int
+	for _, key := range out {
+		if len(key) > 18 && key[:18] == "alive unsubscribed" {
+			unsubscribed++
+		}
+	}
+	assert.Equal(t, f[FaultUnsubscribe], unsubscribed)
+	assert.Len(t, a.requests, 2*f[FaultDisconnect]+f[FaultUnsubscribe])
+}
+
+func TestChaosAPI(t *testing.T) {
+	c := NewChaos(3)
+	c.APIServerError = 0.1
+	c.APINotFound = 0.1
+	c.APITimeout = 0.1
+	a := c.API(&scenarioAPI{fixtures: map[uof.URN][]byte{"sr:match:18001015": readFile(t, "fixture-0.xml")}})
+
+	var failed, notFound int
+	for i := 0; i < 1000; i++ {
+		x, _, err := a.Fixture(uof.LangEN, "sr:match:18001015")
+		if err != nil {
+			failed++
+			if uof.IsApiNotFoundErr(err) {
+				notFound++
+			}
+			continue
+		}
+		assert.Equal(t, uof.URN("sr:match:18001015"), x.URN)
+	}
+	f := c.Faults()
+	assert.Equal(t, f[FaultAPIServerError]+f[FaultAPINotFound]+f[FaultAPITimeout], failed)
+	assert.Equal(t, f[FaultAPINotFound], notFound)
+	assert.InDelta(t, 300, failed, 60)
+}
@@ -0,0 +1,329 @@
+// Package uoftest runs in process fake of the Betradar environment: api
+// server, built on httptest, and the source of the queue messages. With it
+// sdk.Run can be exercised end to end without credentials and network.
+package uoftest
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/minus5/go-uof-sdk/queue"
+	"github.com/minus5/go-uof-sdk/sdk"
+	"github.com/streadway/amqp"
+)
+
+const (
+	// Token and BookmakerID used by the environment
+	Token       = "uoftest-token"
+	BookmakerID = "1"
+
+	whoami        = `<bookmaker_details response_code="OK" expire_at="2100-01-01T00:00:00Z" bookmaker_id="1" virtual_host="/unifiedfeed/1"/>`
+	emptySchedule = `<schedule/>`
+	deliveriesBuf = 1024
+)
+
+// Request made to the fake api.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+}
+
+// RecoveryRequest made to the fake api.
+type RecoveryRequest struct {
+	Producer  uof.Producer
+	Timestamp int // zero for full recovery
+	RequestID int
+}
+
+// Env is the fake Betradar environment.
+type Env struct {
+	server *httptest.Server
+
+	sync.Mutex
+	routes     map[string][]byte
+	requests   []Request
+	conn       *envConn
+	closed     bool
+	onRecovery func(RecoveryRequest)
+}
+
+// envConn is one queue connection. Deliveries chan is never closed, senders
+// may be blocked on it; done is closed on disconnect and out, read by the
+// queue connection, after the buffered deliveries are forwarded.
+type envConn struct {
+	deliveries chan amqp.Delivery
+	out        chan amqp.Delivery
+	done       chan struct{}
+}
+
+func newEnvConn() *envConn {
+	c := &envConn{
+		deliveries: make(chan amqp.Delivery, deliveriesBuf),
+		out:        make(chan amqp.Delivery),
+		done:       make(chan struct{}),
+	}
+	go c.forward()
+	return c
+}
+
+func (c *envConn) forward() {
+	defer close(c.out)
+	for {
+		select {
+		case d := <-c.deliveries:
+			c.out <- d
+		case <-c.done:
+			for {
+				select {
+				case d := <-c.deliveries:
+					c.out <- d
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// NewEnv starts fake api server. By default recovery request is answered with
+// the snapshot complete message. Call Close when done.
+func NewEnv() *Env {
+	e := &Env{
+		routes: make(map[string][]byte),
+		conn:   newEnvConn(),
+	}
+	e.onRecovery = e.SnapshotComplete
+	e.server = httptest.NewServer(http.HandlerFunc(e.serveHTTP))
+	return e
+}
+
+// URL of the fake api server.
+func (e *Env) URL() string {
+	return e.server.URL
+}
+
+// Close stops api server and closes message source.
+func (e *Env) Close() {
+	e.server.Close()
+	e.Lock()
+	defer e.Unlock()
+	if !e.closed {
+		e.closed = true
+		close(e.conn.done)
+	}
+}
+
+// Connection returns queue connection which consumes messages sent to the
+// environment.
+func (e *Env) Connection() (*queue.Connection, error) {
+	return queue.Source(e.dial)
+}
+
+// Options returns sdk options for connecting to the environment.
+func (e *Env) Options() ([]sdk.Option, error) {
+	conn, err := e.Connection()
+	if err != nil {
+		return nil, err
+	}
+	return []sdk.Option{
+		sdk.Credentials(BookmakerID, Token),
+		sdk.Connection(conn, e.URL()),
+	}, nil
+}
+
+func (e *Env) dial() (<-chan amqp.Delivery, error) {
+	e.Lock()
+	defer e.Unlock()
+	if e.closed {
+		return nil, errors.New("environment closed")
+	}
+	return e.conn.out, nil
+}
+
+// Disconnect closes current queue connection. Sdk will reconnect and receive
+// messages sent after disconnect.
+func (e *Env) Disconnect() {
+	e.Lock()
+	defer e.Unlock()
+	if e.closed {
+		return
+	}
+	close(e.conn.done)
+	e.conn = newEnvConn()
+}
+
+// Send delivers queue message with the routing key and body. It blocks while
+// the deliveries buffer is full. Message which can't be delivered before
+// disconnect is lost.
+func (e *Env) Send(routingKey string, body []byte) {
+	e.Lock()
+	c, closed := e.conn, e.closed
+	e.Unlock()
+	if closed {
+		return
+	}
+	select {
+	case c.deliveries <- amqp.Delivery{RoutingKey: routingKey, Body: body}:
+	case <-c.done:
+	}
+}
+
+// SendFile delivers queue message with the body from the file.
+func (e *Env) SendFile(routingKey, filename string) error {
+	body, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	e.Send(routingKey, body)
+	return nil
+}
+
+// Alive sends alive message for the producer.
+func (e *Env) Alive(producer uof.Producer, subscribed int) {
+	e.Send("-.-.-.alive.-.-.-.-",
+		[]byte(fmt.Sprintf(`<alive product="%d" timestamp="%d" subscribed="%d"/>`, producer, uof.CurrentTimestamp(), subscribed)))
+}
+
+// SnapshotComplete sends snapshot complete message for the recovery request.
+func (e *Env) SnapshotComplete(r RecoveryRequest) {
+	e.Send("-.-.-.snapshot_complete.-.-.-.-",
+		[]byte(fmt.Sprintf(`<snapshot_complete product="%d" request_id="%d" timestamp="%d"/>`, r.Producer, r.RequestID, uof.CurrentTimestamp())))
+}
+
+// OnRecovery sets handler for the recovery requests. Handler is called after
+// the request is answered. Use it to send recovery messages, it should finish
+// with SnapshotComplete.
+func (e *Env) OnRecovery(handler func(RecoveryRequest)) {
+	e.Lock()
+	defer e.Unlock()
+	e.onRecovery = handler
+}
+
+// Handle sets response body for the api request. Path is without query.
+func (e *Env) Handle(method, path string, body []byte) {
+	e.Lock()
+	defer e.Unlock()
+	e.routes[method+" "+path] = body
+}
+
+// HandleFile sets response body for the api request from the file.
+func (e *Env) HandleFile(method, path, filename string) error {
+	body, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	e.Handle(method, path, body)
+	return nil
+}
+
+// Markets sets response for the markets descriptions.
+func (e *Env) Markets(lang uof.Lang, body []byte) {
+	e.Handle(http.MethodGet, fmt.Sprintf("/v1/descriptions/%s/markets.xml", lang), body)
+}
+
+// MarketVariant sets response for the variant market description.
+func (e *Env) MarketVariant(lang uof.Lang, marketID int, variant string, body []byte) {
+	e.Handle(http.MethodGet, fmt.Sprintf("/v1/descriptions/%s/markets/%d/variants/%s", lang, marketID, variant), body)
+}
+
+// Fixture sets response for the event fixture.
+func (e *Env) Fixture(lang uof.Lang, eventURN uof.URN, body []byte) {
+	e.Handle(http.MethodGet, fmt.Sprintf("/v1/sports/%s/sport_events/%s/fixture.xml", lang, eventURN), body)
+}
+
+// Player sets response for the player profile.
+func (e *Env) Player(lang uof.Lang, playerID int, body []byte) {
+	e.Handle(http.MethodGet, fmt.Sprintf("/v1/sports/%s/players/sr:player:%d/profile.xml", lang, playerID), body)
+}
+
+// Schedule sets response for the first page of the prematch schedule, used
+// for fixtures preload. Next pages are empty.
+func (e *Env) Schedule(lang uof.Lang, body []byte) {
+	e.Handle(http.MethodGet, fmt.Sprintf("/v1/sports/%s/schedules/pre/schedule.xml", lang), body)
+}
+
+// LiveSchedule sets response for the live schedule.
+func (e *Env) LiveSchedule(lang uof.Lang, body []byte) {
+	e.Handle(http.MethodGet, fmt.Sprintf("/v1/sports/%s/schedules/live/schedule.xml", lang), body)
+}
+
+// Requests returns all requests made to the api.
+func (e *Env) Requests() []Request {
+	e.Lock()
+	defer e.Unlock()
+	return append([]Request(nil), e.requests...)
+}
+
+// RecoveryRequests returns recovery requests made to the api.
+func (e *Env) RecoveryRequests() []RecoveryRequest {
+	var rr []RecoveryRequest
+	for _, r := range e.Requests() {
+		if q, ok := parseRecovery(r); ok {
+			rr = append(rr, q)
+		}
+	}
+	return rr
+}
+
+func (e *Env) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	req := Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery}
+	e.Lock()
+	e.requests = append(e.requests, req)
+	body, ok := e.routes[r.Method+" "+r.URL.Path]
+	onRecovery := e.onRecovery
+	e.Unlock()
+
+	if rr, isRecovery := parseRecovery(req); isRecovery {
+		w.WriteHeader(http.StatusAccepted)
+		if onRecovery != nil {
+			go onRecovery(rr)
+		}
+		return
+	}
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/sports/") && strings.HasSuffix(r.URL.Path, "/schedule.xml"):
+		if !ok || r.URL.Query().Get("start") != "" && r.URL.Query().Get("start") != "0" {
+			body, ok = []byte(emptySchedule), true
+		}
+	case r.URL.Path == "/v1/users/whoami.xml" && !ok:
+		body, ok = []byte(whoami), true
+	case strings.HasPrefix(r.URL.Path, "/v1/replay/") && !ok:
+		body, ok = nil, true
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+// parseRecovery parses /v1/{producer}/recovery/initiate_request path
+func parseRecovery(r Request) (RecoveryRequest, bool) {
+	p := strings.Split(strings.Trim(r.Path, "/"), "/")
+	if r.Method != http.MethodPost || len(p) != 4 || p[0] != "v1" || p[2] != "recovery" || p[3] != "initiate_request" {
+		return RecoveryRequest{}, false
+	}
+	rr := RecoveryRequest{Producer: producerByCode(p[1])}
+	q, _ := url.ParseQuery(r.Query)
+	rr.Timestamp, _ = strconv.Atoi(q.Get("after"))
+	rr.RequestID, _ = strconv.Atoi(q.Get("request_id"))
+	return rr, true
+}
+
+func producerByCode(code string) uof.Producer {
+	for p := uof.Producer(0); p < 32; p++ {
+		if p.Code() == code {
+			return p
+		}
+	}
+	return uof.ProducerUnknown
+}
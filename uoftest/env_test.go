@@ -0,0 +1,137 @@
+package uoftest
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/minus5/go-uof-sdk/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOddsChange = `<odds_change product="1" event_id="sr:match:1" timestamp="1234">
+	<odds>
+		<market id="40"><outcome id="sr:player:1" odds="2.5"/></market>
+	</odds>
+</odds_change>`
+
+const testSchedule = `<schedule>
+	<sport_event id="sr:match:2" scheduled="2019-05-08T19:00:00+00:00">
+		<tournament id="sr:tournament:1" name="Test"><sport id="sr:sport:1" name="Soccer"/></tournament>
+	</sport_event>
+</schedule>`
+
+func readFile(t *testing.T, name string) []byte {
+	buf, err := ioutil.ReadFile("../testdata/" + name)
+	require.NoError(t, err)
+	return buf
+}
+
+func TestEnvRun(t *testing.T) {
+	env := NewEnv()
+	defer env.Close()
+	env.Markets(uof.LangEN, readFile(t, "markets-0.xml"))
+	env.Fixture(uof.LangEN, "sr:match:1", readFile(t, "fixture-0.xml"))
+	env.Player(uof.LangEN, 1, readFile(t, "player_profile_m.xml"))
+	env.Schedule(uof.LangEN, []byte(testSchedule))
+
+	opts, err := env.Options()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	msgs := make(chan *uof.Message, 128)
+	opts = append(opts,
+		sdk.Languages([]uof.Lang{uof.LangEN}),
+		sdk.Recovery([]uof.ProducerChange{{Producer: uof.ProducerLiveOdds}}),
+		sdk.Fixtures(time.Now()),
+		sdk.Callback(func(m *uof.Message) error {
+			msgs <- m
+			return nil
+		}),
+	)
+	errc, err := sdk.Run(ctx, opts...)
+	require.NoError(t, err)
+	go func() {
+		for range errc {
+		}
+	}()
+
+	env.Send("hi.-.live.odds_change.1.sr:match.1.-", []byte(testOddsChange))
+	env.Send("hi.pre.-.fixture_change.1.sr:match.1.-", []byte(`<fixture_change product="3" event_id="sr:match:1" timestamp="1234"/>`))
+
+	seen := make(map[uof.MessageType]int)
+	active := false
+	timeout := time.After(5 * time.Second)
+	for !active || seen[uof.MessageTypeOddsChange] == 0 || seen[uof.MessageTypeFixture] < 2 ||
+		seen[uof.MessageTypeMarkets] == 0 || seen[uof.MessageTypePlayer] == 0 {
+		select {
+		case m := <-msgs:
+			seen[m.Type]++
+			if m.Type == uof.MessageTypeProducersChange && m.Producers[0].Status == uof.ProducerStatusActive {
+				active = true
+			}
+		case <-timeout:
+			t.Fatalf("timeout, seen: %v, active: %v", seen, active)
+		}
+	}
+
+	rr := env.RecoveryRequests()
+	assert.Len(t, rr, 1)
+	assert.Equal(t, uof.ProducerLiveOdds, rr[0].Producer)
+	assert.Equal(t, 0, rr[0].Timestamp)
+}
+
+func TestEnvReconnect(t *testing.T) {
+	env := NewEnv()
+	defer env.Close()
+	conn, err := env.Connection()
+	require.NoError(t, err)
+	out, _ := conn.Listen()
+
+	env.Send("hi.-.live.odds_change.1.sr:match.1.-", []byte(testOddsChange))
+	m := <-out
+	assert.Equal(t, uof.MessageTypeOddsChange, m.Type)
+
+	env.Disconnect()
+	_, ok := <-out
+	assert.False(t, ok)
+}
+
+func TestEnvSendFull(t *testing.T) {
+	env := NewEnv()
+	defer env.Close()
+	// forwarder holds one delivery, buffer the rest
+	for i := 0; i <= deliveriesBuf; i++ {
+		env.Send("hi.-.live.odds_change.1.sr:match.1.-", []byte(testOddsChange))
+	}
+	sent := make(chan struct{})
+	go func() {
+		env.Send("hi.-.live.odds_change.1.sr:match.1.-", []byte(testOddsChange))
+		close(sent)
+	}()
+
+	// blocked send doesn't hold the environment lock
+	done := make(chan struct{})
+	go func() {
+		env.Handle("GET", "/v1/users/whoami.xml", []byte(whoami))
+		env.Requests()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("environment locked by the blocked send")
+	}
+
+	// disconnect releases blocked send
+	env.Disconnect()
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("send not released by disconnect")
+	}
+}
@@ -0,0 +1,517 @@
+package uoftest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/minus5/go-uof-sdk/pipe"
+)
+
+// ExpectTimeout is how long expect step waits for the matching message.
+var ExpectTimeout = 5 * time.Second
+
+// Scenario is declarative feed test. It describes api responses and the
+// timeline of steps: feed messages sent into the pipeline and expected
+// pipeline outputs.
+//
+// Bodies (api responses and sent messages) are xml strings or, when prefixed
+// with @, file names relative to the scenario file.
+type Scenario struct {
+	Name      string   `json:"name"`
+	Languages []string `json:"languages"` // default en
+	Producers []string `json:"producers"` // producer codes, recovery stage is used when set
+	API       struct {
+		Markets  map[string]string `json:"markets"`  // by language
+		Variants map[string]string `json:"variants"` // by marketID/variant, for all languages
+		Fixtures map[string]string `json:"fixtures"` // by event urn, for all languages
+		Players  map[string]string `json:"players"`  // by player id, for all languages
+	} `json:"api"`
+	Steps []Step `json:"steps"`
+
+	dir string
+}
+
+// Step of the scenario. Exactly one field should be set.
+type Step struct {
+	Connection       string  `json:"connection,omitempty"`       // up or down
+	Send             *Send   `json:"send,omitempty"`             // feed message
+	Alive            *Alive  `json:"alive,omitempty"`            // alive message
+	SnapshotComplete string  `json:"snapshotComplete,omitempty"` // producer code, answers last recovery request
+	Expect           *Expect `json:"expect,omitempty"`           // wait for the matching message
+}
+
+// Send is feed message.
+type Send struct {
+	RoutingKey string `json:"routingKey"`
+	Body       string `json:"body"`
+}
+
+// Alive message of the producer.
+type Alive struct {
+	Producer   string `json:"producer"`
+	Subscribed int    `json:"subscribed"`
+}
+
+// Expect describes pipeline output. Messages are consumed until the first one
+// which matches all set fields, non matching messages are skipped.
+type Expect struct {
+	Type      string            `json:"type"` // message type name: odds_change, bet_stop, fixture, market, player, producer_change...
+	EventURN  uof.URN           `json:"eventURN,omitempty"`
+	Lang      string            `json:"lang,omitempty"`
+	Generated *bool             `json:"generated,omitempty"`
+	MarketIDs []int             `json:"marketIDs,omitempty"` // bet stop market ids
+	Producers map[string]string `json:"producers,omitempty"` // producer code to status: down, active, in_recovery
+}
+
+var producerStatuses = map[string]uof.ProducerStatus{
+	"down":        uof.ProducerStatusDown,
+	"active":      uof.ProducerStatusActive,
+	"in_recovery": uof.ProducerStatusInRecovery,
+}
+
+// LoadScenario reads scenario from the json file.
+func LoadScenario(filename string) (*Scenario, error) {
+	buf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var s Scenario
+	if err := json.Unmarshal(buf, &s); err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", filename, err)
+	}
+	s.dir = filepath.Dir(filename)
+	if s.Name == "" {
+		s.Name = filepath.Base(filename)
+	}
+	return &s, nil
+}
+
+func (s *Scenario) body(v string) ([]byte, error) {
+	if strings.HasPrefix(v, "@") {
+		return ioutil.ReadFile(filepath.Join(s.dir, v[1:]))
+	}
+	return []byte(v), nil
+}
+
+func (s *Scenario) languages() ([]uof.Lang, error) {
+	if len(s.Languages) == 0 {
+		return []uof.Lang{uof.LangEN}, nil
+	}
+	var ls []uof.Lang
+	for _, code := range s.Languages {
+		var l uof.Lang
+		l.Parse(code)
+		if l == uof.LangNone {
+			return nil, fmt.Errorf("unknown language %s", code)
+		}
+		ls = append(ls, l)
+	}
+	return ls, nil
+}
+
+func parseProducer(code string) (uof.Producer, error) {
+	p := producerByCode(code)
+	if p == uof.ProducerUnknown {
+		return p, fmt.Errorf("unknown producer %s", code)
+	}
+	return p, nil
+}
+
+// Run executes scenario against the pipeline with markets, fixture, player,
+// bet stop and recovery stages. Api calls of those stages are served from the
+// scenario api responses.
+func (s *Scenario) Run() error {
+	if err := s.run(); err != nil {
+		return fmt.Errorf("scenario %s: %w", s.Name, err)
+	}
+	return nil
+}
+
+func (s *Scenario) run() error {
+	languages, err := s.languages()
+	if err != nil {
+		return err
+	}
+	var producers uof.ProducersChange
+	for _, code := range s.Producers {
+		p, err := parseProducer(code)
+		if err != nil {
+			return err
+		}
+		producers.Add(p, 0)
+	}
+	api, err := newScenarioAPI(s)
+	if err != nil {
+		return err
+	}
+
+	r := &scenarioRun{
+		api: api,
+		in:  make(chan *uof.Message),
+		out: make(chan *uof.Message, deliveriesBuf),
+	}
+	stages := []pipe.InnerStage{
+		pipe.Markets(api, languages),
+		pipe.Fixture(api, languages, time.Time{}),
+		pipe.Player(api, languages),
+		pipe.BetStop(),
+	}
+	if len(producers) > 0 {
+		stages = append(stages, pipe.Recovery(api, producers))
+	}
+	stages = append(stages, pipe.Simple(func(m *uof.Message) error {
+		r.out <- m
+		return nil
+	}))
+	errc := pipe.Build(r.source, stages...)
+	errsDone := make(chan struct{})
+	go func() {
+		defer close(errsDone)
+		for err := range errc {
+			r.Lock()
+			r.errs = append(r.errs, err)
+			r.Unlock()
+		}
+	}()
+
+	var stepErr error
+	for i, st := range s.Steps {
+		if err := r.step(s, st); err != nil {
+			stepErr = fmt.Errorf("step %d: %w", i, err)
+			break
+		}
+	}
+
+	// stop pipeline, drain outputs until all stages are done
+	close(r.in)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-r.out:
+			case <-done:
+				return
+			}
+		}
+	}()
+	<-errsDone
+	close(done)
+	return stepErr
+}
+
+type scenarioRun struct {
+	api *scenarioAPI
+	in  chan *uof.Message
+	out chan *uof.Message
+
+	sync.Mutex
+	errs []error
+}
+
+func (r *scenarioRun) source() (<-chan *uof.Message, <-chan error) {
+	errc := make(chan error)
+	close(errc)
+	return r.in, errc
+}
+
+func (r *scenarioRun) step(s *Scenario, st Step) error {
+	switch {
+	case st.Connection != "":
+		switch st.Connection {
+		case "up":
+			r.in <- uof.NewConnnectionMessage(uof.ConnectionStatusUp)
+		case "down":
+			r.in <- uof.NewConnnectionMessage(uof.ConnectionStatusDown)
+		default:
+			return fmt.Errorf("unknown connection status %s", st.Connection)
+		}
+	case st.Send != nil:
+		body, err := s.body(st.Send.Body)
+		if err != nil {
+			return err
+		}
+		return r.send(st.Send.RoutingKey, body)
+	case st.Alive != nil:
+		p, err := parseProducer(st.Alive.Producer)
+		if err != nil {
+			return err
+		}
+		return r.send("-.-.-.alive.-.-.-.-",
+			[]byte(fmt.Sprintf(`<alive product="%d" timestamp="%d" subscribed="%d"/>`, p, uof.CurrentTimestamp(), st.Alive.Subscribed)))
+	case st.SnapshotComplete != "":
+		p, err := parseProducer(st.SnapshotComplete)
+		if err != nil {
+			return err
+		}
+		rr, err := r.api.recoveryRequest(p)
+		if err != nil {
+			return err
+		}
+		return r.send("-.-.-.snapshot_complete.-.-.-.-",
+			[]byte(fmt.Sprintf(`<snapshot_complete product="%d" request_id="%d" timestamp="%d"/>`, p, rr.RequestID, uof.CurrentTimestamp())))
+	case st.Expect != nil:
+		return r.expect(st.Expect)
+	default:
+		return fmt.Errorf("empty step")
+	}
+	return nil
+}
+
+func (r *scenarioRun) send(routingKey string, body []byte) error {
+	m, err := uof.NewQueueMessage(routingKey, body)
+	if err != nil {
+		return err
+	}
+	r.in <- m
+	return nil
+}
+
+func (r *scenarioRun) expect(e *Expect) error {
+	match, err := e.matcher()
+	if err != nil {
+		return err
+	}
+	var seen []string
+	timeout := time.After(ExpectTimeout)
+	for {
+		select {
+		case m := <-r.out:
+			if match(m) {
+				return nil
+			}
+			seen = append(seen, m.Type.String())
+		case <-timeout:
+			r.Lock()
+			defer r.Unlock()
+			return fmt.Errorf("expected %s not found, seen: %v, errors: %v", e.Type, seen, r.errs)
+		}
+	}
+}
+
+func (e *Expect) matcher() (func(*uof.Message) bool, error) {
+	var typ uof.MessageType
+	typ.Parse(e.Type)
+	if typ == uof.MessageTypeUnknown {
+		return nil, fmt.Errorf("unknown message type %s", e.Type)
+	}
+	var lang uof.Lang
+	if e.Lang != "" {
+		lang.Parse(e.Lang)
+		if lang == uof.LangNone {
+			return nil, fmt.Errorf("unknown language %s", e.Lang)
+		}
+	}
+	producers := make(map[uof.Producer]uof.ProducerStatus)
+	for code, name := range e.Producers {
+		p, err := parseProducer(code)
+		if err != nil {
+			return nil, err
+		}
+		status, ok := producerStatuses[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown producer status %s", name)
+		}
+		producers[p] = status
+	}
+
+	return func(m *uof.Message) bool {
+		if m.Type != typ ||
+			e.EventURN != uof.NoURN && m.EventURN != e.EventURN ||
+			lang != uof.LangNone && m.Lang != lang ||
+			e.Generated != nil && m.Generated != *e.Generated {
+			return false
+		}
+		if e.MarketIDs != nil && (m.BetStop == nil || !reflect.DeepEqual(e.MarketIDs, m.BetStop.MarketIDs)) {
+			return false
+		}
+		for p, status := range producers {
+			found := false
+			for _, pc := range m.Producers {
+				if pc.Producer == p && pc.Status == status {
+					found = true
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// scenarioAPI implements api interfaces of the pipe stages, serving scenario
+// responses.
+type scenarioAPI struct {
+	markets  map[uof.Lang][]byte
+	variants map[string][]byte
+	fixtures map[uof.URN][]byte
+	players  map[int][]byte
+
+	sync.Mutex
+	recovery []RecoveryRequest
+	answered map[uof.Producer]int
+}
+
+func newScenarioAPI(s *Scenario) (*scenarioAPI, error) {
+	a := &scenarioAPI{
+		markets:  make(map[uof.Lang][]byte),
+		variants: make(map[string][]byte),
+		fixtures: make(map[uof.URN][]byte),
+		players:  make(map[int][]byte),
+		answered: make(map[uof.Producer]int),
+	}
+	for code, v := range s.API.Markets {
+		var l uof.Lang
+		l.Parse(code)
+		if l == uof.LangNone {
+			return nil, fmt.Errorf("unknown language %s", code)
+		}
+		body, err := s.body(v)
+		if err != nil {
+			return nil, err
+		}
+		a.markets[l] = body
+	}
+	for k, v := range s.API.Variants {
+		body, err := s.body(v)
+		if err != nil {
+			return nil, err
+		}
+		a.variants[k] = body
+	}
+	for k, v := range s.API.Fixtures {
+		body, err := s.body(v)
+		if err != nil {
+			return nil, err
+		}
+		a.fixtures[uof.URN(k)] = body
+	}
+	for k, v := range s.API.Players {
+		id, err := strconv.Atoi(strings.TrimPrefix(k, "sr:player:"))
+		if err != nil {
+			return nil, fmt.Errorf("player id %s: %w", k, err)
+		}
+		body, err := s.body(v)
+		if err != nil {
+			return nil, err
+		}
+		a.players[id] = body
+	}
+	return a, nil
+}
+
+func notFound(op string) error {
+	return uof.E(op, uof.APIError{URL: op, StatusCode: 404})
+}
+
+func (a *scenarioAPI) get(lang uof.Lang, typ uof.MessageType, body []byte, ok bool, op string) (*uof.Message, []byte, error) {
+	if !ok {
+		return nil, nil, notFound(op)
+	}
+	m, err := uof.NewAPIMessage(lang, typ, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, body, nil
+}
+
+func (a *scenarioAPI) Markets(lang uof.Lang) (uof.MarketDescriptions, []byte, error) {
+	body, ok := a.markets[lang]
+	m, raw, err := a.get(lang, uof.MessageTypeMarkets, body, ok, "markets")
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.Markets, raw, nil
+}
+
+func (a *scenarioAPI) MarketVariant(lang uof.Lang, marketID int, variant string) (uof.MarketDescriptions, []byte, error) {
+	body, ok := a.variants[fmt.Sprintf("%d/%s", marketID, variant)]
+	m, raw, err := a.get(lang, uof.MessageTypeMarkets, body, ok, "market variant")
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.Markets, raw, nil
+}
+
+func (a *scenarioAPI) Fixture(lang uof.Lang, eventURN uof.URN) (*uof.Fixture, []byte, error) {
+	body, ok := a.fixtures[eventURN]
+	m, raw, err := a.get(lang, uof.MessageTypeFixture, body, ok, "fixture")
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.Fixture, raw, nil
+}
+
+func (a *scenarioAPI) Tournament(lang uof.Lang, eventURN uof.URN) (*uof.FixtureTournament, []byte, error) {
+	return nil, nil, notFound("tournament")
+}
+
+func (a *scenarioAPI) Fixtures(lang uof.Lang, to time.Time) (<-chan uof.Fixture, <-chan error) {
+	out := make(chan uof.Fixture)
+	errc := make(chan error)
+	close(out)
+	close(errc)
+	return out, errc
+}
+
+func (a *scenarioAPI) Player(lang uof.Lang, playerID int) (*uof.Player, []byte, error) {
+	body, ok := a.players[playerID]
+	m, raw, err := a.get(lang, uof.MessageTypePlayer, body, ok, "player")
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.Player, raw, nil
+}
+
+func (a *scenarioAPI) RequestRecovery(producer uof.Producer, timestamp int, requestID int) error {
+	a.Lock()
+	defer a.Unlock()
+	a.recovery = append(a.recovery, RecoveryRequest{Producer: producer, Timestamp: timestamp, RequestID: requestID})
+	return nil
+}
+
+func (a *scenarioAPI) CashoutProbabilities(eventURN uof.URN) (*uof.CashoutProbabilities, []byte, error) {
+	return nil, nil, notFound("cashout probabilities")
+}
+
+func (a *scenarioAPI) MarketCashoutProbabilities(eventURN uof.URN, marketID int) (*uof.CashoutProbabilities, []byte, error) {
+	return nil, nil, notFound("cashout probabilities")
+}
+
+func (a *scenarioAPI) SpecifierCashoutProbabilities(eventURN uof.URN, marketID int, specifiers string) (*uof.CashoutProbabilities, []byte, error) {
+	return nil, nil, notFound("cashout probabilities")
+}
+
+// recoveryRequest waits for the next unanswered recovery request of the
+// producer.
+func (a *scenarioAPI) recoveryRequest(producer uof.Producer) (RecoveryRequest, error) {
+	timeout := time.After(ExpectTimeout)
+	for {
+		a.Lock()
+		var rrs []RecoveryRequest
+		for _, rr := range a.recovery {
+			if rr.Producer == producer {
+				rrs = append(rrs, rr)
+			}
+		}
+		if n := a.answered[producer]; len(rrs) > n {
+			a.answered[producer] = len(rrs)
+			a.Unlock()
+			return rrs[len(rrs)-1], nil
+		}
+		a.Unlock()
+		select {
+		case <-timeout:
+			return RecoveryRequest{}, fmt.Errorf("recovery request for %s not found", producer.Code())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package uoftest
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenarios(t *testing.T) {
+	files, err := filepath.Glob("testdata/scenarios/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, fn := range files {
+		s, err := LoadScenario(fn)
+		require.NoError(t, err)
+		t.Run(s.Name, func(t *testing.T) {
+			assert.NoError(t, s.Run())
+		})
+	}
+}
+
+func TestScenarioFailure(t *testing.T) {
+	defer func(d time.Duration) { ExpectTimeout = d }(ExpectTimeout)
+	ExpectTimeout = 100 * time.Millisecond
+
+	s := &Scenario{Name: "missing fixture", Steps: []Step{
+		{Connection: "up"},
+		{Send: &Send{
+			RoutingKey: "hi.pre.-.fixture_change.1.sr:match.1.-",
+			Body:       `<fixture_change product="3" event_id="sr:match:1" timestamp="1234"/>`,
+		}},
+		{Expect: &Expect{Type: "fixture", EventURN: "sr:match:1"}},
+	}}
+	err := s.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "step 2")
+
+	s = &Scenario{Steps: []Step{{Expect: &Expect{Type: "unknown"}}}}
+	assert.Error(t, s.Run())
+	s = &Scenario{Producers: []string{"unknown"}}
+	assert.Error(t, s.Run())
+}
@@ -0,0 +1,381 @@
+package uoftest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minus5/go-uof-sdk"
+)
+
+// Match configures one simulated match.
+type Match struct {
+	EventURN uof.URN
+	SportID  int
+	// number of prematch odds changes before the hand over to live
+	PrematchChanges int
+	// number of live odds changes
+	LiveChanges int
+	// probability of the goal in each live odds change
+	GoalProbability float64
+	// number of total lines (total=0.5, 1.5...) in addition to the 1x2 market
+	TotalLines int
+	// rollback and resend settlement after the match
+	Rollback bool
+}
+
+// Delivery is the simulated queue message.
+type Delivery struct {
+	RoutingKey string
+	Body       []byte
+}
+
+// Simulator generates queue messages for the match lifecycle: fixture change,
+// prematch odds, hand over to live, live odds with score updates and bet stops
+// on goals, match end, settlement and optional settlement rollback. Matches
+// are interleaved, alive messages are sent for both producers. Output is
+// deterministic for the seed.
+type Simulator struct {
+	// alive message for each producer is sent every AliveEvery deliveries
+	AliveEvery int
+	// timestamp of the first message
+	Start int
+
+	matches []Match
+	seed    int64
+}
+
+// NewSimulator creates simulator of the matches.
+func NewSimulator(seed int64, matches ...Match) *Simulator {
+	return &Simulator{
+		AliveEvery: 100,
+		Start:      1500000000000,
+		matches:    matches,
+		seed:       seed,
+	}
+}
+
+type simMatch struct {
+	Match
+	id        int
+	home      int
+	away      int
+	phase     int
+	step      int
+	settled   int // settlements sent
+	goalsRate float64
+}
+
+const (
+	phaseFixture = iota
+	phasePrematch
+	phaseHandover
+	phaseLive
+	phaseEnded
+	phaseSettled
+	phaseDone
+)
+
+// Run generates all deliveries, in order, and calls each for every one.
+// Stops on the first error returned from each.
+func (s *Simulator) Run(each func(Delivery) error) error {
+	r := rand.New(rand.NewSource(s.seed))
+	var ms []*simMatch
+	for _, m := range s.matches {
+		ms = append(ms, &simMatch{
+			Match:     m,
+			id:        m.EventURN.ID(),
+			goalsRate: 2 + r.Float64(),
+		})
+	}
+	ts := s.Start
+	n := 0
+	send := func(d Delivery) error {
+		n++
+		ts++
+		if s.AliveEvery > 0 && n%s.AliveEvery == 0 {
+			for _, p := range []uof.Producer{uof.ProducerLiveOdds, uof.ProducerPrematch} {
+				if err := each(Delivery{
+					RoutingKey: "-.-.-.alive.-.-.-.-",
+					Body:       []byte(`<alive product="` + strconv.Itoa(int(p)) + `" timestamp="` + strconv.Itoa(ts) + `" subscribed="1"/>`),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return each(d)
+	}
+
+	for active := len(ms); active > 0; {
+		active = 0
+		for _, m := range ms {
+			if m.phase == phaseDone {
+				continue
+			}
+			active++
+			for _, d := range m.next(r, ts) {
+				if err := send(d); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Deliveries returns all generated deliveries.
+func (s *Simulator) Deliveries() []Delivery {
+	var ds []Delivery
+	_ = s.Run(func(d Delivery) error {
+		ds = append(ds, d)
+		return nil
+	})
+	return ds
+}
+
+// Source returns pipe source of the simulated messages. Messages are parsed
+// the same way as the queue messages.
+func (s *Simulator) Source() func() (<-chan *uof.Message, <-chan error) {
+	return func() (<-chan *uof.Message, <-chan error) {
+		out := make(chan *uof.Message)
+		errc := make(chan error)
+		go func() {
+			defer close(out)
+			defer close(errc)
+			_ = s.Run(func(d Delivery) error {
+				m, err := uof.NewQueueMessage(d.RoutingKey, d.Body)
+				if err != nil {
+					errc <- uof.Notice("simulator", err)
+					return nil
+				}
+				out <- m
+				return nil
+			})
+		}()
+		return out, errc
+	}
+}
+
+// Play calls each for every delivery with the rate of perSecond deliveries,
+// until all are delivered or the context is done. Zero or negative perSecond
+// delivers without throttling.
+func (s *Simulator) Play(ctx context.Context, perSecond int, each func(Delivery) error) error {
+	if perSecond <= 0 {
+		return s.Run(func(d Delivery) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return each(d)
+		})
+	}
+	interval := time.Second / time.Duration(perSecond)
+	// deliver in batches, timer resolution is not good enough for high rates
+	batch := 1
+	for interval*time.Duration(batch) < time.Millisecond {
+		batch++
+	}
+	ticker := time.NewTicker(interval * time.Duration(batch))
+	defer ticker.Stop()
+	n := 0
+	return s.Run(func(d Delivery) error {
+		if n%batch == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		n++
+		return each(d)
+	})
+}
+
+func (m *simMatch) routingKey(priority, scope, typ string) string {
+	return fmt.Sprintf("%s.%s.%s.%d.sr:match.%d.-", priority, scope, typ, m.SportID, m.id)
+}
+
+// next returns deliveries for the next step of the match
+func (m *simMatch) next(r *rand.Rand, ts int) []Delivery {
+	switch m.phase {
+	case phaseFixture:
+		m.phase = phasePrematch
+		return []Delivery{{
+			RoutingKey: m.routingKey("lo", "pre.-", "fixture_change"),
+			Body:       []byte(fmt.Sprintf(`<fixture_change product="3" event_id="%s" timestamp="%d"/>`, m.EventURN, ts)),
+		}}
+	case phasePrematch:
+		if m.step >= m.PrematchChanges {
+			m.phase, m.step = phaseHandover, 0
+			return m.next(r, ts)
+		}
+		m.step++
+		return []Delivery{m.oddsChange(r, uof.ProducerPrematch, ts, uof.MarketStatusActive, "")}
+	case phaseHandover:
+		m.phase = phaseLive
+		return []Delivery{
+			m.oddsChange(r, uof.ProducerPrematch, ts, uof.MarketStatusHandedOver, ""),
+			m.oddsChange(r, uof.ProducerLiveOdds, ts, uof.MarketStatusActive, m.eventStatus(1, 6)),
+		}
+	case phaseLive:
+		if m.step >= m.LiveChanges {
+			m.phase = phaseEnded
+			return m.next(r, ts)
+		}
+		m.step++
+		matchStatus := 6
+		if m.step > m.LiveChanges/2 {
+			matchStatus = 7
+		}
+		if r.Float64() < m.GoalProbability {
+			if r.Intn(2) == 0 {
+				m.home++
+			} else {
+				m.away++
+			}
+			return []Delivery{
+				{
+					RoutingKey: m.routingKey("hi", "-.live", "bet_stop"),
+					Body:       []byte(fmt.Sprintf(`<bet_stop product="1" event_id="%s" timestamp="%d" groups="all" market_status="-1"/>`, m.EventURN, ts)),
+				},
+				m.oddsChange(r, uof.ProducerLiveOdds, ts, uof.MarketStatusActive, m.eventStatus(1, matchStatus)),
+			}
+		}
+		return []Delivery{m.oddsChange(r, uof.ProducerLiveOdds, ts, uof.MarketStatusActive, m.eventStatus(1, matchStatus))}
+	case phaseEnded:
+		m.phase = phaseSettled
+		return []Delivery{m.oddsChange(r, uof.ProducerLiveOdds, ts, uof.MarketStatusInactive, m.eventStatus(3, 100))}
+	case phaseSettled:
+		m.settled++
+		ds := []Delivery{m.settlement(ts)}
+		if m.Rollback && m.settled == 1 {
+			ds = append(ds, Delivery{
+				RoutingKey: m.routingKey("lo", "-.live", "rollback_bet_settlement"),
+				Body:       []byte(fmt.Sprintf(`<rollback_bet_settlement product="1" event_id="%s" timestamp="%d"><market id="1"/></rollback_bet_settlement>`, m.EventURN, ts)),
+			})
+			return ds
+		}
+		m.phase = phaseDone
+		return ds
+	}
+	return nil
+}
+
+func (m *simMatch) eventStatus(status, matchStatus int) string {
+	return fmt.Sprintf(`<sport_event_status status="%d" match_status="%d" home_score="%d" away_score="%d"/>`,
+		status, matchStatus, m.home, m.away)
+}
+
+// oddsChange creates odds change for the 1x2 and total markets
+func (m *simMatch) oddsChange(r *rand.Rand, producer uof.Producer, ts int, status uof.MarketStatus, eventStatus string) Delivery {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<odds_change product="%d" event_id="%s" timestamp="%d">`, producer, m.EventURN, ts)
+	b.WriteString(eventStatus)
+	b.WriteString(`<odds>`)
+
+	remaining := 1.0
+	if m.phase == phaseLive && m.LiveChanges > 0 {
+		remaining = 1 - float64(m.step)/float64(m.LiveChanges)
+	}
+	lambda := m.goalsRate * remaining
+	home, draw, away := m.probabilities(lambda)
+	outcomes := func(ids []int, probs ...float64) string {
+		if status == uof.MarketStatusHandedOver || status == uof.MarketStatusInactive {
+			return ""
+		}
+		var o strings.Builder
+		for i, p := range probs {
+			// small noise, and 5% margin
+			p = p * (1 + (r.Float64()-0.5)/50)
+			odds := math.Max(1.01, math.Round(100/(p*1.05))/100)
+			fmt.Fprintf(&o, `<outcome id="%d" odds="%.2f" active="1"/>`, ids[i], odds)
+		}
+		return o.String()
+	}
+	fmt.Fprintf(&b, `<market id="1" status="%d">%s</market>`, status, outcomes([]int{1, 2, 3}, home, draw, away))
+	goals := m.home + m.away
+	for i := 0; i < m.TotalLines; i++ {
+		line := float64(i) + 0.5
+		if float64(goals) > line && status == uof.MarketStatusActive {
+			// already decided
+			fmt.Fprintf(&b, `<market id="18" specifiers="total=%.1f" status="%d"/>`, line, uof.MarketStatusInactive)
+			continue
+		}
+		over := 1 - poissonCDF(lambda, int(line)-goals)
+		fmt.Fprintf(&b, `<market id="18" specifiers="total=%.1f" status="%d">%s</market>`, line, status, outcomes([]int{12, 13}, over, 1-over))
+	}
+	b.WriteString(`</odds></odds_change>`)
+
+	priority, scope := "hi", "-.live"
+	if producer == uof.ProducerPrematch {
+		priority, scope = "lo", "pre.-"
+	}
+	return Delivery{RoutingKey: m.routingKey(priority, scope, "odds_change"), Body: []byte(b.String())}
+}
+
+// probabilities of home win, draw and away win with the expected number of
+// remaining goals lambda, split equally between teams
+func (m *simMatch) probabilities(lambda float64) (float64, float64, float64) {
+	var home, draw, away float64
+	for h := 0; h <= 10; h++ {
+		for a := 0; a <= 10; a++ {
+			p := poisson(lambda/2, h) * poisson(lambda/2, a)
+			switch d := (m.home + h) - (m.away + a); {
+			case d > 0:
+				home += p
+			case d < 0:
+				away += p
+			default:
+				draw += p
+			}
+		}
+	}
+	total := home + draw + away
+	clamp := func(p float64) float64 { return math.Max(p/total, 0.01) }
+	return clamp(home), clamp(draw), clamp(away)
+}
+
+func (m *simMatch) settlement(ts int) Delivery {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<bet_settlement product="1" event_id="%s" timestamp="%d" certainty="2"><outcomes>`, m.EventURN, ts)
+	result := func(won bool) int {
+		if won {
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(&b, `<market id="1"><outcome id="1" result="%d"/><outcome id="2" result="%d"/><outcome id="3" result="%d"/></market>`,
+		result(m.home > m.away), result(m.home == m.away), result(m.home < m.away))
+	goals := m.home + m.away
+	for i := 0; i < m.TotalLines; i++ {
+		line := float64(i) + 0.5
+		over := float64(goals) > line
+		fmt.Fprintf(&b, `<market id="18" specifiers="total=%.1f"><outcome id="12" result="%d"/><outcome id="13" result="%d"/></market>`,
+			line, result(over), result(!over))
+	}
+	b.WriteString(`</outcomes></bet_settlement>`)
+	return Delivery{RoutingKey: m.routingKey("lo", "-.live", "bet_settlement"), Body: []byte(b.String())}
+}
+
+func poisson(lambda float64, k int) float64 {
+	if k < 0 {
+		return 0
+	}
+	p := math.Exp(-lambda)
+	for i := 1; i <= k; i++ {
+		p *= lambda / float64(i)
+	}
+	return p
+}
+
+// poissonCDF probability of at most k events
+func poissonCDF(lambda float64, k int) float64 {
+	var c float64
+	for i := 0; i <= k; i++ {
+		c += poisson(lambda, i)
+	}
+	return c
+}
@@ -0,0 +1,106 @@
+package uoftest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/minus5/go-uof-sdk"
+	"github.com/minus5/go-uof-sdk/pipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSimulator() *Simulator {
+	s := NewSimulator(1,
+		Match{EventURN: "sr:match:1", SportID: 1, PrematchChanges: 3, LiveChanges: 50, GoalProbability: 0.1, TotalLines: 4, Rollback: true},
+		Match{EventURN: "sr:match:2", SportID: 1, PrematchChanges: 1, LiveChanges: 20, GoalProbability: 0.2, TotalLines: 2},
+	)
+	s.AliveEvery = 10
+	return s
+}
+
+func TestSimulator(t *testing.T) {
+	ds := testSimulator().Deliveries()
+	assert.Equal(t, ds, testSimulator().Deliveries())
+
+	types := make(map[uof.MessageType]int)
+	var status *uof.SportEventStatus
+	var settlement *uof.BetSettlement
+	for _, d := range ds {
+		m, err := uof.NewQueueMessage(d.RoutingKey, d.Body)
+		assert.NoError(t, err, string(d.Body))
+		types[m.Type]++
+		if m.EventID != 1 {
+			continue
+		}
+		if m.OddsChange != nil && m.OddsChange.EventStatus != nil {
+			status = m.OddsChange.EventStatus
+		}
+		if m.BetSettlement != nil {
+			settlement = m.BetSettlement
+		}
+	}
+	assert.Equal(t, 2, types[uof.MessageTypeFixtureChange])
+	assert.Equal(t, 3, types[uof.MessageTypeBetSettlement])
+	assert.Equal(t, 1, types[uof.MessageTypeRollbackBetSettlement])
+	assert.True(t, types[uof.MessageTypeBetStop] > 0)
+	assert.True(t, types[uof.MessageTypeAlive] > 0)
+	// fixture changes, prematch, hand over, live, end, settlements and rollback
+	assert.Equal(t, 2+4+4+70+2+3+1+types[uof.MessageTypeBetStop]+types[uof.MessageTypeAlive], len(ds))
+
+	// settlement of the 1x2 market matches the final score
+	assert.Equal(t, uof.EventStatus(3), status.Status)
+	home, away := *status.HomeScore, *status.AwayScore
+	results := settlement.Markets[0].Outcomes
+	assert.Equal(t, home > away, results[0].Result == uof.OutcomeResultWin)
+	assert.Equal(t, home == away, results[1].Result == uof.OutcomeResultWin)
+	assert.Equal(t, home < away, results[2].Result == uof.OutcomeResultWin)
+}
+
+func TestSimulatorSource(t *testing.T) {
+	var n int
+	errc := pipe.Build(testSimulator().Source(),
+		pipe.BetStop(),
+		pipe.Simple(func(m *uof.Message) error {
+			n++
+			return nil
+		}),
+	)
+	for err := range errc {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, len(testSimulator().Deliveries()), n)
+}
+
+func TestSimulatorPlay(t *testing.T) {
+	var n int
+	err := testSimulator().Play(context.Background(), 100000, func(Delivery) error {
+		n++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, len(testSimulator().Deliveries()), n)
+
+	// no throttling
+	for _, perSecond := range []int{0, -1} {
+		n = 0
+		err = testSimulator().Play(context.Background(), perSecond, func(Delivery) error {
+			n++
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, len(testSimulator().Deliveries()), n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, testSimulator().Play(ctx, 10, func(Delivery) error { return nil }))
+	assert.Error(t, testSimulator().Play(ctx, 0, func(Delivery) error { return nil }))
+}
+
+func BenchmarkSimulator(b *testing.B) {
+	s := NewSimulator(1, Match{EventURN: "sr:match:1", SportID: 1, PrematchChanges: 10, LiveChanges: 1000, GoalProbability: 0.01, TotalLines: 10})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = s.Run(func(Delivery) error { return nil })
+	}
+}
@@ -0,0 +1,336 @@
+package uof
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// URN is Betradar entity identifier in the form prefix:type:id, for example
+// "sr:match:123" or "vf:season:45". Parsed returns its typed value.
+// Reference: https://docs.betradar.com/display/BD/MG+-+Entities
+type URN string
+
+const NoURN = URN("")
+
+// URNPrefix is the first part of the urn, namespace of the entity.
+type URNPrefix int8
+
+// Prefix and type values are part of the urn Key, they must not be changed,
+// new ones are only appended.
+const (
+	URNPrefixUnknown URNPrefix = iota
+	URNPrefixSR
+	URNPrefixVF
+	URNPrefixVBL
+	URNPrefixVTO
+	URNPrefixVDR
+	URNPrefixVHC
+	URNPrefixVTI
+	URNPrefixVBI
+	URNPrefixWNS
+	URNPrefixTest
+)
+
+var urnPrefixNames = []string{"", "sr", "vf", "vbl", "vto", "vdr", "vhc", "vti", "vbi", "wns", "test"}
+
+func (p *URNPrefix) Parse(name string) {
+	v := URNPrefixUnknown
+	for i, n := range urnPrefixNames {
+		if n != "" && n == name {
+			v = URNPrefix(i)
+			break
+		}
+	}
+	*p = v
+}
+
+func (p URNPrefix) String() string {
+	if p <= URNPrefixUnknown || int(p) >= len(urnPrefixNames) {
+		return InvalidName
+	}
+	return urnPrefixNames[p]
+}
+
+// Producer returns producer with the same code as prefix, ProducerUnknown if
+// there is no such producer (test urns).
+func (p URNPrefix) Producer() Producer {
+	return producerByCode(p.String())
+}
+
+func producerByCode(code string) Producer {
+	for _, d := range producers {
+		if d.code == code {
+			return d.id
+		}
+	}
+	return ProducerUnknown
+}
+
+// URNType is the second part of the urn, type of the entity.
+type URNType int8
+
+const (
+	URNTypeUnknown URNType = iota
+	URNTypeMatch
+	URNTypeStage
+	URNTypeSeason
+	URNTypeTournament
+	URNTypeSimpleTournament
+	URNTypeRace
+	URNTypeDraw
+	URNTypeLottery
+	URNTypeCompetitor
+	URNTypePlayer
+	URNTypeVenue
+	URNTypeSport
+	URNTypeCategory
+)
+
+var urnTypeNames = []string{"", "match", "stage", "season", "tournament", "simple_tournament", "race", "draw", "lottery", "competitor", "player", "venue", "sport", "category"}
+
+func (t *URNType) Parse(name string) {
+	v := URNTypeUnknown
+	for i, n := range urnTypeNames {
+		if n != "" && n == name {
+			v = URNType(i)
+			break
+		}
+	}
+	*t = v
+}
+
+func (t URNType) String() string {
+	if t <= URNTypeUnknown || int(t) >= len(urnTypeNames) {
+		return InvalidName
+	}
+	return urnTypeNames[t]
+}
+
+// ParsedURN is urn split into typed parts. Zero value is invalid urn.
+type ParsedURN struct {
+	Prefix URNPrefix
+	Type   URNType
+	ID     int
+}
+
+// MaxURNID is the largest urn id for which Key is unique.
+const MaxURNID = 1<<47 - 1
+
+// ParseURN parses urn string. Prefix and type must be known, id positive
+// decimal number not larger than MaxURNID.
+func ParseURN(s string) (ParsedURN, error) {
+	var p ParsedURN
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return p, fmt.Errorf("malformed urn %q", s)
+	}
+	p.Prefix.Parse(parts[0])
+	if p.Prefix == URNPrefixUnknown {
+		return ParsedURN{}, fmt.Errorf("unknown urn prefix %q", s)
+	}
+	p.Type.Parse(parts[1])
+	if p.Type == URNTypeUnknown {
+		return ParsedURN{}, fmt.Errorf("unknown urn type %q", s)
+	}
+	id, err := strconv.Atoi(parts[2])
+	// formatting must give the same string, no leading zeros or sign
+	if err != nil || id <= 0 || id > MaxURNID || strconv.Itoa(id) != parts[2] {
+		return ParsedURN{}, fmt.Errorf("malformed urn id %q", s)
+	}
+	p.ID = id
+	return p, nil
+}
+
+func (p ParsedURN) Valid() bool {
+	return p.Prefix != URNPrefixUnknown && p.Type != URNTypeUnknown && p.ID > 0
+}
+
+func (p ParsedURN) String() string {
+	if !p.Valid() {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s:%d", p.Prefix, p.Type, p.ID)
+}
+
+func (p ParsedURN) URN() URN {
+	return URN(p.String())
+}
+
+// Key is unique int64 for each urn, stable between versions. Id is in the
+// upper bits, prefix in the second and type in the lowest byte. Invalid urn
+// has key 0.
+func (p ParsedURN) Key() int64 {
+	if !p.Valid() {
+		return 0
+	}
+	return int64(p.ID)<<16 | int64(uint8(p.Prefix))<<8 | int64(uint8(p.Type))
+}
+
+// URNFromKey is reverse of the Key.
+func URNFromKey(key int64) ParsedURN {
+	p := ParsedURN{
+		Prefix: URNPrefix(key >> 8 & 0xff),
+		Type:   URNType(key & 0xff),
+		ID:     int(key >> 16),
+	}
+	if p.Prefix.String() == InvalidName || p.Type.String() == InvalidName || p.ID <= 0 {
+		return ParsedURN{}
+	}
+	return p
+}
+
+func (p ParsedURN) Producer() Producer {
+	return p.Prefix.Producer()
+}
+
+func (p ParsedURN) IsTournament() bool {
+	return p.Type == URNTypeSeason || p.Type == URNTypeTournament
+}
+
+func (p ParsedURN) IsTest() bool {
+	return p.Prefix == URNPrefixTest
+}
+
+// Parsed returns typed urn, zero value if the urn is malformed.
+func (u URN) Parsed() ParsedURN {
+	p, _ := ParseURN(string(u))
+	return p
+}
+
+// ID is the numeric part of the urn. Urns with unknown prefix or type, which
+// are not Valid, still have id.
+func (u URN) ID() int {
+	if p := u.Parsed(); p.Valid() {
+		return p.ID
+	}
+	id, _ := u.split()
+	return id
+}
+
+// split splits urn into id and prefix, id is 0 if the urn is malformed
+func (u URN) split() (int, string) {
+	parts := strings.Split(string(u), ":")
+	if len(parts) != 3 {
+		return 0, ""
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 63)
+	if err != nil || id == 0 {
+		return 0, ""
+	}
+	return int(id), parts[0]
+}
+
+func (u URN) Prefix() URNPrefix {
+	return u.Parsed().Prefix
+}
+
+func (u URN) Type() URNType {
+	return u.Parsed().Type
+}
+
+// Key is unique int64 for each urn, 0 for malformed urn. See ParsedURN.Key.
+func (u URN) Key() int64 {
+	return u.Parsed().Key()
+}
+
+func (u URN) Empty() bool {
+	return string(u) == ""
+}
+
+func NewEventURN(eventID int) URN {
+	return URN(fmt.Sprintf("%s%d", srMatch, eventID))
+}
+
+func (u URN) String() string {
+	return string(u)
+}
+
+func (u *URN) Parse(s string) {
+	r := URN(s)
+	if id, err := strconv.Atoi(s); err == nil {
+		r = NewEventURN(id)
+	}
+	*u = r
+}
+
+// EventID tries to generate unique id for all types of events. Most comon are
+// those with prefix sr:match for them we reserve positive id-s. All others got
+// range in negative ids.
+// Reference: https://docs.betradar.com/display/BD/MG+-+Entities
+// Reference: http://sdk.sportradar.com/content/unifiedfeedsdk/net/doc/html/e1f73019-73cd-c9f8-0d58-7fe25800abf2.htm
+// List of currently existing event types is taken from the combo box in the
+// integration control page. From method "Fixture for a specified sport event".
+// !!! Refactored to Producer + EventID in producer. EventID is not unique
+// among urn types of the same producer, use the urn or its Key to identify the
+// event.
+func (u URN) EventID() int {
+	p := u.Parsed()
+	if !p.Valid() {
+		// unknown prefix or type, id is unique within producer
+		id, prefix := u.split()
+		if producerByCode(prefix) == ProducerUnknown {
+			return 0
+		}
+		return id
+	}
+	if p.Producer() != ProducerUnknown {
+		return p.ID
+	}
+	return p.uniqueEventID()
+}
+
+func (u URN) uniqueEventID() int {
+	return u.Parsed().uniqueEventID()
+}
+
+// suffixes of the unique event ids by urn prefix and type
+var eventIDSuffixes = []struct {
+	prefix URNPrefix
+	typ    URNType
+	suffix int
+}{
+	{URNPrefixSR, URNTypeStage, 1},
+	{URNPrefixSR, URNTypeSeason, 2},
+	{URNPrefixSR, URNTypeTournament, 3},
+	{URNPrefixSR, URNTypeSimpleTournament, 4},
+	{URNPrefixVF, URNTypeMatch, 16},
+	{URNPrefixVF, URNTypeSeason, 17},
+	{URNPrefixVF, URNTypeTournament, 18},
+	{URNPrefixVBL, URNTypeMatch, 19},
+	{URNPrefixVBL, URNTypeSeason, 20},
+	{URNPrefixVBL, URNTypeTournament, 21},
+	{URNPrefixVTO, URNTypeMatch, 22},
+	{URNPrefixVTO, URNTypeSeason, 23},
+	{URNPrefixVTO, URNTypeTournament, 24},
+	{URNPrefixVDR, URNTypeStage, 25},
+	{URNPrefixVHC, URNTypeStage, 26},
+	{URNPrefixVTI, URNTypeMatch, 27},
+	{URNPrefixVTI, URNTypeTournament, 28},
+	{URNPrefixWNS, URNTypeDraw, 29},
+}
+
+func (p ParsedURN) uniqueEventID() int {
+	// test matches have the same id as sr matches, reserved suffix is 15
+	if (p.Prefix == URNPrefixSR || p.Prefix == URNPrefixTest) && p.Type == URNTypeMatch {
+		return p.ID
+	}
+	for _, s := range eventIDSuffixes {
+		if s.prefix == p.Prefix && s.typ == p.Type {
+			return -(p.ID<<8 | s.suffix)
+		}
+	}
+	return 0
+}
+
+func (u URN) Producer() Producer {
+	return u.Parsed().Producer()
+}
+
+func (u URN) IsTournament() bool {
+	return u.Parsed().IsTournament()
+}
+
+func (u URN) IsTest() bool {
+	return u.Parsed().IsTest()
+}
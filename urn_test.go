@@ -0,0 +1,241 @@
+package uof
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURN(t *testing.T) {
+	u := URN("sr:match:123")
+	assert.Equal(t, 123, u.ID())
+	assert.Equal(t, URNTypeMatch, u.Type())
+	assert.Equal(t, URN("sr:match:123"), NewEventURN(123))
+	assert.Equal(t, "sr:match:123", URN("sr:match:123").String())
+
+	assert.Equal(t, 0, URN("").ID())
+	assert.True(t, URN("").Empty())
+	assert.Equal(t, 0, URN("").EventID())
+	assert.Equal(t, 0, URN("pero").ID())
+
+	assert.Equal(t, URNTypeUnknown, URN("").Type())
+	assert.Equal(t, URNTypeUnknown, URN("pero").Type())
+	assert.Equal(t, 0, URN("pero").EventID())
+
+	u.Parse("123")
+	assert.Equal(t, URN("sr:match:123"), u)
+	assert.Equal(t, 123, u.EventID())
+}
+
+func TestURNEventID(t *testing.T) {
+	assert.Equal(t, -0xff01, URN("sr:stage:255").uniqueEventID())
+	assert.Equal(t, -0xff02, URN("sr:season:255").uniqueEventID())
+	assert.Equal(t, -0xff1C, URN("vti:tournament:255").uniqueEventID())
+	assert.Equal(t, 0, URN("pero:zdero:255").uniqueEventID())
+
+	data := []struct {
+		u  string
+		id int
+	}{
+		{"sr:match:127", 127},
+
+		{"sr:stage:127", -0x7f01},
+		{"sr:season:255", -0xff02},
+		{"sr:tournament:255", -0xff03},
+		{"sr:simple_tournament:255", -0xff04},
+
+		{"test:match:255", 255},
+
+		{"vf:match:255", -0xff10},
+		{"vf:season:255", -0xff11},
+		{"vf:tournament:255", -0xff12},
+
+		{"vbl:match:255", -0xff13},
+		{"vbl:season:255", -0xff14},
+		{"vbl:tournament:255", -0xff15},
+
+		{"vto:match:255", -0xff16},
+		{"vto:season:255", -0xff17},
+		{"vto:tournament:255", -0xff18},
+
+		{"vdr:stage:255", -0xff19},
+		{"vhc:stage:255", -0xff1A},
+
+		{"vti:match:255", -0xff1B},
+		{"vti:tournament:255", -0xff1C},
+
+		{"wns:draw:255", -0xff1D},
+		// invalid
+		{"pero:zdero:255", 0},
+	}
+	for _, d := range data {
+		assert.Equal(t, d.id, URN(d.u).uniqueEventID())
+	}
+}
+
+func TestURNMalformedID(t *testing.T) {
+	for _, u := range []URN{"sr:match:-1", "sr:match:18446744073709551615", "sr:match:x", "sr:match:1:2"} {
+		assert.Equal(t, 0, u.ID(), u)
+		assert.Equal(t, 0, u.EventID(), u)
+	}
+	// id larger than MaxURNID is not parsed, suffixed id can't overflow
+	assert.Equal(t, 0, URN("sr:season:140737488355328").uniqueEventID())
+	assert.Equal(t, -(MaxURNID<<8 | 2), URN("sr:season:140737488355327").uniqueEventID())
+}
+
+func TestParseURN(t *testing.T) {
+	data := []struct {
+		s        string
+		prefix   URNPrefix
+		typ      URNType
+		id       int
+		producer Producer
+	}{
+		{"sr:match:1", URNPrefixSR, URNTypeMatch, 1, ProducerDefault},
+		{"sr:stage:2", URNPrefixSR, URNTypeStage, 2, ProducerDefault},
+		{"sr:season:3", URNPrefixSR, URNTypeSeason, 3, ProducerDefault},
+		{"sr:tournament:4", URNPrefixSR, URNTypeTournament, 4, ProducerDefault},
+		{"sr:simple_tournament:5", URNPrefixSR, URNTypeSimpleTournament, 5, ProducerDefault},
+		{"sr:race:6", URNPrefixSR, URNTypeRace, 6, ProducerDefault},
+		{"sr:competitor:7", URNPrefixSR, URNTypeCompetitor, 7, ProducerDefault},
+		{"sr:player:8", URNPrefixSR, URNTypePlayer, 8, ProducerDefault},
+		{"sr:venue:9", URNPrefixSR, URNTypeVenue, 9, ProducerDefault},
+		{"sr:sport:10", URNPrefixSR, URNTypeSport, 10, ProducerDefault},
+		{"sr:category:11", URNPrefixSR, URNTypeCategory, 11, ProducerDefault},
+		{"vf:match:12", URNPrefixVF, URNTypeMatch, 12, Producer(6)},
+		{"vbl:season:13", URNPrefixVBL, URNTypeSeason, 13, Producer(8)},
+		{"vto:tournament:14", URNPrefixVTO, URNTypeTournament, 14, Producer(9)},
+		{"vdr:stage:15", URNPrefixVDR, URNTypeStage, 15, Producer(10)},
+		{"vhc:stage:16", URNPrefixVHC, URNTypeStage, 16, Producer(11)},
+		{"vti:match:17", URNPrefixVTI, URNTypeMatch, 17, Producer(12)},
+		{"vbi:match:18", URNPrefixVBI, URNTypeMatch, 18, Producer(15)},
+		{"wns:draw:19", URNPrefixWNS, URNTypeDraw, 19, Producer(7)},
+		{"wns:lottery:20", URNPrefixWNS, URNTypeLottery, 20, Producer(7)},
+		{"test:match:21", URNPrefixTest, URNTypeMatch, 21, ProducerUnknown},
+		{"sr:match:140737488355327", URNPrefixSR, URNTypeMatch, MaxURNID, ProducerDefault},
+	}
+	keys := make(map[int64]string)
+	for _, d := range data {
+		p, err := ParseURN(d.s)
+		assert.NoError(t, err, d.s)
+		assert.Equal(t, ParsedURN{Prefix: d.prefix, Type: d.typ, ID: d.id}, p, d.s)
+		assert.Equal(t, d.producer, p.Producer(), d.s)
+		assert.Equal(t, d.producer, URN(d.s).Producer(), d.s)
+
+		// round trip
+		assert.Equal(t, d.s, p.String())
+		assert.Equal(t, URN(d.s), p.URN())
+		assert.Equal(t, p, URN(d.s).Parsed())
+		assert.Equal(t, p, URNFromKey(p.Key()))
+
+		// collision free
+		k := URN(d.s).Key()
+		assert.True(t, k > 0, d.s)
+		_, ok := keys[k]
+		assert.False(t, ok, d.s)
+		keys[k] = d.s
+	}
+	assert.Equal(t, int64(0x10102), URN("sr:stage:1").Key())
+	assert.NotEqual(t, URN("sr:match:1").Key(), URN("sr:season:1").Key())
+	assert.NotEqual(t, URN("sr:match:1").Key(), URN("vf:match:1").Key())
+}
+
+func TestParseURNErrors(t *testing.T) {
+	for _, s := range []string{
+		"", "sr", "sr:match", "sr:match:", "sr:match:1:2",
+		"pero:match:1", "sr:zdero:1", ":match:1", "sr::1",
+		"sr:match:0", "sr:match:-1", "sr:match:+1", "sr:match:01", "sr:match: 1", "sr:match:x",
+		"sr:match:140737488355328", "sr:match:18446744073709551615",
+		"SR:match:1", "sr:Match:1",
+	} {
+		p, err := ParseURN(s)
+		assert.Error(t, err, s)
+		assert.Equal(t, ParsedURN{}, p, s)
+		assert.False(t, p.Valid())
+		assert.Equal(t, "", p.String())
+		assert.Equal(t, int64(0), p.Key())
+		assert.Equal(t, int64(0), URN(s).Key())
+		assert.Equal(t, ProducerUnknown, URN(s).Producer())
+	}
+	assert.Equal(t, ParsedURN{}, URNFromKey(0))
+	assert.Equal(t, ParsedURN{}, URNFromKey(-1))
+	assert.Equal(t, ParsedURN{}, URNFromKey(1<<16|0xffff))
+}
+
+func TestURNFallbackID(t *testing.T) {
+	// unknown prefix or type still has id, event id if prefix is producer code
+	data := []struct {
+		u       string
+		id      int
+		eventID int
+	}{
+		{"sr:zdero:1", 1, 1},
+		{"sr::2", 2, 2},
+		{"vf:zdero:3", 3, 3},
+		{"sr:match:04", 4, 4},
+		{"sr:match:140737488355328", MaxURNID + 1, MaxURNID + 1},
+		{"pero:match:5", 5, 0},
+		{":match:6", 6, 0},
+		{"SR:match:7", 7, 0},
+		// malformed id
+		{"sr:match:0", 0, 0},
+		{"sr:match:-1", 0, 0},
+		{"sr:match:+1", 0, 0},
+		{"sr:match: 1", 0, 0},
+		{"sr:match:x", 0, 0},
+		{"sr:match:9223372036854775808", 0, 0},
+		{"sr:match:1:2", 0, 0},
+	}
+	for _, d := range data {
+		assert.Equal(t, d.id, URN(d.u).ID(), d.u)
+		assert.Equal(t, d.eventID, URN(d.u).EventID(), d.u)
+	}
+}
+
+func TestURNKeyLayout(t *testing.T) {
+	// every declared prefix and type fits into its key byte
+	assert.True(t, len(urnPrefixNames) <= 1<<8)
+	assert.True(t, len(urnTypeNames) <= 1<<8)
+	keys := make(map[int64]ParsedURN)
+	for prefix := range urnPrefixNames[1:] {
+		for typ := range urnTypeNames[1:] {
+			for _, id := range []int{1, 0xff, 0xffff, MaxURNID} {
+				p := ParsedURN{Prefix: URNPrefix(prefix + 1), Type: URNType(typ + 1), ID: id}
+				k := p.Key()
+				assert.True(t, k > 0, p)
+				assert.Equal(t, p, URNFromKey(k))
+				_, ok := keys[k]
+				assert.False(t, ok, p)
+				keys[k] = p
+			}
+		}
+	}
+}
+
+func TestURNTypes(t *testing.T) {
+	for i, n := range urnPrefixNames[1:] {
+		var p URNPrefix
+		p.Parse(n)
+		assert.Equal(t, URNPrefix(i+1), p)
+		assert.Equal(t, n, p.String())
+	}
+	for i, n := range urnTypeNames[1:] {
+		var typ URNType
+		typ.Parse(n)
+		assert.Equal(t, URNType(i+1), typ)
+		assert.Equal(t, n, typ.String())
+	}
+	var p URNPrefix
+	p.Parse("")
+	assert.Equal(t, URNPrefixUnknown, p)
+	assert.Equal(t, InvalidName, p.String())
+	assert.Equal(t, InvalidName, URNType(127).String())
+
+	assert.True(t, URN("sr:season:1").IsTournament())
+	assert.True(t, URN("vf:tournament:1").IsTournament())
+	assert.False(t, URN("sr:match:1").IsTournament())
+	assert.False(t, URN("sr:simple_tournament:1").IsTournament())
+	assert.True(t, URN("test:match:1").IsTest())
+	assert.False(t, URN("sr:match:1").IsTest())
+	assert.Equal(t, URNPrefixVF, URN("vf:season:1").Prefix())
+}